@@ -6,31 +6,76 @@ import (
 	"flag"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/loader"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/addressRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/closureRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/crossingRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/metadataRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/nodeRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/osmdatarepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/transitRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/wayRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/addressService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/metadataService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/nodeService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/osmdataservice"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/transitService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/wayService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/clip"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/elevation"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/progress"
 	"os"
 	"runtime"
+	"strings"
+	"time"
 )
 
+// writerQueueSize is the number of full buffers waiting to be written, it limits the memory used for buffers
+const writerQueueSize = 2
+
 func main() {
-	importFile := flag.String("import", "", "import file")
+	importFiles := flag.String("import", "", "comma separated list of import files, overlapping extracts are merged")
 	databaseFile := flag.String("database", "", "database file")
+	elevationDirectory := flag.String("elevation", "", "directory containing SRTM (.hgt) or GeoTIFF elevation tiles (optional)")
+	gtfsFiles := flag.String("gtfs", "", "comma separated list of GTFS zip files (optional)")
+	boundingBox := flag.String("bbox", "", "only import the area minLon,minLat,maxLon,maxLat (optional)")
+	polygonFile := flag.String("polygon", "", "only import the area of a GeoJSON or .poly file (optional)")
+	changeFiles := flag.String("changes", "", "comma separated list of OsmChange files (.osc or .osc.gz) to apply to an imported database (optional)")
+	progressFile := flag.String("progress-json", "", "file to append the import progress to as json lines (optional)")
+	progressInterval := flag.Duration("progress-interval", 10*time.Second, "interval between progress reports")
 
 	flag.Parse()
 
-	if importFile == nil || databaseFile == nil {
+	if *databaseFile == "" || (*importFiles == "" && *gtfsFiles == "" && *changeFiles == "") {
 		panic("no import or database file provided")
 	}
 
+	if *boundingBox != "" && *polygonFile != "" {
+		panic("only one of bbox and polygon can be provided")
+	}
+
 	logger := logging.New(logging.LevelDebug, os.Stdout)
 
+	var area clip.Area
+	var err error
+	if *boundingBox != "" {
+		area, err = clip.ParseBoundingBox(*boundingBox)
+		if err != nil {
+			logger.Error().Msgf("error while parsing bounding box: %s", err.Error())
+			return
+		}
+	}
+
+	if *polygonFile != "" {
+		area, err = clip.Open(*polygonFile)
+		if err != nil {
+			logger.Error().Msgf("error while loading polygon: %s", err.Error())
+			return
+		}
+	}
+
 	db, err := database.New(*databaseFile)
 	if err != nil {
 		logger.Error().Msgf("error while creating database: %s", err.Error())
@@ -38,10 +83,14 @@ func main() {
 	}
 	defer db.Close()
 
+	// the bulk inserts of all services are written by one goroutine, while the next buffers are filled
+	writer := database.NewWriter(writerQueueSize)
+	defer writer.Close()
+
 	osmdataRepo := osmdatarepository.New(runtime.GOMAXPROCS(-1))
 	osmdataSvc := osmdataservice.New(
 		osmdataRepo,
-		[]string{*importFile},
+		strings.Split(*importFiles, ","),
 		logger.WithAttrs("service", "osmdata"),
 	)
 
@@ -52,7 +101,7 @@ func main() {
 		return
 	}
 
-	waySvc := wayService.New(wayRepo, logger.WithAttrs("service", "way"))
+	waySvc := wayService.New(wayRepo, writer, logger.WithAttrs("service", "way"))
 
 	nodeRepo := nodeRepository.New(db)
 	err = nodeRepo.Init(false)
@@ -61,7 +110,7 @@ func main() {
 		return
 	}
 
-	nodeSvc := nodeService.New(nodeRepo, logger.WithAttrs("service", "node"))
+	nodeSvc := nodeService.New(nodeRepo, writer, logger.WithAttrs("service", "node"))
 
 	addrRepo := addressRepository.New(db)
 	err = addrRepo.Init()
@@ -70,13 +119,122 @@ func main() {
 		return
 	}
 
-	addrSvc := addressService.New(addrRepo, logger.WithAttrs("service", "address"))
+	addrSvc := addressService.New(addrRepo, writer, logger.WithAttrs("service", "address"))
+
+	elevationModel := elevation.NewNone()
+	if *elevationDirectory != "" {
+		elevationModel, err = elevation.New(*elevationDirectory)
+		if err != nil {
+			logger.Error().Msgf("error while loading elevation tiles: %s", err.Error())
+			return
+		}
+	}
+
+	crossingRepo := crossingRepository.New(db)
+	err = crossingRepo.Init()
+	if err != nil {
+		logger.Error().Msgf("error while initializing crossing repository: %s", err.Error())
+		return
+	}
 
-	application := loader.New(osmdataSvc, nodeSvc, waySvc, addrSvc, logger.WithAttrs("application", "loader"))
+	profiles, err := weightRepository.NewProfiles(nil)
+	if err != nil {
+		logger.Error().Msgf("error while loading profiles: %s", err.Error())
+		return
+	}
 
-	err = application.Load()
+	weightRepo := weightRepository.New(profiles, logger.WithAttrs("repository", "weight"))
+	closureRepo := closureRepository.New(db)
+	err = closureRepo.Init()
 	if err != nil {
-		logger.Error().Msgf("error while loading data: %s", err.Error())
+		logger.Error().Msgf("error while initializing closure repository: %s", err.Error())
 		return
 	}
+
+	graphSvc := graphService.New(nodeRepo, crossingRepo, wayRepo, weightRepo, closureRepo, logger.WithAttrs("service", "graph"))
+
+	transitRepo := transitRepository.New(db)
+	err = transitRepo.Init()
+	if err != nil {
+		logger.Error().Msgf("error while initializing transit repository: %s", err.Error())
+		return
+	}
+
+	transitSvc := transitService.New(transitRepo, logger.WithAttrs("service", "transit"))
+
+	metadataRepo := metadataRepository.New(db)
+	err = metadataRepo.Init()
+	if err != nil {
+		logger.Error().Msgf("error while initializing metadata repository: %s", err.Error())
+		return
+	}
+
+	metadataSvc := metadataService.New(metadataRepo, logger.WithAttrs("service", "metadata"))
+
+	progressSinks := []func(progress.Report){progress.LogSink(logger.WithAttrs("application", "progress"))}
+	if *progressFile != "" {
+		file, err := os.OpenFile(*progressFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			logger.Error().Msgf("error while opening progress file: %s", err.Error())
+			return
+		}
+		defer file.Close()
+
+		progressSinks = append(progressSinks, progress.JsonSink(file))
+	}
+
+	tracker := progress.New(*progressInterval, progressSinks...)
+	defer tracker.Stop()
+
+	application := loader.New(osmdataSvc, nodeSvc, waySvc, addrSvc, graphSvc, transitSvc, metadataSvc, elevationModel, area, tracker, logger.WithAttrs("application", "loader"))
+
+	if *importFiles != "" {
+		err = application.Load()
+		if err != nil {
+			logger.Error().Msgf("error while loading data: %s", err.Error())
+			return
+		}
+	}
+
+	if *changeFiles != "" {
+		// applying changes looks up the ways and nodes by id, which needs the indices
+		err = nodeRepo.InitIndices()
+		if err != nil {
+			logger.Error().Msgf("error while initializing node indices: %s", err.Error())
+			return
+		}
+
+		err = wayRepo.InitIndices()
+		if err != nil {
+			logger.Error().Msgf("error while initializing way indices: %s", err.Error())
+			return
+		}
+
+		err = application.ApplyChanges(strings.Split(*changeFiles, ","))
+		if err != nil {
+			logger.Error().Msgf("error while applying changes: %s", err.Error())
+			return
+		}
+	}
+
+	if *gtfsFiles != "" {
+		// linking the stops searches the nearest nodes, which needs the indices
+		err = nodeRepo.InitIndices()
+		if err != nil {
+			logger.Error().Msgf("error while initializing node indices: %s", err.Error())
+			return
+		}
+
+		err = wayRepo.InitIndices()
+		if err != nil {
+			logger.Error().Msgf("error while initializing way indices: %s", err.Error())
+			return
+		}
+
+		err = application.LoadTransit(strings.Split(*gtfsFiles, ","))
+		if err != nil {
+			logger.Error().Msgf("error while loading transit data: %s", err.Error())
+			return
+		}
+	}
 }
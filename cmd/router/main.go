@@ -3,20 +3,34 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/addressRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/closureRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/crossingRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/metadataRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/nodeRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/transitRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/wayRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/addressService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/metadataService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/nodeService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/transitService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/grpc"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/http"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 )
 
 func main() {
@@ -40,7 +54,36 @@ func main() {
 		logger.Error().Msgf("error while creating database: %s", err.Error())
 		return
 	}
-	defer db.Close()
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			logger.Error().Msgf("error while closing database: %s", err.Error())
+		}
+		logger.Info().Msg("closed database")
+	}()
+
+	// the router does not insert in bulk, the writer is only needed by the services
+	writer := database.NewWriter(1)
+	defer writer.Close()
+
+	metadataRepo := metadataRepository.New(db)
+	err = metadataRepo.Init()
+	if err != nil {
+		logger.Error().Msgf("error while initializing metadata repository: %s", err.Error())
+		return
+	}
+
+	// databases imported before checkpoints were recorded have no checkpoint and are used as they are
+	checkpoint, err := metadataService.New(metadataRepo, logger.WithAttrs("service", "metadata")).GetImportCheckpoint()
+	if err != nil {
+		logger.Error().Msgf("error while reading import checkpoint: %s", err.Error())
+		return
+	}
+
+	if checkpoint != nil && !checkpoint.Complete() {
+		logger.Error().Msgf("the import of the database is not finished (step %s), continue it with the loader", checkpoint.Step)
+		return
+	}
 
 	nodeRepo := nodeRepository.New(db)
 	err = nodeRepo.Init(true)
@@ -49,7 +92,7 @@ func main() {
 		return
 	}
 
-	nodeSvc := nodeService.New(nodeRepo, logger.WithAttrs("service", "node"))
+	nodeSvc := nodeService.New(nodeRepo, writer, logger.WithAttrs("service", "node"))
 
 	wayRepo := wayRepository.New(db)
 	err = wayRepo.Init(true)
@@ -67,9 +110,28 @@ func main() {
 		return
 	}
 
-	weightRepo := weightRepository.New(logger.WithAttrs("repository", "weight"))
+	profiles, err := weightRepository.NewProfiles(config.Profiles)
+	if err != nil {
+		logger.Error().Msgf("error while loading profiles: %s", err.Error())
+		return
+	}
 
-	graphSvc := graphService.New(nodeRepo, crossingRepo, wayRepo, weightRepo, logger.WithAttrs("service", "graph"))
+	weightRepo := weightRepository.New(profiles, logger.WithAttrs("repository", "weight"))
+	logger.Info().Msgf("loaded profiles: %v", weightRepo.ProfileNames())
+
+	closureRepo := closureRepository.New(db)
+	err = closureRepo.Init()
+	if err != nil {
+		logger.Error().Msgf("error while initializing closure repository: %s", err.Error())
+		return
+	}
+
+	graphSvc := graphService.New(nodeRepo, crossingRepo, wayRepo, weightRepo, closureRepo, logger.WithAttrs("service", "graph"))
+	err = graphSvc.LoadClosures()
+	if err != nil {
+		logger.Error().Msgf("error while loading closures: %s", err.Error())
+		return
+	}
 
 	addrRepo := addressRepository.New(db)
 	err = addrRepo.Init()
@@ -78,18 +140,142 @@ func main() {
 		return
 	}
 
-	addrSvc := addressService.New(addrRepo, logger.WithAttrs("service", "address"))
+	addrSvc := addressService.New(addrRepo, writer, logger.WithAttrs("service", "address"))
+
+	transitRepo := transitRepository.New(db)
+	err = transitRepo.Init()
+	if err != nil {
+		logger.Error().Msgf("error while initializing transit repository: %s", err.Error())
+		return
+	}
+
+	transitSvc := transitService.New(transitRepo, logger.WithAttrs("service", "transit"))
+	err = transitSvc.LoadTimetable()
+	if err != nil {
+		logger.Error().Msgf("error while loading timetable: %s", err.Error())
+		return
+	}
+
+	application := router.New(graphSvc, addrSvc, nodeSvc, transitSvc, logger.WithAttrs("application", "loader"))
+
+	readinessChecks := []http.ReadinessCheck{
+		{Name: "database", Check: db.Ping},
+		{Name: "repositories", Check: func() error {
+			return checkPrepared(map[string]preparedRepository{
+				"node":     nodeRepo,
+				"way":      wayRepo,
+				"crossing": crossingRepo,
+				"closure":  closureRepo,
+				"address":  addrRepo,
+				"transit":  transitRepo,
+			})
+		}},
+		{Name: "snap", Check: application.CheckSnap},
+	}
 
-	application := router.New(graphSvc, addrSvc, nodeSvc, logger.WithAttrs("application", "loader"))
+	// the http and the grpc server share the rate limit budgets
+	serverAccess := access.New(config.ServerConfig)
 
-	server, err := http.NewHttpServer(logger.WithAttrs("service", "interfaceHTTP"), application, config.ServerConfig)
+	server, err := http.NewHttpServer(logger.WithAttrs("service", "interfaceHTTP"), application, config.ServerConfig, serverAccess, readinessChecks)
 	if err != nil {
 		logger.Error().Msgf("error while creating http server: %s", err.Error())
+		return
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// SIGHUP reloads the closures and clears the caches, e.g. after the database was updated
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	go func() {
+		for range reload {
+			err := application.Reload()
+			if err != nil {
+				logger.Error().Msgf("error while reloading: %s", err.Error())
+				continue
+			}
+			logger.Info().Msg("reloaded closures and cleared caches")
+		}
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
 	logger.Info().Msg("loaded interfaceHTTP")
-	err = server.ListenAndServe()
+
+	grpcServeErr := make(chan error, 1)
+	if config.ServerConfig.GrpcPort != 0 {
+		grpcServer, err := grpc.NewGrpcServer(logger.WithAttrs("service", "interfaceGRPC"), application, serverAccess)
+		if err != nil {
+			logger.Error().Msgf("error while creating grpc server: %s", err.Error())
+			return
+		}
+
+		listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.ServerConfig.Host, config.ServerConfig.GrpcPort))
+		if err != nil {
+			logger.Error().Msgf("error while listening for grpc server: %s", err.Error())
+			return
+		}
+
+		go func() {
+			grpcServeErr <- grpcServer.Serve(listener)
+		}()
+
+		defer func() {
+			// GracefulStop waits for running calls, the shutdown timeout also applies to them
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+			case <-time.After(config.ServerConfig.GetShutdownTimeout()):
+				grpcServer.Stop()
+			}
+		}()
+
+		logger.Info().Msg("loaded interfaceGRPC")
+	}
+
+	select {
+	case err = <-serveErr:
+		logger.Error().Msgf("error while serving http server: %s", err.Error())
+		return
+	case err = <-grpcServeErr:
+		logger.Error().Msgf("error while serving grpc server: %s", err.Error())
+		return
+	case <-ctx.Done():
+	}
+
+	timeout := config.ServerConfig.GetShutdownTimeout()
+	logger.Info().Msgf("shutting down, draining in-flight requests for up to %s", timeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = server.Shutdown(shutdownCtx)
 	if err != nil {
-		logger.Info().Msgf("error while serving http server: %s", err.Error())
+		logger.Error().Msgf("error while shutting down http server: %s", err.Error())
+	}
+}
+
+type preparedRepository interface {
+	Prepared() bool
+}
+
+// checkPrepared fails, if the statements of a repository are not prepared
+func checkPrepared(repositories map[string]preparedRepository) error {
+	for name, repository := range repositories {
+		if !repository.Prepared() {
+			return fmt.Errorf("statements of %s repository are not prepared", name)
+		}
 	}
+	return nil
 }
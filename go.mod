@@ -3,10 +3,16 @@ module github.com/paulkoehlerdev/gosmRoutify
 go 1.21
 
 require (
+	github.com/mattn/go-sqlite3 v1.14.19
 	golang.org/x/exp v0.0.0-20231110203233-9a3e6036ecaa
+	google.golang.org/grpc v1.60.1
 	google.golang.org/protobuf v1.31.0
 )
 
 require (
-	github.com/mattn/go-sqlite3 v1.14.19 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/net v0.16.0 // indirect
+	golang.org/x/sys v0.14.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20231002182017-d307bd883b97 // indirect
 )
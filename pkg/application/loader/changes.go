@@ -0,0 +1,344 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	addressModel "github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
+	nodeModel "github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	wayModel "github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmchange"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmpbfreaderdata"
+	"io"
+	"sort"
+)
+
+type changeFile struct {
+	path  string
+	state *osmchange.State
+}
+
+// ApplyChanges applies OsmChange files to an imported database in the order of their replication sequence numbers.
+// Files with a sequence number, that has already been applied, are skipped.
+func (i *impl) ApplyChanges(files []string) error {
+	checkpoint, err := i.metadataService.GetImportCheckpoint()
+	if err != nil {
+		return fmt.Errorf("error while reading import checkpoint: %s", err.Error())
+	}
+
+	if checkpoint != nil && !checkpoint.Complete() {
+		return fmt.Errorf("the import of the database is not finished (step %s)", checkpoint.Step)
+	}
+
+	changeFiles := make([]changeFile, len(files))
+	for index, path := range files {
+		state, err := osmchange.StateOf(path)
+		if err != nil {
+			return fmt.Errorf("error while reading replication state of %s: %s", path, err.Error())
+		}
+		changeFiles[index] = changeFile{path: path, state: state}
+	}
+
+	sort.Slice(changeFiles, func(a, b int) bool {
+		return changeFiles[a].state.SequenceNumber < changeFiles[b].state.SequenceNumber
+	})
+
+	current, _, applied, err := i.metadataService.GetReplicationState()
+	if err != nil {
+		return fmt.Errorf("error while reading replication state of database: %s", err.Error())
+	}
+
+	for _, file := range changeFiles {
+		if applied && file.state.SequenceNumber <= current {
+			i.logger.Info().Msgf("Skipping %s: sequence number %d is already applied", file.path, file.state.SequenceNumber)
+			continue
+		}
+
+		if applied && file.state.SequenceNumber != current+1 {
+			return fmt.Errorf("missing change files: database is at sequence number %d, next file %s has %d", current, file.path, file.state.SequenceNumber)
+		}
+
+		i.logger.Info().Msgf("Applying %s (sequence number %d)", file.path, file.state.SequenceNumber)
+
+		err = i.applyChangeFile(file.path)
+		if err != nil {
+			return fmt.Errorf("error while applying %s: %s", file.path, err.Error())
+		}
+
+		err = i.metadataService.SetReplicationState(file.state.SequenceNumber, file.state.Timestamp)
+		if err != nil {
+			return fmt.Errorf("error while recording replication state: %s", err.Error())
+		}
+
+		current, applied = file.state.SequenceNumber, true
+	}
+
+	return nil
+}
+
+type changeSet struct {
+	nodes []*osmchange.Change
+	ways  []*osmchange.Change
+
+	// nodesByID are the created and modified nodes, the last change of a node wins
+	nodesByID map[int64]*osmpbfreaderdata.Node
+
+	// affectedNodes are the nodes of changed ways before and after the change, their crossings are recomputed
+	affectedNodes map[int64]struct{}
+	// oldWayNodes are the nodes, changed ways referenced before the change, they may not be needed anymore
+	oldWayNodes map[int64]struct{}
+}
+
+func (i *impl) applyChangeFile(path string) error {
+	changes, err := readChangeFile(path)
+	if err != nil {
+		return err
+	}
+
+	// ways are applied first, so that nodes can be checked against the ways referencing them, like in Load()
+	for _, change := range changes.ways {
+		err = i.applyWayChange(change, changes)
+		if err != nil {
+			return fmt.Errorf("error while applying way %d: %s", change.Way.ID, err.Error())
+		}
+	}
+
+	for _, change := range changes.nodes {
+		err = i.applyNodeChange(change)
+		if err != nil {
+			return fmt.Errorf("error while applying node %d: %s", change.Node.ID, err.Error())
+		}
+	}
+
+	for nodeID := range changes.oldWayNodes {
+		err = i.removeUnusedNode(nodeID)
+		if err != nil {
+			return fmt.Errorf("error while removing unused node %d: %s", nodeID, err.Error())
+		}
+	}
+
+	missing := 0
+	affectedNodes := make([]int64, 0, len(changes.affectedNodes))
+	for nodeID := range changes.affectedNodes {
+		affectedNodes = append(affectedNodes, nodeID)
+
+		if _, ok := changes.oldWayNodes[nodeID]; ok {
+			continue
+		}
+
+		node, err := i.nodeService.SelectNodeFromID(nodeID)
+		if err != nil || node == nil {
+			missing++
+		}
+	}
+
+	if missing > 0 {
+		i.logger.Warn().Msgf("%d nodes of changed ways are not in the database, import a newer extract to route over them", missing)
+	}
+
+	err = i.wayService.UpdateCrossingsOfNodes(affectedNodes)
+	if err != nil {
+		return fmt.Errorf("error while updating crossings: %s", err.Error())
+	}
+
+	i.logger.Info().Msgf("Applied %d node and %d way changes", len(changes.nodes), len(changes.ways))
+
+	return nil
+}
+
+func readChangeFile(path string) (*changeSet, error) {
+	reader, err := osmchange.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	out := &changeSet{
+		nodesByID:     make(map[int64]*osmpbfreaderdata.Node),
+		affectedNodes: make(map[int64]struct{}),
+		oldWayNodes:   make(map[int64]struct{}),
+	}
+
+	decoder := osmchange.New(reader)
+	for {
+		change, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error while decoding changes: %s", err.Error())
+		}
+
+		switch {
+		case change.Node != nil:
+			out.nodes = append(out.nodes, change)
+			if change.Action == osmchange.Delete {
+				delete(out.nodesByID, change.Node.ID)
+			} else {
+				out.nodesByID[change.Node.ID] = change.Node
+			}
+		case change.Way != nil:
+			out.ways = append(out.ways, change)
+		}
+	}
+
+	return out, nil
+}
+
+func (i *impl) applyWayChange(change *osmchange.Change, changes *changeSet) error {
+	way := change.Way
+
+	oldNodeIDs, err := i.nodeService.SelectNodeIDsFromWayID(way.ID)
+	if err != nil {
+		return fmt.Errorf("error while selecting nodes of way: %s", err.Error())
+	}
+
+	for _, nodeID := range oldNodeIDs {
+		changes.affectedNodes[nodeID] = struct{}{}
+		changes.oldWayNodes[nodeID] = struct{}{}
+	}
+
+	err = i.wayService.DeleteWay(way.ID)
+	if err != nil {
+		return fmt.Errorf("error while deleting way: %s", err.Error())
+	}
+
+	// ways leaving the area are deleted, like ways outside of the area are not imported
+	if change.Action == osmchange.Delete || !i.wayInArea(way.NodeIDs, changes) {
+		return i.addressService.ReplaceAddress(addressModel.Way, way.ID, nil)
+	}
+
+	address, addrErr := getAddressFromTags(way.Tags)
+	if addrErr != nil {
+		address = nil
+	} else {
+		address.OsmType = addressModel.Way
+		address.OsmID = way.ID
+	}
+
+	err = i.addressService.ReplaceAddress(addressModel.Way, way.ID, address)
+	if err != nil {
+		return fmt.Errorf("error while replacing address: %s", err.Error())
+	}
+
+	if _, ok := way.Tags["highway"]; !ok && address == nil {
+		return nil
+	}
+
+	for _, nodeID := range way.NodeIDs {
+		changes.affectedNodes[nodeID] = struct{}{}
+	}
+
+	err = i.wayService.InsertWay(wayModel.Way{
+		OsmID: way.ID,
+		Tags:  way.Tags,
+		Nodes: way.NodeIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("error while inserting way: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) applyNodeChange(change *osmchange.Change) error {
+	node := change.Node
+
+	if change.Action == osmchange.Delete {
+		err := i.nodeService.DeleteNode(node.ID)
+		if err != nil {
+			return fmt.Errorf("error while deleting node: %s", err.Error())
+		}
+
+		return i.addressService.ReplaceAddress(addressModel.Node, node.ID, nil)
+	}
+
+	address, addrErr := getAddressFromTags(node.Tags)
+	if addrErr != nil || (i.area != nil && !i.area.Contains(node.Lat, node.Lon)) {
+		address = nil
+	} else {
+		address.OsmType = addressModel.Node
+		address.OsmID = node.ID
+	}
+
+	err := i.addressService.ReplaceAddress(addressModel.Node, node.ID, address)
+	if err != nil {
+		return fmt.Errorf("error while replacing address: %s", err.Error())
+	}
+
+	ways, err := i.wayService.SelectWayIDsFromNode(node.ID)
+	if err != nil {
+		return fmt.Errorf("error while selecting ways of node: %s", err.Error())
+	}
+
+	if len(ways) == 0 && address == nil {
+		return i.nodeService.DeleteNode(node.ID)
+	}
+
+	return i.insertNode(node)
+}
+
+// wayInArea checks the nodes of a way against the area of a clipped import with the coordinates of the change file
+// or of the database. Nodes, that are in neither, cannot be located and are treated as outside.
+func (i *impl) wayInArea(nodeIDs []int64, changes *changeSet) bool {
+	if i.area == nil {
+		return true
+	}
+
+	for _, nodeID := range nodeIDs {
+		if node, ok := changes.nodesByID[nodeID]; ok {
+			if i.area.Contains(node.Lat, node.Lon) {
+				return true
+			}
+			continue
+		}
+
+		node, err := i.nodeService.SelectNodeFromID(nodeID)
+		if err == nil && node != nil && i.area.Contains(node.Lat, node.Lon) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *impl) insertNode(node *osmpbfreaderdata.Node) error {
+	newNode := nodeModel.Node{
+		OsmID: node.ID,
+		Lat:   node.Lat,
+		Lon:   node.Lon,
+		Tags:  node.Tags,
+	}
+
+	newNode.SetEle(i.elevationModel.Lookup(newNode.Lat, newNode.Lon))
+
+	err := i.nodeService.InsertNode(newNode)
+	if err != nil {
+		return fmt.Errorf("error while inserting node: %s", err.Error())
+	}
+
+	return nil
+}
+
+// removeUnusedNode deletes a node, that is neither part of a way nor an address anymore
+func (i *impl) removeUnusedNode(nodeID int64) error {
+	ways, err := i.wayService.SelectWayIDsFromNode(nodeID)
+	if err != nil {
+		return fmt.Errorf("error while selecting ways of node: %s", err.Error())
+	}
+
+	if len(ways) != 0 {
+		return nil
+	}
+
+	node, err := i.nodeService.SelectNodeFromID(nodeID)
+	if err != nil || node == nil {
+		// the node is not in the database
+		return nil
+	}
+
+	if _, err := getAddressFromTags(node.Tags); err == nil {
+		return nil
+	}
+
+	return i.nodeService.DeleteNode(nodeID)
+}
@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/metadataService"
+	"strings"
+)
+
+// checkpointInterval is the number of blobs between two checkpoints, a blob contains up to 8000 elements
+const checkpointInterval = 64
+
+type importCheckpoints struct {
+	loader     *impl
+	checkpoint metadataService.ImportCheckpoint
+}
+
+// startCheckpoints continues the unfinished import of the same files or starts a new import
+func (i *impl) startCheckpoints() (*importCheckpoints, error) {
+	files := strings.Join(i.dataService.FilePaths(), ",")
+
+	checkpoint, err := i.metadataService.GetImportCheckpoint()
+	if err != nil {
+		return nil, fmt.Errorf("error while reading import checkpoint: %s", err.Error())
+	}
+
+	checkpoints := &importCheckpoints{loader: i}
+
+	switch {
+	case checkpoint == nil:
+		relationRowID, err := i.wayService.SelectLastRelationRowID()
+		if err != nil {
+			return nil, fmt.Errorf("error while checking database: %s", err.Error())
+		}
+
+		if relationRowID != 0 {
+			return nil, fmt.Errorf("the database already contains imported data, use a new database file")
+		}
+
+		i.logger.Info().Msgf("Starting import!")
+
+		checkpoints.checkpoint = metadataService.ImportCheckpoint{Files: files}
+		err = checkpoints.save(metadataService.ImportStepWays, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error while saving checkpoint: %s", err.Error())
+		}
+
+	case checkpoint.Complete():
+		return nil, fmt.Errorf("the database already contains the complete import of %s, use a new database file", checkpoint.Files)
+
+	case checkpoint.Files != files:
+		return nil, fmt.Errorf("the database contains an unfinished import of %s, continue it with the same files or use a new database file", checkpoint.Files)
+
+	default:
+		i.logger.Info().Msgf("Continuing import at step %s, offset %d after %d commits", checkpoint.Step, checkpoint.Offset, checkpoint.Commits)
+
+		// ways and nodes are inserted again with the same values, but relations and addresses would be duplicated
+		err = i.wayService.DeleteRelationsAfterRowID(checkpoint.RelationRowID)
+		if err != nil {
+			return nil, fmt.Errorf("error while resetting relations to checkpoint: %s", err.Error())
+		}
+
+		err = i.addressService.DeleteAfterRowID(checkpoint.AddressRowID)
+		if err != nil {
+			return nil, fmt.Errorf("error while resetting addresses to checkpoint: %s", err.Error())
+		}
+
+		checkpoints.checkpoint = *checkpoint
+	}
+
+	return checkpoints, nil
+}
+
+// save records the step and the offset of the next blob, the bulk inserts have to be committed before
+func (c *importCheckpoints) save(step metadataService.ImportStep, offset int64) error {
+	relationRowID, err := c.loader.wayService.SelectLastRelationRowID()
+	if err != nil {
+		return err
+	}
+
+	addressRowID, err := c.loader.addressService.SelectLastRowID()
+	if err != nil {
+		return err
+	}
+
+	c.checkpoint.Step = step
+	c.checkpoint.Offset = offset
+	c.checkpoint.Commits++
+	c.checkpoint.RelationRowID = relationRowID
+	c.checkpoint.AddressRowID = addressRowID
+
+	return c.loader.metadataService.SetImportCheckpoint(c.checkpoint)
+}
+
+func (c *importCheckpoints) saver(step metadataService.ImportStep) func(offset int64) error {
+	return func(offset int64) error {
+		return c.save(step, offset)
+	}
+}
@@ -0,0 +1,78 @@
+package loader
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/osmdatarepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/clip"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmpbfreaderdata"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/progress"
+	"sort"
+)
+
+// nodeSet is a sorted list of node ids, it needs less memory than a map for the millions of nodes of an area
+type nodeSet struct {
+	ids []int64
+}
+
+func (s *nodeSet) add(id int64) {
+	s.ids = append(s.ids, id)
+}
+
+// finish sorts the ids, it has to be called after the last add
+func (s *nodeSet) finish() {
+	if !sort.SliceIsSorted(s.ids, func(a, b int) bool { return s.ids[a] < s.ids[b] }) {
+		sort.Slice(s.ids, func(a, b int) bool { return s.ids[a] < s.ids[b] })
+	}
+}
+
+func (s *nodeSet) contains(id int64) bool {
+	index := sort.Search(len(s.ids), func(index int) bool { return s.ids[index] >= id })
+	return index < len(s.ids) && s.ids[index] == id
+}
+
+// containsAny reports whether a way has a node in the set, ways crossing the border of the area are kept whole
+func (s *nodeSet) containsAny(ids []int64) bool {
+	for _, id := range ids {
+		if s.contains(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// clipPassProcessor collects the nodes inside the area, before the ways can be filtered in the first pass
+type clipPassProcessor struct {
+	area    clip.Area
+	nodes   *nodeSet
+	tracker progress.Tracker
+	logger  logging.Logger
+}
+
+func newClipPassProcessor(area clip.Area, nodes *nodeSet, tracker progress.Tracker, logger logging.Logger) osmdatarepository.OsmDataProcessor {
+	return &clipPassProcessor{
+		area:    area,
+		nodes:   nodes,
+		tracker: tracker,
+		logger:  logger,
+	}
+}
+
+func (i *clipPassProcessor) ProcessNode(node osmpbfreaderdata.Node) {
+	if i.area.Contains(node.Lat, node.Lon) {
+		i.nodes.add(node.ID)
+	}
+}
+
+func (i *clipPassProcessor) ProcessWay(_ osmpbfreaderdata.Way) {}
+
+func (i *clipPassProcessor) ProcessRelation(_ osmpbfreaderdata.Relation) {}
+
+func (i *clipPassProcessor) Progress(bytes int64) {
+	i.tracker.Update(bytes)
+}
+
+func (i *clipPassProcessor) OnFinish() error {
+	i.nodes.finish()
+	i.logger.Info().Msgf("Found %d nodes inside the area", len(i.nodes.ids))
+	return nil
+}
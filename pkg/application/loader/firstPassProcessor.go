@@ -2,27 +2,35 @@ package loader
 
 import (
 	"fmt"
-	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
+	addressModel "github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
 	wayModel "github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/osmdatarepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/addressService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/wayService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmpbfreaderdata"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/progress"
 )
 
 type firstPassProcessor struct {
 	wayService       wayService.WayService
 	addressService   addressService.AddressService
 	logger           logging.Logger
+	nodesInArea      *nodeSet // nil, if the import is not clipped
+	checkpoint       func(offset int64) error
+	tracker          progress.Tracker
 	wayCount         int
 	acceptedWayCount int
+	blobCount        int
 }
 
-func newFirstPassProcessor(wayService wayService.WayService, addressService addressService.AddressService, logger logging.Logger) osmdatarepository.OsmDataProcessor {
+func newFirstPassProcessor(wayService wayService.WayService, addressService addressService.AddressService, nodesInArea *nodeSet, checkpoint func(offset int64) error, tracker progress.Tracker, logger logging.Logger) osmdatarepository.OsmDataProcessor {
 	return &firstPassProcessor{
 		wayService:     wayService,
 		addressService: addressService,
+		nodesInArea:    nodesInArea,
+		checkpoint:     checkpoint,
+		tracker:        tracker,
 		logger:         logger,
 		wayCount:       0,
 	}
@@ -42,6 +50,10 @@ func (i *firstPassProcessor) ProcessWay(way osmpbfreaderdata.Way) {
 		i.logger.Info().Msgf("Inserted %dM ways, accepted %d", i.wayCount/1000000, i.acceptedWayCount)
 	}
 
+	if i.nodesInArea != nil && !i.nodesInArea.containsAny(way.NodeIDs) {
+		return
+	}
+
 	address, err := i.getAddressFromWay(way)
 	if _, ok := way.Tags["highway"]; !(ok || (err == nil && address != nil)) {
 		return
@@ -67,40 +79,57 @@ func (i *firstPassProcessor) ProcessWay(way osmpbfreaderdata.Way) {
 func (i *firstPassProcessor) ProcessRelation(_ osmpbfreaderdata.Relation) {
 }
 
-func (i *firstPassProcessor) OnFinish() {
-	err := i.addressService.CommitBulkInsert()
+// Checkpoint commits the buffered ways and addresses and saves the progress every checkpointInterval blobs
+func (i *firstPassProcessor) Checkpoint(offset int64) error {
+	i.blobCount++
+	if i.blobCount%checkpointInterval != 0 {
+		return nil
+	}
+
+	err := i.commit()
 	if err != nil {
-		i.logger.Error().Msgf("Error while committing bulk insert: %s", err.Error())
+		return fmt.Errorf("error while committing bulk insert: %s", err.Error())
 	}
 
-	err = i.wayService.CommitBulkInsert()
+	err = i.checkpoint(offset)
 	if err != nil {
-		i.logger.Error().Msgf("Error while committing bulk insert: %s", err.Error())
+		return fmt.Errorf("error while saving checkpoint: %s", err.Error())
 	}
 
-	i.logger.Info().Msgf("Creating Way Indices!")
+	return nil
+}
+
+func (i *firstPassProcessor) Progress(bytes int64) {
+	i.tracker.Update(bytes)
+}
 
-	err = i.wayService.CreateIndices()
+func (i *firstPassProcessor) commit() error {
+	err := i.addressService.CommitBulkInsert()
 	if err != nil {
-		i.logger.Error().Msgf("Error while creating indices: %s", err.Error())
+		return err
 	}
 
-	i.logger.Info().Msgf("Updating Crossings!")
+	return i.wayService.CommitBulkInsert()
+}
 
-	err = i.wayService.UpdateCrossings()
+func (i *firstPassProcessor) OnFinish() error {
+	err := i.commit()
 	if err != nil {
-		i.logger.Error().Msgf("Error while updating crossings: %s", err.Error())
+		return fmt.Errorf("error while committing bulk insert: %s", err.Error())
 	}
 
 	i.logger.Info().Msgf("Inserted %dM ways, accepted %d", i.wayCount/1000000, i.acceptedWayCount)
+
+	return nil
 }
 
-func (i *firstPassProcessor) getAddressFromWay(way osmpbfreaderdata.Way) (*address.Address, error) {
+func (i *firstPassProcessor) getAddressFromWay(way osmpbfreaderdata.Way) (*addressModel.Address, error) {
 	address, err := getAddressFromTags(way.Tags)
 	if err != nil {
 		return nil, fmt.Errorf("error while getting address from node tags: %s", err.Error())
 	}
 
+	address.OsmType = addressModel.Way
 	address.OsmID = way.ID
 
 	return address, nil
@@ -4,72 +4,229 @@ import (
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/osmdatarepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/addressService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/metadataService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/nodeService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/osmdataservice"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/transitService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/wayService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/clip"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/elevation"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/progress"
 )
 
 type Loader interface {
 	Load() error
+	LoadTransit(files []string) error
+	ApplyChanges(files []string) error
 }
 
 type impl struct {
-	dataService    osmdataservice.OsmDataService
-	nodeService    nodeService.NodeService
-	addressService addressService.AddressService
-	wayService     wayService.WayService
-	logger         logging.Logger
+	dataService     osmdataservice.OsmDataService
+	nodeService     nodeService.NodeService
+	addressService  addressService.AddressService
+	wayService      wayService.WayService
+	graphService    graphService.GraphService
+	transitService  transitService.TransitService
+	metadataService metadataService.MetadataService
+	elevationModel  elevation.ElevationModel
+	area            clip.Area
+	tracker         progress.Tracker
+	logger          logging.Logger
 
 	nodeCount int
 	wayCount  int
 }
 
-func New(dataService osmdataservice.OsmDataService, nodeService nodeService.NodeService, wayService wayService.WayService, addressService addressService.AddressService, logger logging.Logger) Loader {
+func New(dataService osmdataservice.OsmDataService, nodeService nodeService.NodeService, wayService wayService.WayService, addressService addressService.AddressService, graphService graphService.GraphService, transitService transitService.TransitService, metadataService metadataService.MetadataService, elevationModel elevation.ElevationModel, area clip.Area, tracker progress.Tracker, logger logging.Logger) Loader {
 	return &impl{
-		dataService:    dataService,
-		nodeService:    nodeService,
-		addressService: addressService,
-		wayService:     wayService,
-		logger:         logger,
-		nodeCount:      0,
+		dataService:     dataService,
+		nodeService:     nodeService,
+		addressService:  addressService,
+		wayService:      wayService,
+		graphService:    graphService,
+		transitService:  transitService,
+		metadataService: metadataService,
+		elevationModel:  elevationModel,
+		area:            area,
+		tracker:         tracker,
+		logger:          logger,
+		nodeCount:       0,
 	}
 }
 
 func (i *impl) Load() error {
+	checkpoints, err := i.startCheckpoints()
+	if err != nil {
+		return err
+	}
+
+	steps := []struct {
+		step metadataService.ImportStep
+		run  func(checkpoints *importCheckpoints, offset int64) error
+	}{
+		{metadataService.ImportStepWays, i.loadWays},
+		{metadataService.ImportStepWayIndices, i.createWayIndices},
+		{metadataService.ImportStepNodes, i.loadNodes},
+		{metadataService.ImportStepNodeIndices, i.createNodeIndices},
+		{metadataService.ImportStepComplete, nil},
+	}
+
+	started := false
+	for index, step := range steps {
+		var offset int64
+		if step.step == checkpoints.checkpoint.Step {
+			started, offset = true, checkpoints.checkpoint.Offset
+		}
+
+		if !started || step.run == nil {
+			continue
+		}
+
+		err = step.run(checkpoints, offset)
+		if err != nil {
+			return err
+		}
+
+		err = checkpoints.save(steps[index+1].step, 0)
+		if err != nil {
+			return fmt.Errorf("error while saving checkpoint: %s", err.Error())
+		}
+	}
+
+	i.logger.Info().Msgf("Import complete!")
+
+	return nil
+}
+
+// crossingsChunkSize is the number of relations updated at once while updating the crossings, so that the progress
+// of the update can be reported
+const crossingsChunkSize = 1 << 20
+
+func (i *impl) loadWays(checkpoints *importCheckpoints, offset int64) error {
+	size, err := i.dataService.Size()
+	if err != nil {
+		return fmt.Errorf("error while reading size of import files: %s", err.Error())
+	}
+
+	var nodesInArea *nodeSet
+	if i.area != nil {
+		i.logger.Info().Msgf("Clip pass: collecting nodes inside the area")
+
+		nodesInArea = &nodeSet{}
+		clipPassProcessor := newClipPassProcessor(i.area, nodesInArea, i.tracker, i.logger)
+		clipPassFilter := osmdatarepository.NewBinaryOsmDataFilter(
+			false, true, true,
+		)
+
+		i.tracker.Start("clip", progress.Bytes, 0, size)
+		err := i.dataService.Process(clipPassProcessor, clipPassFilter)
+		if err != nil {
+			return fmt.Errorf("error while processing clip pass: %s", err.Error())
+		}
+		i.tracker.Finish()
+	}
+
 	firstPassProcessor := newFirstPassProcessor(
 		i.wayService,
 		i.addressService,
+		nodesInArea,
+		checkpoints.saver(metadataService.ImportStepWays),
+		i.tracker,
 		i.logger,
 	)
 	firstPassFilter := osmdatarepository.NewBinaryOsmDataFilter(
 		true, false, true,
 	)
 
+	i.logger.Info().Msgf("First pass: inserting ways and addresses")
+
+	i.tracker.Start("ways", progress.Bytes, offset, size)
+	err = i.dataService.ProcessFrom(offset, firstPassProcessor, firstPassFilter)
+	if err != nil {
+		return fmt.Errorf("error while processing first pass: %s", err.Error())
+	}
+	i.tracker.Finish()
+
+	return nil
+}
+
+func (i *impl) createWayIndices(_ *importCheckpoints, _ int64) error {
+	i.logger.Info().Msgf("Creating Way Indices!")
+
+	// sqlite does not report the progress of creating an index, only the elapsed time is reported
+	i.tracker.Start("wayIndices", progress.Rows, 0, 0)
+	err := i.wayService.CreateIndices()
+	if err != nil {
+		return fmt.Errorf("error while creating way indices: %s", err.Error())
+	}
+	i.tracker.Finish()
+
+	i.logger.Info().Msgf("Updating Crossings!")
+
+	lastRowID, err := i.wayService.SelectLastRelationRowID()
+	if err != nil {
+		return fmt.Errorf("error while selecting last relation: %s", err.Error())
+	}
+
+	i.tracker.Start("crossings", progress.Rows, 0, lastRowID)
+	for fromRowID := int64(0); fromRowID < lastRowID; fromRowID += crossingsChunkSize {
+		toRowID := min(fromRowID+crossingsChunkSize, lastRowID)
+
+		err = i.wayService.UpdateCrossingsInRange(fromRowID, toRowID)
+		if err != nil {
+			return fmt.Errorf("error while updating crossings: %s", err.Error())
+		}
+
+		i.tracker.Update(toRowID)
+	}
+	i.tracker.Finish()
+
+	return nil
+}
+
+func (i *impl) loadNodes(checkpoints *importCheckpoints, offset int64) error {
 	secondPassProcessor := newSecondPassProcessor(
 		i.wayService,
 		i.nodeService,
 		i.addressService,
+		i.elevationModel,
+		i.area,
+		checkpoints.saver(metadataService.ImportStepNodes),
+		i.tracker,
 		i.logger,
 	)
 	secondPassFilter := osmdatarepository.NewBinaryOsmDataFilter(
 		false, true, true,
 	)
 
-	i.logger.Info().Msgf("Starting import!")
-	i.logger.Info().Msgf("First pass: inserting ways and addresses")
-
-	err := i.dataService.Process(firstPassProcessor, firstPassFilter)
+	size, err := i.dataService.Size()
 	if err != nil {
-		return fmt.Errorf("error while processing first pass: %s", err.Error())
+		return fmt.Errorf("error while reading size of import files: %s", err.Error())
 	}
 
 	i.logger.Info().Msgf("Second pass: inserting nodes")
 
-	err = i.dataService.Process(secondPassProcessor, secondPassFilter)
+	i.tracker.Start("nodes", progress.Bytes, offset, size)
+	err = i.dataService.ProcessFrom(offset, secondPassProcessor, secondPassFilter)
 	if err != nil {
 		return fmt.Errorf("error while processing second pass: %s", err.Error())
 	}
+	i.tracker.Finish()
+
+	return nil
+}
+
+func (i *impl) createNodeIndices(_ *importCheckpoints, _ int64) error {
+	i.logger.Info().Msgf("Creating Node Indices!")
+
+	i.tracker.Start("nodeIndices", progress.Rows, 0, 0)
+	err := i.nodeService.CreateIndices()
+	if err != nil {
+		return fmt.Errorf("error while creating node indices: %s", err.Error())
+	}
+	i.tracker.Finish()
 
 	return nil
 }
@@ -2,30 +2,42 @@ package loader
 
 import (
 	"fmt"
-	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
+	addressModel "github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
 	nodeModel "github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/osmdatarepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/addressService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/nodeService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/wayService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/clip"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/elevation"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmpbfreaderdata"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/progress"
 )
 
 type secondPassProcessor struct {
 	wayService        wayService.WayService
 	nodeService       nodeService.NodeService
 	addressService    addressService.AddressService
+	elevationModel    elevation.ElevationModel
+	area              clip.Area // nil, if the import is not clipped
+	checkpoint        func(offset int64) error
+	tracker           progress.Tracker
 	logger            logging.Logger
 	nodeCount         int
 	acceptedNodeCount int
+	blobCount         int
 }
 
-func newSecondPassProcessor(wayService wayService.WayService, nodeService nodeService.NodeService, addressService addressService.AddressService, logger logging.Logger) osmdatarepository.OsmDataProcessor {
+func newSecondPassProcessor(wayService wayService.WayService, nodeService nodeService.NodeService, addressService addressService.AddressService, elevationModel elevation.ElevationModel, area clip.Area, checkpoint func(offset int64) error, tracker progress.Tracker, logger logging.Logger) osmdatarepository.OsmDataProcessor {
 	return &secondPassProcessor{
 		wayService:     wayService,
 		nodeService:    nodeService,
 		addressService: addressService,
+		elevationModel: elevationModel,
+		area:           area,
+		checkpoint:     checkpoint,
+		tracker:        tracker,
 		logger:         logger,
 	}
 }
@@ -44,6 +56,10 @@ func (i *secondPassProcessor) ProcessNode(node osmpbfreaderdata.Node) {
 	}
 
 	address, addrErr := i.getAddressFromNode(node)
+	if i.area != nil && !i.area.Contains(node.Lat, node.Lon) {
+		// nodes outside of the area are only kept as part of ways crossing the border
+		address = nil
+	}
 	ways, wayErr := i.wayService.SelectWayIDsFromNode(newNode.OsmID)
 	if !((wayErr == nil && len(ways) != 0) || (addrErr == nil && address != nil)) {
 		return
@@ -59,6 +75,8 @@ func (i *secondPassProcessor) ProcessNode(node osmpbfreaderdata.Node) {
 
 	i.acceptedNodeCount++
 
+	newNode.SetEle(i.elevationModel.Lookup(newNode.Lat, newNode.Lon))
+
 	err := i.nodeService.InsertNodeBulk(newNode)
 	if err != nil {
 		i.logger.Error().Msgf("Error while inserting node: %s", err.Error())
@@ -71,35 +89,57 @@ func (i *secondPassProcessor) ProcessWay(_ osmpbfreaderdata.Way) {}
 func (i *secondPassProcessor) ProcessRelation(_ osmpbfreaderdata.Relation) {
 }
 
-func (i *secondPassProcessor) OnFinish() {
-	err := i.addressService.CommitBulkInsert()
+// Checkpoint commits the buffered nodes and addresses and saves the progress every checkpointInterval blobs
+func (i *secondPassProcessor) Checkpoint(offset int64) error {
+	i.blobCount++
+	if i.blobCount%checkpointInterval != 0 {
+		return nil
+	}
+
+	err := i.commit()
 	if err != nil {
-		i.logger.Error().Msgf("Error while committing bulk insert: %s", err.Error())
+		return fmt.Errorf("error while committing bulk insert: %s", err.Error())
 	}
 
-	err = i.nodeService.CommitBulkInsert()
+	err = i.checkpoint(offset)
 	if err != nil {
-		i.logger.Error().Msgf("Error while committing bulk insert: %s", err.Error())
-		return
+		return fmt.Errorf("error while saving checkpoint: %s", err.Error())
 	}
 
-	i.logger.Info().Msgf("Creating Node Indices!")
+	return nil
+}
+
+func (i *secondPassProcessor) Progress(bytes int64) {
+	i.tracker.Update(bytes)
+}
 
-	err = i.nodeService.CreateIndices()
+func (i *secondPassProcessor) commit() error {
+	err := i.addressService.CommitBulkInsert()
 	if err != nil {
-		i.logger.Error().Msgf("Error while creating indices: %s", err.Error())
-		return
+		return err
+	}
+
+	return i.nodeService.CommitBulkInsert()
+}
+
+func (i *secondPassProcessor) OnFinish() error {
+	err := i.commit()
+	if err != nil {
+		return fmt.Errorf("error while committing bulk insert: %s", err.Error())
 	}
 
 	i.logger.Info().Msgf("Inserted %dM nodes, accepted %d", i.nodeCount/1000000, i.acceptedNodeCount)
+
+	return nil
 }
 
-func (i *secondPassProcessor) getAddressFromNode(node osmpbfreaderdata.Node) (*address.Address, error) {
+func (i *secondPassProcessor) getAddressFromNode(node osmpbfreaderdata.Node) (*addressModel.Address, error) {
 	address, err := getAddressFromTags(node.Tags)
 	if err != nil {
 		return nil, fmt.Errorf("error while getting address from node tags: %s", err.Error())
 	}
 
+	address.OsmType = addressModel.Node
 	address.OsmID = node.ID
 
 	return address, nil
@@ -0,0 +1,178 @@
+package loader
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/transit"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/gtfs"
+	"path/filepath"
+	"strings"
+)
+
+const transitAccessProfile = "pedestrian"
+
+// LoadTransit imports GTFS feeds and links their stops to the nearest pedestrian nodes,
+// the OSM data has to be imported before
+func (i *impl) LoadTransit(files []string) error {
+	profile, err := i.graphService.GetProfile(transitAccessProfile)
+	if err != nil {
+		return fmt.Errorf("error while getting access profile: %s", err.Error())
+	}
+
+	vehicle := weightRepository.Vehicle{Profile: profile}
+
+	for _, file := range files {
+		i.logger.Info().Msgf("Importing transit feed %s", file)
+
+		feed, err := gtfs.Open(file)
+		if err != nil {
+			return fmt.Errorf("error while reading feed %s: %s", file, err.Error())
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+		transitFeed, err := i.convertFeed(name, feed, vehicle)
+		if err != nil {
+			return fmt.Errorf("error while converting feed %s: %s", file, err.Error())
+		}
+
+		err = i.transitService.InsertFeed(*transitFeed)
+		if err != nil {
+			return fmt.Errorf("error while inserting feed %s: %s", file, err.Error())
+		}
+
+		i.logger.Info().Msgf("Imported %d stops, %d trips and %d connections", len(transitFeed.Stops), len(transitFeed.Trips), len(transitFeed.Connections))
+	}
+
+	return nil
+}
+
+func (i *impl) convertFeed(name string, feed *gtfs.Feed, vehicle weightRepository.Vehicle) (*transit.Feed, error) {
+	if len(feed.Agencies) == 0 || feed.Agencies[0].Timezone == "" {
+		return nil, fmt.Errorf("feed has no agency timezone")
+	}
+
+	prefix := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		return name + ":" + id
+	}
+
+	out := &transit.Feed{
+		Name:     name,
+		Timezone: feed.Agencies[0].Timezone,
+	}
+
+	stops := make(map[string]bool, len(feed.Stops))
+	unlinked := 0
+	for _, stop := range feed.Stops {
+		var nodeID int64
+		n, err := i.graphService.GetNearestNode(stop.Lat, stop.Lon, vehicle)
+		if err == nil {
+			nodeID = n.OsmID
+		} else {
+			unlinked++
+		}
+
+		stops[stop.ID] = true
+		out.Stops = append(out.Stops, transit.Stop{
+			ID:     prefix(stop.ID),
+			Name:   stop.Name,
+			Lat:    stop.Lat,
+			Lon:    stop.Lon,
+			Parent: prefix(stop.ParentStation),
+			NodeID: nodeID,
+		})
+	}
+
+	if unlinked > 0 {
+		i.logger.Warn().Msgf("%d stops are not near a pedestrian way and can only be used for transfers", unlinked)
+	}
+
+	routes := make(map[string]gtfs.Route, len(feed.Routes))
+	for _, route := range feed.Routes {
+		routes[route.ID] = route
+	}
+
+	for _, trip := range feed.Trips {
+		route := routes[trip.RouteID]
+
+		routeName := route.ShortName
+		if routeName == "" {
+			routeName = route.LongName
+		}
+
+		out.Trips = append(out.Trips, transit.Trip{
+			ID:        prefix(trip.ID),
+			ServiceID: prefix(trip.ServiceID),
+			RouteName: routeName,
+			Mode:      gtfs.RouteTypeMode(route.Type),
+			Headsign:  trip.Headsign,
+		})
+	}
+
+	// stop times are sorted by trip and sequence, consecutive stop times of a trip form a connection
+	for index := 1; index < len(feed.StopTimes); index++ {
+		from := feed.StopTimes[index-1]
+		to := feed.StopTimes[index]
+
+		if from.TripID != to.TripID || !stops[from.StopID] || !stops[to.StopID] {
+			continue
+		}
+
+		out.Connections = append(out.Connections, transit.Connection{
+			TripID:        prefix(to.TripID),
+			DepartureStop: prefix(from.StopID),
+			ArrivalStop:   prefix(to.StopID),
+			DepartureTime: from.DepartureTime,
+			ArrivalTime:   to.ArrivalTime,
+		})
+	}
+
+	out.Services = convertServices(feed, prefix)
+
+	return out, nil
+}
+
+func convertServices(feed *gtfs.Feed, prefix func(string) string) []transit.Service {
+	services := make(map[string]*transit.Service)
+	var order []string
+
+	getService := func(id string) *transit.Service {
+		service, ok := services[id]
+		if !ok {
+			service = &transit.Service{
+				ID:      prefix(id),
+				Added:   make(map[string]bool),
+				Removed: make(map[string]bool),
+			}
+			services[id] = service
+			order = append(order, id)
+		}
+		return service
+	}
+
+	for _, calendar := range feed.Calendars {
+		service := getService(calendar.ServiceID)
+		service.Weekdays = calendar.Weekdays
+		service.StartDate = calendar.StartDate
+		service.EndDate = calendar.EndDate
+	}
+
+	for _, date := range feed.CalendarDates {
+		service := getService(date.ServiceID)
+		switch date.ExceptionType {
+		case gtfs.ExceptionAdded:
+			service.Added[date.Date] = true
+		case gtfs.ExceptionRemoved:
+			service.Removed[date.Date] = true
+		}
+	}
+
+	out := make([]transit.Service, 0, len(order))
+	for _, id := range order {
+		out = append(out, *services[id])
+	}
+	return out
+}
@@ -1,87 +1,307 @@
 package router
 
 import (
+	"errors"
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/closure"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/addressService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/nodeService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/transitService"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/astar"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/lru"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/mvt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
 	"time"
 )
 
-type RouteSegmentInfo struct {
-	LengthInMeters float64         `json:"distance"`
-	LengthInTime   int64           `json:"time"`
-	GeoJson        geojson.GeoJson `json:"geojson"`
+// RouteOptions are the optional parameters of a route request
+type RouteOptions struct {
+	Profile    string
+	Dimensions weightRepository.VehicleDimensions
+	Departure  time.Time // used by transit routing, now if zero
+	AvoidAreas []geojson.Polygon
+
+	// Alternatives is the maximum number of alternative routes, only used by FindRoutes
+	Alternatives int
+
+	// Debug adds the search space and counters to the best route, only used by FindRoutes
+	Debug bool
 }
 
 type Application interface {
-	FindRoute(points []geojson.Point) ([]RouteSegmentInfo, error)
+	FindRoute(points []geojson.Point, options RouteOptions) ([]route.SegmentInfo, error)
+	FindRoutes(waypoints []Waypoint, options RouteOptions) ([]route.Route, error)
+	FindRoundTrips(start geojson.Point, options RoundTripOptions) ([]route.RoundTrip, error)
+	FindNearest(point geojson.Point, options RouteOptions) (*SnappedPoint, error)
+	FindNearestNodes(point geojson.Point, options RouteOptions, number int) ([]*SnappedPoint, error)
+	FindMatrix(sources []geojson.Point, destinations []geojson.Point, options RouteOptions) ([][]*MatrixCell, error)
+	GetTile(z, x, y int) ([]byte, error)
 	FindAddresses(query string) ([]*address.Address, error)
 	LocateAddressByID(id int64) (geojson.Point, error)
+
+	GetClosures() []*closure.Closure
+	AddClosure(closure closure.Closure) (*closure.Closure, error)
+	RemoveClosure(id int64) error
+
+	CheckSnap() error
+	Reload() error
 }
 
 const (
 	maxVisitedNodes = 500000
 )
 
+var (
+	// ErrInvalidRequest is returned, if the request can not be answered due to invalid parameters
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrNoNearNode is returned, if a point is too far away from any road usable with the profile
+	ErrNoNearNode = errors.New("no road near point")
+
+	// ErrNoRoute is returned, if two points are not connected
+	ErrNoRoute = errors.New("no route found")
+)
+
+// FieldError is an invalid request, that is caused by a single field of the request
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidRequest.Error(), e.Field, e.Message)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// SnappedPoint is the node of the graph, that is used for a requested point
+type SnappedPoint struct {
+	NodeID   int64
+	Location geojson.Point
+	Distance float64 // meters from the requested point
+}
+
 type impl struct {
 	logger         logging.Logger
 	graphService   graphService.GraphService
 	addressService addressService.AddressService
 	nodeService    nodeService.NodeService
+	transitService transitService.TransitService
+	tileCache      *lru.Cache[mvt.TileID, []byte]
+	routeCache     *lru.Cache[string, []route.Route]
+	nearNodesCache *lru.Cache[nearNodesKey, []*node.Node]
 }
 
-func New(graphService graphService.GraphService, addressService addressService.AddressService, nodeService nodeService.NodeService, logger logging.Logger) Application {
+func New(graphService graphService.GraphService, addressService addressService.AddressService, nodeService nodeService.NodeService, transitService transitService.TransitService, logger logging.Logger) Application {
 	return &impl{
 		logger:         logger,
 		graphService:   graphService,
 		addressService: addressService,
 		nodeService:    nodeService,
+		transitService: transitService,
+		tileCache:      lru.New[mvt.TileID, []byte](tileCacheSize),
+		routeCache:     lru.NewWithTTL[string, []route.Route](routeCacheSize, routeCacheTTL),
+		nearNodesCache: lru.New[nearNodesKey, []*node.Node](nearNodesCacheSize),
 	}
 }
 
-func (i *impl) FindRoute(points []geojson.Point) ([]RouteSegmentInfo, error) {
-	startTime := time.Now()
+func (i *impl) FindRoute(points []geojson.Point, options RouteOptions) ([]route.SegmentInfo, error) {
+	routes, err := i.FindRoutes(toWaypoints(points), options)
+	if err != nil {
+		return nil, err
+	}
 
-	out := make([]RouteSegmentInfo, 0, len(points)-1)
+	return routes[0].Segments, nil
+}
 
-	nodes := make([]*node.Node, len(points))
-	for index, point := range points {
-		node, err := i.graphService.GetNearestNode(point.Lon(), point.Lat())
-		if err != nil {
-			return nil, fmt.Errorf("error while finding nearest node to [%f, %f]: %s", point.Lat(), point.Lon(), err.Error())
+func (i *impl) getVehicle(profileName string, dimensions weightRepository.VehicleDimensions) (weightRepository.Vehicle, error) {
+	if profileName == "" {
+		profileName = weightRepository.DefaultProfileName
+	}
+
+	// transit routes start and end on foot
+	if profileName == TransitProfileName {
+		profileName = transitAccessProfile
+	}
+
+	profile, err := i.graphService.GetProfile(profileName)
+	if err != nil {
+		return weightRepository.Vehicle{}, &FieldError{Field: "profile", Message: err.Error()}
+	}
+
+	return weightRepository.Vehicle{
+		Profile:    profile,
+		Dimensions: dimensions,
+	}, nil
+}
+
+// FindNearest returns the node, that a route with these options would start at for the point
+func (i *impl) FindNearest(point geojson.Point, options RouteOptions) (*SnappedPoint, error) {
+	vehicle, err := i.getVehicle(options.Profile, options.Dimensions)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := i.snapWaypoints([]Waypoint{{Location: point}}, vehicle, options.AvoidAreas, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	location := geojson.NewPoint(nodes[0].Lon, nodes[0].Lat)
+
+	return &SnappedPoint{
+		NodeID:   nodes[0].OsmID,
+		Location: location,
+		Distance: sphericmath.CalcDistanceInMeters(
+			sphericmath.NewPoint(point.Lon(), point.Lat()),
+			sphericmath.NewPoint(nodes[0].Lat, nodes[0].Lon),
+		),
+	}, nil
+}
+
+// FindNearestNodes returns up to number nodes, that a route with these options could start at, the nearest first
+func (i *impl) FindNearestNodes(point geojson.Point, options RouteOptions, number int) ([]*SnappedPoint, error) {
+	vehicle, err := i.getVehicle(options.Profile, options.Dimensions)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := i.nearNodes(point.Lon(), point.Lat(), vehicle, nil)
+	if errors.Is(err, graphService.ErrNoNearNode) {
+		return nil, fmt.Errorf("%w: [%f, %f]", ErrNoNearNode, point.Lat(), point.Lon())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error while finding nearest nodes to [%f, %f]: %s", point.Lat(), point.Lon(), err.Error())
+	}
+
+	from := sphericmath.NewPoint(point.Lon(), point.Lat())
+	out := make([]*SnappedPoint, 0, min(number, len(candidates)))
+	for _, candidate := range candidates[:min(number, len(candidates))] {
+		out = append(out, &SnappedPoint{
+			NodeID:   candidate.OsmID,
+			Location: geojson.NewPoint(candidate.Lon, candidate.Lat),
+			Distance: sphericmath.CalcDistanceInMeters(from, sphericmath.NewPoint(candidate.Lat, candidate.Lon)),
+		})
+	}
+
+	return out, nil
+}
+
+// routeSearch describes a route through waypoints, that are already snapped to nodes
+type routeSearch struct {
+	points     []geojson.Point
+	nodes      []*node.Node
+	vehicle    weightRepository.Vehicle
+	avoidAreas []geojson.Polygon
+
+	// edgePenalties multiply the weights of edges in all segments, may be nil
+	edgePenalties map[graphService.EdgeKey]float64
+
+	// reusePenalty is set in edgePenalties for the edges of a segment for the following segments, if it is greater than one
+	reusePenalty float64
+
+	// debug records the search, may be nil
+	debug *searchDebug
+}
+
+// findRoute returns the segments between the waypoints and the node paths of the segments
+func (i *impl) findRoute(search routeSearch) ([]route.SegmentInfo, [][]int64, error) {
+	out := make([]route.SegmentInfo, 0, len(search.points)-1)
+	paths := make([][]int64, 0, len(search.points)-1)
+
+	edgePenalties := search.edgePenalties
+	if search.reusePenalty > 1 {
+		edgePenalties = make(map[graphService.EdgeKey]float64, len(search.edgePenalties))
+		for key, penalty := range search.edgePenalties {
+			edgePenalties[key] = penalty
 		}
+	}
 
-		nodes[index] = node
+	var stats *graphService.QueryStats
+	if search.debug != nil {
+		stats = &search.debug.stats
 	}
 
-	i.logger.Debug().Msgf("calculated nearest node in %s", time.Since(startTime).String())
+	start := search.nodes[0]
+	for index, end := range search.nodes[1:] {
+		query := graphService.Query{
+			Vehicle:       search.vehicle,
+			Start:         *start,
+			End:           *end,
+			EdgePenalties: edgePenalties,
+			AvoidAreas:    search.avoidAreas,
+			Stats:         stats,
+		}
+
+		iterations := 0
+		hooks := &astar.Hooks[int64, float64]{
+			OnSettle: func(int64, float64, float64) { iterations++ },
+		}
+		if search.debug != nil {
+			debugHooks := search.debug.hooks(index)
+			hooks.OnSettle = func(id int64, gScore float64, heuristic float64) {
+				iterations++
+				debugHooks.OnSettle(id, gScore, heuristic)
+			}
+			hooks.OnRelax = debugHooks.OnRelax
+		}
+
+		searchStart := time.Now()
+		path, length, err := astar.AStarWithHooks[int64, float64](start.OsmID, end.OsmID, i.graphService.GetEdges(query), i.graphService.GetHeuristic(query), maxVisitedNodes, hooks)
+		if search.debug != nil {
+			search.debug.SearchTime += milliseconds(time.Since(searchStart))
+		}
+
+		routeIterations.With(search.vehicle.Profile.Name).Observe(float64(iterations))
+
+		if errors.Is(err, astar.ErrNoRoute) {
+			noRouteFound.With(search.vehicle.Profile.Name).Inc()
+			return nil, nil, fmt.Errorf("%w: between point %d and %d", ErrNoRoute, index, index+1)
+		}
 
-	start := nodes[0]
-	for index, end := range nodes[1:] {
-		path, length, err := astar.AStar[int64, float64](start.OsmID, end.OsmID, i.graphService.GetEdges(*end), i.graphService.GetHeuristic(*end), maxVisitedNodes)
 		if err != nil {
-			return nil, fmt.Errorf("error while routing: %s", err.Error())
+			return nil, nil, fmt.Errorf("error while routing: %s", err.Error())
+		}
+
+		pathStart := time.Now()
+
+		// the penalties are only used to find the path, the time is calculated without them
+		if edgePenalties != nil {
+			query.EdgePenalties = nil
+			length = i.calculatePathTime(path, query)
+		}
+
+		if search.reusePenalty > 1 {
+			for pathIndex := 1; pathIndex < len(path); pathIndex++ {
+				edgePenalties[graphService.NewEdgeKey(path[pathIndex-1], path[pathIndex])] = search.reusePenalty
+			}
 		}
 
-		nodePoints, lengthInMeters, err := i.graphService.CalculatePathInformation(path)
+		nodePoints, elevations, lengthInMeters, err := i.graphService.CalculatePathInformation(path, search.vehicle, stats)
 		if err != nil {
-			return nil, fmt.Errorf("error while building geojson line: %s", err.Error())
+			return nil, nil, fmt.Errorf("error while building geojson line: %s", err.Error())
 		}
 
+		ascent, descent, elevationProfile := calculateElevationProfile(nodePoints, elevations)
+
 		nodePoints = append(
-			[]geojson.Point{points[index]},
+			[]geojson.Point{search.points[index]},
 			nodePoints...,
 		)
 
 		nodePoints = append(
 			nodePoints,
-			points[index+1],
+			search.points[index+1],
 		)
 
 		geometry := geojson.LineString(nodePoints).ToGeometry()
@@ -93,16 +313,38 @@ func (i *impl) FindRoute(points []geojson.Point) ([]RouteSegmentInfo, error) {
 			Properties: nil,
 		})
 
-		out = append(out, RouteSegmentInfo{
-			LengthInMeters: lengthInMeters,
-			LengthInTime:   int64(length),
-			GeoJson:        geoJson,
+		out = append(out, route.SegmentInfo{
+			LengthInMeters:   lengthInMeters,
+			LengthInTime:     int64(length),
+			Ascent:           ascent,
+			Descent:          descent,
+			ElevationProfile: elevationProfile,
+			GeoJson:          geoJson,
 		})
+		paths = append(paths, path)
+
+		if search.debug != nil {
+			search.debug.PathTime += milliseconds(time.Since(pathStart))
+		}
 
 		start = end
 	}
 
-	return out, nil
+	return out, paths, nil
+}
+
+// calculatePathTime sums up the weights of the edges of a path in the same way as the search
+func (i *impl) calculatePathTime(path []int64, query graphService.Query) float64 {
+	edges := i.graphService.GetEdges(query)
+
+	var out float64
+	var prev int64
+	for index := 0; index+1 < len(path); index++ {
+		out += edges(prev, path[index])[path[index+1]]
+		prev = path[index]
+	}
+
+	return out
 }
 
 func (i *impl) FindAddresses(query string) ([]*address.Address, error) {
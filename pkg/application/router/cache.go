@@ -0,0 +1,149 @@
+package router
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"math"
+	"time"
+)
+
+const (
+	routeCacheSize = 1024
+	routeCacheTTL  = 10 * time.Minute
+
+	nearNodesCacheSize = 4096
+	nearNodesCellSize  = graphService.NearNodesRadius // degrees
+
+	routeCacheName     = "route"
+	nearNodesCacheName = "nearNodes"
+	tileCacheName      = "tile"
+)
+
+// nearNodesKey identifies the usable nodes around a grid cell
+type nearNodesKey struct {
+	vehicle string
+	lat     int64
+	lon     int64
+}
+
+func vehicleKey(vehicle weightRepository.Vehicle) string {
+	return fmt.Sprintf("%s|%+v", vehicle.Profile.Name, vehicle.Dimensions)
+}
+
+// routeKey identifies a route search by the snapped nodes instead of the coordinates, so nearby waypoints share routes.
+// The active closures are part of the key, because closures with validity times change routes without an update.
+func (i *impl) routeKey(search routeSearch, alternatives int) string {
+	nodeIDs := make([]int64, len(search.nodes))
+	for index, n := range search.nodes {
+		nodeIDs[index] = n.OsmID
+	}
+
+	now := time.Now()
+	var closureIDs []int64
+	for _, c := range i.graphService.GetClosures() {
+		if c.IsActive(now) {
+			closureIDs = append(closureIDs, c.ID)
+		}
+	}
+
+	return fmt.Sprintf("%s|%d|%v|%v|%v", vehicleKey(search.vehicle), alternatives, search.avoidAreas, nodeIDs, closureIDs)
+}
+
+func (i *impl) getCachedRoutes(key string, points []geojson.Point) ([]route.Route, bool) {
+	routes, ok := i.routeCache.Get(key)
+	countCacheRequest(routeCacheName, ok)
+	if !ok {
+		return nil, false
+	}
+
+	return withWaypoints(routes, points), true
+}
+
+// withWaypoints copies cached routes and connects their geometries to the requested waypoints
+func withWaypoints(routes []route.Route, points []geojson.Point) []route.Route {
+	out := make([]route.Route, len(routes))
+	for routeIndex, cached := range routes {
+		segments := make([]route.SegmentInfo, len(cached.Segments))
+		for index, segment := range cached.Segments {
+			segment.GeoJson = withEndpoints(segment.GeoJson, points[index], points[index+1])
+			segments[index] = segment
+		}
+		out[routeIndex] = newRoute(points, segments)
+	}
+	return out
+}
+
+// withEndpoints replaces the first and the last coordinate of the lines of a segment
+func withEndpoints(in geojson.GeoJson, from geojson.Point, to geojson.Point) geojson.GeoJson {
+	out := geojson.NewEmptyGeoJson()
+	for _, feature := range in.Features {
+		feature.Geometry.Coordinates = append([]interface{}(nil), feature.Geometry.Coordinates...)
+		out.AddFeature(feature)
+	}
+
+	if len(out.Features) == 0 {
+		return out
+	}
+
+	first := out.Features[0].Geometry.Coordinates
+	if len(first) > 0 {
+		first[0] = from
+	}
+
+	last := out.Features[len(out.Features)-1].Geometry.Coordinates
+	if len(last) > 0 {
+		last[len(last)-1] = to
+	}
+
+	return out
+}
+
+// nearNodes is GetNearNodes with the usable nodes cached per grid cell.
+// A cell caches the usable nodes of all search areas within the cell, so the result does not depend on the cache.
+func (i *impl) nearNodes(lat float64, lon float64, vehicle weightRepository.Vehicle, stats *graphService.QueryStats) ([]*node.Node, error) {
+	key := nearNodesKey{
+		vehicle: vehicleKey(vehicle),
+		lat:     int64(math.Floor(lat / nearNodesCellSize)),
+		lon:     int64(math.Floor(lon / nearNodesCellSize)),
+	}
+
+	nodes, ok := i.nearNodesCache.Get(key)
+	countCacheRequest(nearNodesCacheName, ok)
+
+	if !ok {
+		centerLat := (float64(key.lat) + 0.5) * nearNodesCellSize
+		centerLon := (float64(key.lon) + 0.5) * nearNodesCellSize
+
+		var err error
+		nodes, err = i.graphService.GetUsableNodes(centerLat, centerLon, nearNodesCellSize/2+graphService.NearNodesRadius, vehicle, stats)
+		if err != nil {
+			return nil, err
+		}
+
+		i.nearNodesCache.Add(key, nodes)
+	}
+
+	return graphService.SortNearNodes(nodes, lat, lon)
+}
+
+// invalidateRoutes removes all cached routes, it has to be called whenever the closures change
+func (i *impl) invalidateRoutes() {
+	i.routeCache.Purge()
+}
+
+// Reload reloads the closures and clears all caches, after the database was changed by another process
+func (i *impl) Reload() error {
+	err := i.graphService.LoadClosures()
+	if err != nil {
+		return fmt.Errorf("error while loading closures: %s", err.Error())
+	}
+
+	i.routeCache.Purge()
+	i.nearNodesCache.Purge()
+	i.tileCache.Purge()
+	return nil
+}
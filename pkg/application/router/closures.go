@@ -0,0 +1,103 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/closure"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+)
+
+// ErrNotFound is returned, if the requested object does not exist
+var ErrNotFound = errors.New("not found")
+
+func (i *impl) GetClosures() []*closure.Closure {
+	return i.graphService.GetClosures()
+}
+
+func (i *impl) AddClosure(c closure.Closure) (*closure.Closure, error) {
+	err := validateClosure(c)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+
+	c.ID = 0
+	added, err := i.graphService.AddClosure(c)
+	if err != nil {
+		return nil, err
+	}
+
+	i.invalidateRoutes()
+	return added, nil
+}
+
+func (i *impl) RemoveClosure(id int64) error {
+	deleted, err := i.graphService.RemoveClosure(id)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("%w: closure %d", ErrNotFound, id)
+	}
+
+	i.invalidateRoutes()
+	return nil
+}
+
+func validateClosure(c closure.Closure) error {
+	targets := 0
+	if c.WayID != 0 {
+		targets++
+	}
+	if c.Nodes != nil {
+		targets++
+	}
+	if c.Polygon != nil {
+		targets++
+	}
+
+	if targets != 1 {
+		return fmt.Errorf("exactly one of wayId, nodes or polygon has to be set")
+	}
+
+	if c.Nodes != nil && (len(c.Nodes) != 2 || c.Nodes[0] == c.Nodes[1]) {
+		return fmt.Errorf("nodes has to contain two different node ids")
+	}
+
+	if c.Polygon != nil {
+		err := validatePolygon(c.Polygon)
+		if err != nil {
+			return err
+		}
+	}
+
+	// a missing factor would silently close the road
+	if c.SpeedFactor == nil {
+		return fmt.Errorf("speedFactor is required, use 0 to close the road")
+	}
+
+	// faster speeds would make the heuristic overestimate the remaining costs
+	if *c.SpeedFactor < 0 || *c.SpeedFactor > 1 {
+		return fmt.Errorf("speedFactor has to be between 0 (closed) and 1")
+	}
+
+	if c.ValidFrom != nil && c.ValidUntil != nil && !c.ValidFrom.Before(*c.ValidUntil) {
+		return fmt.Errorf("validFrom has to be before validUntil")
+	}
+
+	return nil
+}
+
+func validatePolygon(polygon geojson.Polygon) error {
+	if len(polygon) == 0 {
+		return fmt.Errorf("polygon needs an outer ring")
+	}
+
+	for _, ring := range polygon {
+		if len(ring) < 3 {
+			return fmt.Errorf("polygon rings need at least three points")
+		}
+	}
+
+	return nil
+}
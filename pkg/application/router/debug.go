@@ -0,0 +1,119 @@
+package router
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/astar"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"time"
+)
+
+// maxDebugElements limits the settled nodes and the relaxed edges of the search space, that are returned
+const maxDebugElements = 20000
+
+// searchDebug records the search of a route and builds its route.Debug
+type searchDebug struct {
+	route.Debug
+
+	stats   graphService.QueryStats
+	settled []debugNode
+	relaxed []debugEdge
+}
+
+type debugNode struct {
+	segment   int
+	id        int64
+	gScore    float64
+	heuristic float64
+}
+
+type debugEdge struct {
+	segment  int
+	from     int64
+	to       int64
+	weight   float64
+	improved bool
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
+
+// hooks records the search of a segment
+func (d *searchDebug) hooks(segment int) *astar.Hooks[int64, float64] {
+	return &astar.Hooks[int64, float64]{
+		OnSettle: func(id int64, gScore float64, heuristic float64) {
+			d.Iterations++
+			if len(d.settled) >= maxDebugElements {
+				d.Truncated = true
+				return
+			}
+			d.settled = append(d.settled, debugNode{segment, id, gScore, heuristic})
+		},
+		OnRelax: func(from int64, to int64, weight float64, improved bool) {
+			if len(d.relaxed) >= maxDebugElements {
+				d.Truncated = true
+				return
+			}
+			d.relaxed = append(d.relaxed, debugEdge{segment, from, to, weight, improved})
+		},
+	}
+}
+
+// buildSearchSpace converts the recorded search into geojson, the nodes are located without counting the queries
+func (d *searchDebug) buildSearchSpace(selectNode func(id int64) (*node.Node, error)) {
+	d.Queries = d.stats.Queries()
+	d.SearchSpace = geojson.NewEmptyGeoJson()
+
+	locations := make(map[int64]geojson.Point)
+	locate := func(id int64) (geojson.Point, bool) {
+		if location, ok := locations[id]; ok {
+			return location, true
+		}
+
+		n, err := selectNode(id)
+		if err != nil || n == nil {
+			return geojson.Point{}, false
+		}
+
+		locations[id] = geojson.NewPoint(n.Lon, n.Lat)
+		return locations[id], true
+	}
+
+	for order, settled := range d.settled {
+		location, ok := locate(settled.id)
+		if !ok {
+			continue
+		}
+
+		feature := geojson.NewFeature(location.ToGeometry())
+		feature.Properties["segment"] = settled.segment
+		feature.Properties["osmId"] = settled.id
+		feature.Properties["order"] = order
+		feature.Properties["g"] = settled.gScore
+		feature.Properties["h"] = settled.heuristic
+		feature.Properties["f"] = settled.gScore + settled.heuristic
+		d.SearchSpace.AddFeature(feature)
+	}
+
+	for _, relaxed := range d.relaxed {
+		from, ok := locate(relaxed.from)
+		if !ok {
+			continue
+		}
+
+		to, ok := locate(relaxed.to)
+		if !ok {
+			continue
+		}
+
+		feature := geojson.NewFeature(geojson.LineString{from, to}.ToGeometry())
+		feature.Properties["segment"] = relaxed.segment
+		feature.Properties["from"] = relaxed.from
+		feature.Properties["to"] = relaxed.to
+		feature.Properties["weight"] = relaxed.weight
+		feature.Properties["improved"] = relaxed.improved
+		d.SearchSpace.AddFeature(feature)
+	}
+}
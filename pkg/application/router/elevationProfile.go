@@ -0,0 +1,48 @@
+package router
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"math"
+)
+
+// calculateElevationProfile sums up ascent and descent along the path
+// and returns the profile as [distance from start, elevation] pairs, points without elevation are skipped
+func calculateElevationProfile(points []geojson.Point, elevations []float64) (ascent float64, descent float64, profile [][2]float64) {
+	profile = make([][2]float64, 0, len(points))
+
+	distance := 0.0
+	prevElevation := math.NaN()
+
+	for index, point := range points {
+		// consecutive path segments share their connecting node
+		if index > 0 && point == points[index-1] {
+			continue
+		}
+
+		if index > 0 {
+			distance += sphericmath.CalcDistanceInMeters(
+				sphericmath.NewPoint(points[index-1].Lon(), points[index-1].Lat()),
+				sphericmath.NewPoint(point.Lon(), point.Lat()),
+			)
+		}
+
+		elevation := elevations[index]
+		if math.IsNaN(elevation) {
+			continue
+		}
+
+		if !math.IsNaN(prevElevation) {
+			if elevation > prevElevation {
+				ascent += elevation - prevElevation
+			} else {
+				descent += prevElevation - elevation
+			}
+		}
+
+		profile = append(profile, [2]float64{distance, elevation})
+		prevElevation = elevation
+	}
+
+	return ascent, descent, profile
+}
@@ -0,0 +1,144 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/astar"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"math"
+)
+
+// maxMatrixCells limits the routes, that are searched for a matrix
+const maxMatrixCells = 625
+
+// MatrixCell is the best route between a source and a destination
+type MatrixCell struct {
+	LengthInMeters float64
+	LengthInTime   int64
+}
+
+// FindMatrix finds the best route from every source to every destination with one search per source.
+// Cells of unconnected pairs and of points without a near road are nil, cells of equal points have zero length.
+func (i *impl) FindMatrix(sources []geojson.Point, destinations []geojson.Point, options RouteOptions) ([][]*MatrixCell, error) {
+	if len(sources) == 0 {
+		return nil, &FieldError{Field: "sources", Message: "at least one source is required"}
+	}
+
+	if len(destinations) == 0 {
+		return nil, &FieldError{Field: "destinations", Message: "at least one destination is required"}
+	}
+
+	if len(sources)*len(destinations) > maxMatrixCells {
+		return nil, &FieldError{Field: "destinations", Message: fmt.Sprintf("too many routes, at most %d sources times destinations are supported", maxMatrixCells)}
+	}
+
+	if options.Profile == TransitProfileName {
+		return nil, &FieldError{Field: "profile", Message: "not supported for matrices"}
+	}
+
+	for index, area := range options.AvoidAreas {
+		err := validatePolygon(area)
+		if err != nil {
+			return nil, &FieldError{Field: fmt.Sprintf("avoid.areas[%d]", index), Message: err.Error()}
+		}
+	}
+
+	vehicle, err := i.getVehicle(options.Profile, options.Dimensions)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceNodes, err := i.snapMatrixPoints(sources, vehicle, options.AvoidAreas)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationNodes, err := i.snapMatrixPoints(destinations, vehicle, options.AvoidAreas)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []node.Node
+	var targetIDs []int64
+	for _, destination := range destinationNodes {
+		if destination != nil {
+			targets = append(targets, *destination)
+			targetIDs = append(targetIDs, destination.OsmID)
+		}
+	}
+
+	out := make([][]*MatrixCell, len(sources))
+	for sourceIndex, source := range sources {
+		out[sourceIndex] = make([]*MatrixCell, len(destinations))
+
+		for destinationIndex, destination := range destinations {
+			if source == destination {
+				out[sourceIndex][destinationIndex] = &MatrixCell{}
+			}
+		}
+
+		if sourceNodes[sourceIndex] == nil || len(targets) == 0 {
+			// points without a near road stay nil
+			continue
+		}
+
+		tree, err := astar.DijkstraToTargets[int64, float64](
+			sourceNodes[sourceIndex].OsmID,
+			targetIDs,
+			i.graphService.GetEdges(graphService.Query{Vehicle: vehicle, Start: *sourceNodes[sourceIndex], End: targets[0], Targets: targets[1:], AvoidAreas: options.AvoidAreas}),
+			math.MaxFloat64,
+			maxVisitedNodes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error while searching the routes of source %d: %s", sourceIndex, err.Error())
+		}
+
+		for destinationIndex, destination := range destinationNodes {
+			if destination == nil || out[sourceIndex][destinationIndex] != nil {
+				continue
+			}
+
+			length, ok := tree.Costs[destination.OsmID]
+			if !ok {
+				// unreachable pairs stay nil
+				continue
+			}
+
+			lengthInMeters := 0.0
+			if path := tree.Path(destination.OsmID); len(path) > 1 {
+				_, _, lengthInMeters, err = i.graphService.CalculatePathInformation(path, vehicle, nil)
+				if err != nil {
+					return nil, fmt.Errorf("error while calculating the length of route %d to %d: %s", sourceIndex, destinationIndex, err.Error())
+				}
+			}
+
+			out[sourceIndex][destinationIndex] = &MatrixCell{
+				LengthInMeters: lengthInMeters,
+				LengthInTime:   int64(length),
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// snapMatrixPoints finds the nodes of the points, points without a near road get a nil node
+func (i *impl) snapMatrixPoints(points []geojson.Point, vehicle weightRepository.Vehicle, avoidAreas []geojson.Polygon) ([]*node.Node, error) {
+	out := make([]*node.Node, len(points))
+	for index, point := range points {
+		nodes, err := i.snapWaypoints([]Waypoint{{Location: point}}, vehicle, avoidAreas, nil)
+		if errors.Is(err, ErrNoNearNode) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		out[index] = nodes[0]
+	}
+
+	return out, nil
+}
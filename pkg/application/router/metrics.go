@@ -0,0 +1,40 @@
+package router
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/metrics"
+)
+
+// routeIterationBuckets are the upper bounds of the settled nodes of a route search
+var routeIterationBuckets = []float64{10, 100, 1000, 10000, 100000, 1000000}
+
+var (
+	routeIterations = metrics.Default.NewHistogramVec(
+		"gosmroutify_route_search_iterations",
+		"Settled nodes per route search between two waypoints.",
+		routeIterationBuckets,
+		"profile",
+	)
+	snappingFailures = metrics.Default.NewCounterVec(
+		"gosmroutify_snapping_failures_total",
+		"Waypoints, that could not be snapped to a road.",
+		"profile",
+	)
+	noRouteFound = metrics.Default.NewCounterVec(
+		"gosmroutify_no_route_found_total",
+		"Route searches, that found no connection between two waypoints.",
+		"profile",
+	)
+	cacheRequests = metrics.Default.NewCounterVec(
+		"gosmroutify_cache_requests_total",
+		"Cache lookups per cache and result (hit or miss).",
+		"cache", "result",
+	)
+)
+
+func countCacheRequest(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.With(cache, result).Inc()
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+)
+
+// CheckSnap snaps the location of a node of the road graph, to verify that routes can be searched
+func (i *impl) CheckSnap() error {
+	sample, err := i.nodeService.SelectSampleNode()
+	if err != nil {
+		return fmt.Errorf("error while selecting sample node: %s", err.Error())
+	}
+
+	// pedestrians can use almost every way, so the sample node is usable with this profile
+	vehicle, err := i.getVehicle(transitAccessProfile, weightRepository.VehicleDimensions{})
+	if err != nil {
+		return fmt.Errorf("error while loading profile: %s", err.Error())
+	}
+
+	_, err = i.graphService.GetNearNodes(sample.Lat, sample.Lon, vehicle, nil)
+	if err != nil {
+		return fmt.Errorf("error while snapping sample node %d: %s", sample.OsmID, err.Error())
+	}
+
+	return nil
+}
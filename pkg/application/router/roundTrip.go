@@ -0,0 +1,181 @@
+package router
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	roundTripReusePenalty     = 5.0     // factor for edges that are already part of the loop
+	roundTripDetourFactor     = 1.3     // roads are longer than the beeline between the waypoints
+	roundTripSpeedFactor      = 0.6     // share of the maximum speed used to estimate the distance of a duration
+	roundTripTolerance        = 0.2     // relative deviation of the length, after which the loop is scaled once
+	roundTripMaxDistance      = 300_000 // meters
+	roundTripDefaultCount     = 3
+	roundTripMaxCount         = 5
+	roundTripAttemptsPerCount = 3
+)
+
+// RoundTripOptions are the parameters of a round trip request, either Distance or Duration has to be set
+type RoundTripOptions struct {
+	Profile    string
+	Dimensions weightRepository.VehicleDimensions
+	Distance   float64 // meters
+	Duration   float64 // seconds
+	Seed       int64
+	Candidates int
+}
+
+// roundTripCandidate is a round trip with its deviation from the requested distance or duration
+type roundTripCandidate struct {
+	roundTrip route.RoundTrip
+	deviation float64
+}
+
+// FindRoundTrips generates loops from start by routing through two waypoints, that form a triangle with the start
+// in a random direction. The candidates are sorted by their deviation from the requested distance or duration.
+func (i *impl) FindRoundTrips(start geojson.Point, options RoundTripOptions) ([]route.RoundTrip, error) {
+	if options.Profile == TransitProfileName {
+		return nil, fmt.Errorf("%w: round trips are not supported for transit", ErrInvalidRequest)
+	}
+
+	vehicle, err := i.getVehicle(options.Profile, options.Dimensions)
+	if err != nil {
+		return nil, err
+	}
+
+	distance := options.Distance
+	if distance <= 0 && options.Duration > 0 {
+		distance = options.Duration * vehicle.Profile.MaxSpeed / 3.6 * roundTripSpeedFactor
+	}
+
+	if distance <= 0 || distance > roundTripMaxDistance {
+		return nil, fmt.Errorf("%w: distance or duration must be positive and at most %d km", ErrInvalidRequest, roundTripMaxDistance/1000)
+	}
+
+	candidates := options.Candidates
+	if candidates == 0 {
+		candidates = roundTripDefaultCount
+	}
+
+	if candidates < 0 || candidates > roundTripMaxCount {
+		return nil, fmt.Errorf("%w: candidates must be between 1 and %d", ErrInvalidRequest, roundTripMaxCount)
+	}
+
+	random := rand.New(rand.NewSource(options.Seed))
+
+	var found []roundTripCandidate
+	for attempt := 0; attempt < candidates*roundTripAttemptsPerCount && len(found) < candidates; attempt++ {
+		bearing := random.Float64() * 2 * math.Pi
+
+		roundTrip, err := i.findRoundTrip(start, bearing, distance, vehicle)
+		if err != nil {
+			i.logger.Debug().Msgf("skipping round trip candidate: %s", err.Error())
+			continue
+		}
+
+		deviation := math.Abs(float64(roundTrip.LengthInTime)-options.Duration) / options.Duration
+		if options.Distance > 0 {
+			deviation = math.Abs(roundTrip.LengthInMeters-options.Distance) / options.Distance
+		}
+
+		found = append(found, roundTripCandidate{roundTrip: *roundTrip, deviation: deviation})
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no round trip found")
+	}
+
+	sort.SliceStable(found, func(a, b int) bool {
+		return found[a].deviation < found[b].deviation
+	})
+
+	out := make([]route.RoundTrip, len(found))
+	for index, candidate := range found {
+		out[index] = candidate.roundTrip
+	}
+
+	return out, nil
+}
+
+func (i *impl) findRoundTrip(start geojson.Point, bearing float64, distance float64, vehicle weightRepository.Vehicle) (*route.RoundTrip, error) {
+	side := distance / 3 / roundTripDetourFactor
+
+	roundTrip, err := i.routeRoundTrip(start, bearing, side, vehicle)
+	if err != nil {
+		return nil, err
+	}
+
+	deviation := roundTrip.LengthInMeters / distance
+	if math.Abs(deviation-1) <= roundTripTolerance || roundTrip.LengthInMeters == 0 {
+		return roundTrip, nil
+	}
+
+	scaled, err := i.routeRoundTrip(start, bearing, side/deviation, vehicle)
+	if err != nil || math.Abs(scaled.LengthInMeters/distance-1) > math.Abs(deviation-1) {
+		return roundTrip, nil
+	}
+
+	return scaled, nil
+}
+
+// routeRoundTrip routes through an equilateral triangle with the given side length
+func (i *impl) routeRoundTrip(start geojson.Point, bearing float64, side float64, vehicle weightRepository.Vehicle) (*route.RoundTrip, error) {
+	origin := sphericmath.NewPoint(start.Lon(), start.Lat())
+	first := sphericmath.CalculateDestination(origin, bearing, side)
+	second := sphericmath.CalculateDestination(origin, bearing+math.Pi/3, side)
+
+	waypoints := []geojson.Point{
+		start,
+		geojson.NewPoint(first.Lon(), first.Lat()),
+		geojson.NewPoint(second.Lon(), second.Lat()),
+		start,
+	}
+
+	nodes, err := i.snapWaypoints(toWaypoints(waypoints), vehicle, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, _, err := i.findRoute(routeSearch{
+		points:       waypoints,
+		nodes:        nodes,
+		vehicle:      vehicle,
+		reusePenalty: roundTripReusePenalty,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &route.RoundTrip{
+		Waypoints: waypoints[1:3],
+		GeoJson:   geojson.NewEmptyGeoJson(),
+	}
+
+	var line geojson.LineString
+	for _, segment := range segments {
+		out.LengthInMeters += segment.LengthInMeters
+		out.LengthInTime += segment.LengthInTime
+		out.Ascent += segment.Ascent
+		out.Descent += segment.Descent
+
+		for _, segmentLine := range segment.Lines() {
+			for _, point := range segmentLine {
+				if len(line) > 0 && line[len(line)-1] == point {
+					continue
+				}
+				line = append(line, point)
+			}
+		}
+	}
+
+	out.GeoJson.AddFeature(geojson.NewFeature(line.ToGeometry()))
+
+	return out, nil
+}
@@ -0,0 +1,327 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"math"
+	"time"
+)
+
+const (
+	defaultHeadingTolerance = 45.0 // degrees
+
+	maxAlternatives       = 3
+	alternativePenalty    = 1.5  // factor for the edges of routes, that are already found
+	alternativeMaxShare   = 0.75 // share of edges, that an alternative may have in common with the routes found before
+	alternativeMaxStretch = 1.5  // time of an alternative relative to the best route
+	alternativeAttempts   = 3    // searches per requested alternative
+)
+
+// Waypoint is a point of a route request with optional hints for snapping it to the graph
+type Waypoint struct {
+	Location geojson.Point
+
+	// Heading is the direction of travel at the waypoint in degrees clockwise from north
+	Heading          *float64
+	HeadingTolerance float64 // degrees, defaultHeadingTolerance if zero
+
+	Radius float64 // maximum snapping distance in meters, unlimited if zero
+	NodeID int64   // node to use instead of snapping, e.g. from a previous response
+}
+
+func toWaypoints(points []geojson.Point) []Waypoint {
+	out := make([]Waypoint, len(points))
+	for index, point := range points {
+		out[index] = Waypoint{Location: point}
+	}
+	return out
+}
+
+func newRoute(waypoints []geojson.Point, segments []route.SegmentInfo) route.Route {
+	out := route.Route{Waypoints: waypoints, Segments: segments}
+	for _, segment := range segments {
+		out.LengthInMeters += segment.LengthInMeters
+		out.LengthInTime += segment.LengthInTime
+	}
+	return out
+}
+
+// FindRoutes finds the best route through the waypoints and up to options.Alternatives alternative routes
+func (i *impl) FindRoutes(waypoints []Waypoint, options RouteOptions) ([]route.Route, error) {
+	if len(waypoints) < 2 {
+		return nil, &FieldError{Field: "waypoints", Message: "at least two waypoints are required"}
+	}
+
+	for index, area := range options.AvoidAreas {
+		err := validatePolygon(area)
+		if err != nil {
+			return nil, &FieldError{Field: fmt.Sprintf("avoid.areas[%d]", index), Message: err.Error()}
+		}
+	}
+
+	if options.Alternatives < 0 || options.Alternatives > maxAlternatives {
+		return nil, &FieldError{Field: "alternatives", Message: fmt.Sprintf("has to be between 0 and %d", maxAlternatives)}
+	}
+
+	// waypoints given by node get the location of the node
+	waypoints = append([]Waypoint(nil), waypoints...)
+
+	if options.Profile == TransitProfileName {
+		if options.Alternatives > 0 {
+			return nil, &FieldError{Field: "alternatives", Message: "not supported for transit"}
+		}
+
+		if options.Debug {
+			return nil, &FieldError{Field: "debug", Message: "not supported for transit"}
+		}
+
+		segments, err := i.findTransitRoute(waypoints, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return []route.Route{newRoute(waypointLocations(waypoints), segments)}, nil
+	}
+
+	vehicle, err := i.getVehicle(options.Profile, options.Dimensions)
+	if err != nil {
+		return nil, err
+	}
+
+	var debug *searchDebug
+	var stats *graphService.QueryStats
+	if options.Debug {
+		debug = &searchDebug{}
+		stats = &debug.stats
+	}
+
+	snappingStart := time.Now()
+	nodes, err := i.snapWaypoints(waypoints, vehicle, options.AvoidAreas, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	if debug != nil {
+		debug.SnappingTime = milliseconds(time.Since(snappingStart))
+	}
+
+	search := routeSearch{
+		points:     waypointLocations(waypoints),
+		nodes:      nodes,
+		vehicle:    vehicle,
+		avoidAreas: options.AvoidAreas,
+		debug:      debug,
+	}
+
+	// the search space is only recorded without cache
+	var cacheKey string
+	if debug == nil {
+		cacheKey = i.routeKey(search, options.Alternatives)
+		if cached, ok := i.getCachedRoutes(cacheKey, search.points); ok {
+			return cached, nil
+		}
+	}
+
+	best, bestPaths, err := i.findRoute(search)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []route.Route{newRoute(search.points, best)}
+	if debug != nil {
+		debug.buildSearchSpace(i.nodeService.SelectNodeFromID)
+		out[0].Debug = &debug.Debug
+	}
+
+	search.debug = nil
+	if options.Alternatives > 0 {
+		out = append(out, i.findAlternatives(search, out[0], bestPaths, options.Alternatives)...)
+	}
+
+	if debug == nil {
+		i.routeCache.Add(cacheKey, out)
+		return withWaypoints(out, search.points), nil
+	}
+
+	return out, nil
+}
+
+// findAlternatives penalises the edges of the routes found so far, until the search finds routes that differ enough.
+// bestPaths are the node paths of the segments of best.
+func (i *impl) findAlternatives(search routeSearch, best route.Route, bestPaths [][]int64, count int) []route.Route {
+	penalties := make(map[graphService.EdgeKey]float64)
+	used := make(map[graphService.EdgeKey]bool)
+
+	penalise := func(paths [][]int64, markUsed bool) {
+		for _, key := range pathEdges(paths) {
+			penalty, ok := penalties[key]
+			if !ok {
+				penalty = 1
+			}
+			penalties[key] = penalty * alternativePenalty
+
+			if markUsed {
+				used[key] = true
+			}
+		}
+	}
+
+	penalise(bestPaths, true)
+	search.edgePenalties = penalties
+
+	var out []route.Route
+	for attempt := 0; attempt < count*alternativeAttempts && len(out) < count; attempt++ {
+		segments, paths, err := i.findRoute(search)
+		if err != nil {
+			i.logger.Debug().Msgf("stopping search for alternatives: %s", err.Error())
+			break
+		}
+
+		candidate := newRoute(search.points, segments)
+		edges := pathEdges(paths)
+
+		shared := 0
+		for _, key := range edges {
+			if used[key] {
+				shared++
+			}
+		}
+
+		isAlternative := len(edges) > 0 &&
+			float64(shared)/float64(len(edges)) <= alternativeMaxShare &&
+			float64(candidate.LengthInTime) <= float64(best.LengthInTime)*alternativeMaxStretch
+
+		penalise(paths, isAlternative)
+		if isAlternative {
+			out = append(out, candidate)
+		}
+	}
+
+	return out
+}
+
+func waypointLocations(waypoints []Waypoint) []geojson.Point {
+	out := make([]geojson.Point, len(waypoints))
+	for index, waypoint := range waypoints {
+		out[index] = waypoint.Location
+	}
+	return out
+}
+
+func pathEdges(paths [][]int64) []graphService.EdgeKey {
+	var out []graphService.EdgeKey
+	for _, path := range paths {
+		for index := 1; index < len(path); index++ {
+			out = append(out, graphService.NewEdgeKey(path[index-1], path[index]))
+		}
+	}
+	return out
+}
+
+// snapWaypoints finds the nodes of the waypoints and sets the location of waypoints, that are only given by node
+func (i *impl) snapWaypoints(waypoints []Waypoint, vehicle weightRepository.Vehicle, avoidAreas []geojson.Polygon, stats *graphService.QueryStats) ([]*node.Node, error) {
+	nodes := make([]*node.Node, len(waypoints))
+	for index, waypoint := range waypoints {
+		n, err := i.snapWaypoint(index, waypoint, vehicle, avoidAreas, stats)
+		if errors.Is(err, ErrNoNearNode) {
+			snappingFailures.With(vehicle.Profile.Name).Inc()
+		}
+		if err != nil {
+			return nil, err
+		}
+		nodes[index] = n
+
+		if waypoint.NodeID != 0 && waypoint.Location == (geojson.Point{}) {
+			waypoints[index].Location = geojson.NewPoint(n.Lon, n.Lat)
+		}
+	}
+
+	return nodes, nil
+}
+
+// snapWaypoint finds the nearest node within the radius of the waypoint, that has an edge in the direction of its heading
+func (i *impl) snapWaypoint(index int, waypoint Waypoint, vehicle weightRepository.Vehicle, avoidAreas []geojson.Polygon, stats *graphService.QueryStats) (*node.Node, error) {
+	point := waypoint.Location
+
+	if waypoint.NodeID != 0 {
+		stats.Add(1)
+		n, err := i.nodeService.SelectNodeFromID(waypoint.NodeID)
+		if err != nil || n == nil {
+			return nil, &FieldError{Field: fmt.Sprintf("waypoints[%d].nodeId", index), Message: "unknown node"}
+		}
+		return n, nil
+	}
+
+	candidates, err := i.nearNodes(point.Lon(), point.Lat(), vehicle, stats)
+	if errors.Is(err, graphService.ErrNoNearNode) {
+		return nil, fmt.Errorf("%w: [%f, %f]", ErrNoNearNode, point.Lat(), point.Lon())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error while finding nearest node to [%f, %f]: %s", point.Lat(), point.Lon(), err.Error())
+	}
+
+	location := sphericmath.NewPoint(point.Lon(), point.Lat())
+	for _, candidate := range candidates {
+		if waypoint.Radius > 0 && sphericmath.CalcDistanceInMeters(location, sphericmath.NewPoint(candidate.Lat, candidate.Lon)) > waypoint.Radius {
+			break
+		}
+
+		if waypoint.Heading != nil && !i.matchesHeading(candidate, waypoint, vehicle, avoidAreas, stats) {
+			continue
+		}
+
+		return candidate, nil
+	}
+
+	if waypoint.Heading != nil {
+		return nil, fmt.Errorf("%w: no road with heading %.0f near waypoint %d", ErrNoNearNode, *waypoint.Heading, index)
+	}
+
+	return nil, fmt.Errorf("%w: no road within %.0f meters of waypoint %d", ErrNoNearNode, waypoint.Radius, index)
+}
+
+// matchesHeading checks if an edge leaves the node in the direction of the heading,
+// the direction of an edge is approximated by the beeline to the next crossing
+func (i *impl) matchesHeading(n *node.Node, waypoint Waypoint, vehicle weightRepository.Vehicle, avoidAreas []geojson.Polygon, stats *graphService.QueryStats) bool {
+	tolerance := waypoint.HeadingTolerance
+	if tolerance == 0 {
+		tolerance = defaultHeadingTolerance
+	}
+
+	query := graphService.Query{
+		Vehicle:    vehicle,
+		Start:      *n,
+		End:        *n,
+		AvoidAreas: avoidAreas,
+		Stats:      stats,
+	}
+
+	from := sphericmath.NewPoint(n.Lat, n.Lon)
+	for neighbor := range i.graphService.GetEdges(query)(0, n.OsmID) {
+		stats.Add(1)
+		lat, lon, err := i.nodeService.LocateOsmID(neighbor)
+		if err != nil {
+			i.logger.Error().Msgf("error while locating node %d: %s", neighbor, err.Error())
+			continue
+		}
+
+		bearing := sphericmath.CalculateBearing(from, sphericmath.NewPoint(lat, lon)) * 180 / math.Pi
+		difference := math.Mod(math.Abs(bearing-*waypoint.Heading), 360)
+		if difference > 180 {
+			difference = 360 - difference
+		}
+
+		if difference <= tolerance {
+			return true
+		}
+	}
+
+	return false
+}
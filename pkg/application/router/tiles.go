@@ -0,0 +1,124 @@
+package router
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/mvt"
+)
+
+const (
+	minTileZoom   = 12 // lower zoom levels would contain too many ways, their tiles are empty
+	maxTileZoom   = 22
+	tileBuffer    = 64 // tile units around the tile, in which features are included
+	tileCacheSize = 512
+
+	waysLayerName      = "ways"
+	crossingsLayerName = "crossings"
+)
+
+// GetTile encodes the road graph within a tile as Mapbox Vector Tile
+func (i *impl) GetTile(z, x, y int) ([]byte, error) {
+	tile := mvt.TileID{Z: z, X: x, Y: y}
+	if !tile.IsValid() || z > maxTileZoom {
+		return nil, fmt.Errorf("%w: tile %d/%d/%d does not exist", ErrInvalidRequest, z, x, y)
+	}
+
+	if z < minTileZoom {
+		return []byte{}, nil
+	}
+
+	cached, ok := i.tileCache.Get(tile)
+	countCacheRequest(tileCacheName, ok)
+	if ok {
+		return cached, nil
+	}
+
+	out, err := i.encodeTile(tile)
+	if err != nil {
+		return nil, err
+	}
+
+	i.tileCache.Add(tile, out)
+	return out, nil
+}
+
+func (i *impl) encodeTile(tile mvt.TileID) ([]byte, error) {
+	minLat, minLon, maxLat, maxLon := tile.Bounds()
+
+	bufferLat := (maxLat - minLat) * tileBuffer / mvt.DefaultExtent
+	bufferLon := (maxLon - minLon) * tileBuffer / mvt.DefaultExtent
+	minLat, maxLat = minLat-bufferLat, maxLat+bufferLat
+	minLon, maxLon = minLon-bufferLon, maxLon+bufferLon
+
+	network, err := i.graphService.GetNetwork(minLat, minLon, maxLat, maxLon)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading network of tile: %s", err.Error())
+	}
+
+	ways := mvt.NewLayer(waysLayerName)
+	crossings := mvt.NewLayer(crossingsLayerName)
+	seenCrossings := make(map[int64]bool)
+
+	for _, networkWay := range network {
+		line := make([]mvt.TilePoint, len(networkWay.Nodes))
+		for index, n := range networkWay.Nodes {
+			line[index] = tile.Project(n.Lat, n.Lon, ways.Extent)
+
+			isInside := n.Lat >= minLat && n.Lat <= maxLat && n.Lon >= minLon && n.Lon <= maxLon
+			if n.IsCrossing && isInside && !seenCrossings[n.OsmID] {
+				seenCrossings[n.OsmID] = true
+
+				properties := map[string]any{"osmId": n.OsmID}
+				if barrier, ok := n.Tags["barrier"]; ok {
+					properties["barrier"] = barrier
+				}
+
+				crossings.AddFeature(mvt.Feature{
+					ID:         uint64(n.OsmID),
+					Type:       mvt.Point,
+					Geometry:   [][]mvt.TilePoint{{line[index]}},
+					Properties: properties,
+				})
+			}
+		}
+
+		attributes := networkWay.Attributes
+
+		// reversed oneways are drawn in their direction of travel
+		if attributes.Oneway < 0 {
+			for a, b := 0, len(line)-1; a < b; a, b = a+1, b-1 {
+				line[a], line[b] = line[b], line[a]
+			}
+		}
+
+		properties := map[string]any{
+			"osmId":    networkWay.Way.OsmID,
+			"roadType": attributes.RoadType,
+			"maxSpeed": attributes.MaxSpeed,
+			"oneway":   attributes.Oneway != 0,
+		}
+
+		for _, tag := range []string{"highway", "name"} {
+			if value, ok := networkWay.Way.Tags[tag]; ok {
+				properties[tag] = value
+			}
+		}
+
+		for profile, access := range attributes.Access {
+			properties["access:"+profile] = access.String()
+		}
+
+		ways.AddFeature(mvt.Feature{
+			ID:         uint64(networkWay.Way.OsmID),
+			Type:       mvt.LineString,
+			Geometry:   [][]mvt.TilePoint{line},
+			Properties: properties,
+		})
+	}
+
+	out, err := (&mvt.Tile{Layers: []*mvt.Layer{ways, crossings}}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("error while encoding tile: %s", err.Error())
+	}
+
+	return out, nil
+}
@@ -0,0 +1,264 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/transit"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/transitService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/arrayutil"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/astar"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"math"
+	"time"
+)
+
+const (
+	TransitProfileName = "transit"
+
+	transitAccessProfile  = "pedestrian"
+	maxAccessTime         = 15 * 60 // seconds of walking to and from the stops
+	maxAccessVisitedNodes = 100000
+	walkMode              = "walk"
+)
+
+// routeLeg is a leg with the points of its line
+type routeLeg struct {
+	route.Leg
+
+	points []geojson.Point
+}
+
+// findTransitRoute combines walking over the graph with scheduled transit legs, every segment departs at the arrival of the previous one
+func (i *impl) findTransitRoute(waypoints []Waypoint, options RouteOptions) ([]route.SegmentInfo, error) {
+	if !i.transitService.HasTimetable() {
+		return nil, fmt.Errorf("%w: no transit data imported", ErrInvalidRequest)
+	}
+
+	profile, err := i.graphService.GetProfile(transitAccessProfile)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting access profile: %s", err.Error())
+	}
+
+	vehicle := weightRepository.Vehicle{Profile: profile}
+
+	nodes, err := i.snapWaypoints(waypoints, vehicle, options.AvoidAreas, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	stopsByNode := make(map[int64][]*transit.Stop)
+	for _, stop := range i.transitService.GetStops() {
+		if stop.NodeID != 0 {
+			stopsByNode[stop.NodeID] = append(stopsByNode[stop.NodeID], stop)
+		}
+	}
+
+	departure := options.Departure
+	if departure.IsZero() {
+		departure = time.Now()
+	}
+
+	out := make([]route.SegmentInfo, 0, len(waypoints)-1)
+	for index := range nodes[1:] {
+		legs, err := i.findTransitLegs(*nodes[index], *nodes[index+1], vehicle, options.AvoidAreas, stopsByNode, departure)
+		if err != nil {
+			return nil, err
+		}
+
+		legs[0].points = append([]geojson.Point{waypoints[index].Location}, legs[0].points...)
+		legs[len(legs)-1].points = append(legs[len(legs)-1].points, waypoints[index+1].Location)
+
+		geoJson := geojson.NewEmptyGeoJson()
+		segmentLegs := make([]route.Leg, len(legs))
+		var lengthInMeters float64
+		for legIndex, leg := range legs {
+			segmentLegs[legIndex] = leg.Leg
+			lengthInMeters += leg.LengthInMeters
+
+			feature := geojson.NewFeature(geojson.LineString(leg.points).ToGeometry())
+			feature.Properties["mode"] = leg.Mode
+			if leg.Route != "" {
+				feature.Properties["route"] = leg.Route
+			}
+			geoJson.AddFeature(feature)
+		}
+
+		arrival := legs[len(legs)-1].Arrival
+
+		out = append(out, route.SegmentInfo{
+			LengthInMeters: lengthInMeters,
+			LengthInTime:   int64(arrival.Sub(departure).Seconds()),
+			GeoJson:        geoJson,
+			Legs:           segmentLegs,
+		})
+
+		departure = arrival
+	}
+
+	return out, nil
+}
+
+func (i *impl) findTransitLegs(start node.Node, end node.Node, vehicle weightRepository.Vehicle, avoidAreas []geojson.Polygon, stopsByNode map[int64][]*transit.Stop, departure time.Time) ([]routeLeg, error) {
+	// stops are linked to the nearest node, which is often no crossing, so they have to be searched as targets
+	accessTree, err := astar.Dijkstra[int64, float64](
+		start.OsmID,
+		i.graphService.GetEdges(graphService.Query{Vehicle: vehicle, Start: start, End: end, Targets: i.nearStops(start, vehicle, stopsByNode), AvoidAreas: avoidAreas}),
+		maxAccessTime,
+		maxAccessVisitedNodes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while searching stops near the start: %s", err.Error())
+	}
+
+	// the search from the end follows the edges backwards, so that the ways to the end respect oneways
+	egressTree, err := astar.Dijkstra[int64, float64](
+		end.OsmID,
+		i.graphService.GetReverseEdges(graphService.Query{Vehicle: vehicle, Start: start, End: end, Targets: i.nearStops(end, vehicle, stopsByNode), AvoidAreas: avoidAreas}),
+		maxAccessTime,
+		maxAccessVisitedNodes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while searching stops near the destination: %s", err.Error())
+	}
+
+	access := reachableStops(accessTree, stopsByNode)
+	egress := reachableStops(egressTree, stopsByNode)
+
+	walkTime, canWalk := accessTree.Costs[end.OsmID]
+
+	journey, err := i.transitService.FindJourney(access, egress, departure)
+	if errors.Is(err, transitService.ErrNoJourney) {
+		journey = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error while searching transit journey: %s", err.Error())
+	}
+
+	if journey == nil || len(journey.Legs) == 0 {
+		if !canWalk {
+			noRouteFound.With(TransitProfileName).Inc()
+			return nil, fmt.Errorf("%w: no transit connection", ErrNoRoute)
+		}
+
+		walk, err := i.walkLeg(accessTree.Path(end.OsmID), departure, walkTime, vehicle)
+		if err != nil {
+			return nil, err
+		}
+		return []routeLeg{walk}, nil
+	}
+
+	lastLeg := journey.Legs[len(journey.Legs)-1]
+	egressTime := egress[journey.EgressStop.ID]
+	arrival := lastLeg.Arrival.Add(time.Duration(egressTime * float64(time.Second)))
+
+	if canWalk && !departure.Add(time.Duration(walkTime*float64(time.Second))).After(arrival) {
+		walk, err := i.walkLeg(accessTree.Path(end.OsmID), departure, walkTime, vehicle)
+		if err != nil {
+			return nil, err
+		}
+		return []routeLeg{walk}, nil
+	}
+
+	accessWalk, err := i.walkLeg(accessTree.Path(journey.AccessStop.NodeID), departure, access[journey.AccessStop.ID], vehicle)
+	if err != nil {
+		return nil, err
+	}
+
+	legs := []routeLeg{accessWalk}
+	for _, leg := range journey.Legs {
+		legs = append(legs, transitLeg(leg))
+	}
+
+	egressWalk, err := i.walkLeg(arrayutil.Reverse(egressTree.Path(journey.EgressStop.NodeID)), lastLeg.Arrival, egressTime, vehicle)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(legs, egressWalk), nil
+}
+
+// nearStops returns the nodes of the stops, that may be reached from n within the maximum access time
+func (i *impl) nearStops(n node.Node, vehicle weightRepository.Vehicle, stopsByNode map[int64][]*transit.Stop) []node.Node {
+	radius := maxAccessTime * vehicle.Profile.MaxSpeed / 3.6
+	from := sphericmath.NewPoint(n.Lat, n.Lon)
+
+	var out []node.Node
+	for nodeID, stops := range stopsByNode {
+		if sphericmath.CalcDistanceInMeters(from, sphericmath.NewPoint(stops[0].Lat, stops[0].Lon)) > radius {
+			continue
+		}
+
+		stopNode, err := i.nodeService.SelectNodeFromID(nodeID)
+		if err != nil || stopNode == nil {
+			continue
+		}
+		out = append(out, *stopNode)
+	}
+	return out
+}
+
+func reachableStops(tree *astar.ShortestPathTree[int64, float64], stopsByNode map[int64][]*transit.Stop) map[string]float64 {
+	out := make(map[string]float64)
+	for nodeID, cost := range tree.Costs {
+		for _, stop := range stopsByNode[nodeID] {
+			out[stop.ID] = cost
+		}
+	}
+	return out
+}
+
+func (i *impl) walkLeg(path []int64, departure time.Time, seconds float64, vehicle weightRepository.Vehicle) (routeLeg, error) {
+	leg := routeLeg{Leg: route.Leg{
+		Mode:      walkMode,
+		Departure: departure,
+		Arrival:   departure.Add(time.Duration(math.Ceil(seconds)) * time.Second),
+	}}
+
+	if len(path) < 2 {
+		return leg, nil
+	}
+
+	points, _, lengthInMeters, err := i.graphService.CalculatePathInformation(path, vehicle, nil)
+	if err != nil {
+		return routeLeg{}, fmt.Errorf("error while building walking leg: %s", err.Error())
+	}
+
+	leg.points = points
+	leg.LengthInMeters = lengthInMeters
+	return leg, nil
+}
+
+// transitLeg connects the stops of a leg with straight lines, as the shapes of the feed are not imported
+func transitLeg(leg transit.Leg) routeLeg {
+	out := routeLeg{Leg: route.Leg{
+		Mode:      walkMode,
+		From:      leg.Stops[0].Name,
+		To:        leg.Stops[len(leg.Stops)-1].Name,
+		Departure: leg.Departure,
+		Arrival:   leg.Arrival,
+	}}
+
+	if leg.Trip != nil {
+		out.Mode = leg.Trip.Mode
+		out.Route = leg.Trip.RouteName
+		out.Headsign = leg.Trip.Headsign
+	}
+
+	for index, stop := range leg.Stops {
+		out.points = append(out.points, geojson.NewPoint(stop.Lon, stop.Lat))
+
+		if index > 0 {
+			prev := leg.Stops[index-1]
+			out.LengthInMeters += sphericmath.CalcDistanceInMeters(
+				sphericmath.NewPoint(prev.Lat, prev.Lon),
+				sphericmath.NewPoint(stop.Lat, stop.Lon),
+			)
+		}
+	}
+
+	return out
+}
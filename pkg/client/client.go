@@ -0,0 +1,221 @@
+// Package client is a Go client for the HTTP API of the router.
+// Requests and responses use the route entity, so client and server share their schema.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultBackoff    = 250 * time.Millisecond
+	maxBackoff        = 30 * time.Second
+
+	apiKeyHeader = "X-Api-Key"
+)
+
+type Client interface {
+	// Route finds the best route through the waypoints and the requested alternatives, using POST /api/route
+	Route(ctx context.Context, request RouteRequest) (*route.Response, error)
+	// RoundTrip finds round trips starting and ending at start, using GET /api/roundtrip
+	RoundTrip(ctx context.Context, start geojson.Point, request RoundTripRequest) ([]route.RoundTrip, error)
+	// Search finds addresses matching the query, using GET /api/search
+	Search(ctx context.Context, query string) ([]*address.Address, error)
+	// Locate returns the location of an address found by Search, using GET /api/locate
+	Locate(ctx context.Context, id int64) (geojson.Point, error)
+}
+
+type Config struct {
+	BaseURL    string       // e.g. https://api.gosmroutify.xyz
+	ApiKey     string       // sent as X-Api-Key, if not empty
+	HTTPClient *http.Client // defaults to http.DefaultClient
+
+	MaxRetries int           // retries of requests failing with 429 or 5xx, defaults to DefaultMaxRetries, negative disables retries
+	Backoff    time.Duration // wait before the first retry, it doubles with every retry, defaults to DefaultBackoff
+}
+
+type impl struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+func New(config Config) Client {
+	out := &impl{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		apiKey:     config.ApiKey,
+		httpClient: config.HTTPClient,
+		maxRetries: config.MaxRetries,
+		backoff:    config.Backoff,
+	}
+
+	if out.httpClient == nil {
+		out.httpClient = http.DefaultClient
+	}
+
+	if out.maxRetries == 0 {
+		out.maxRetries = DefaultMaxRetries
+	}
+
+	if out.maxRetries < 0 {
+		out.maxRetries = 0
+	}
+
+	if out.backoff <= 0 {
+		out.backoff = DefaultBackoff
+	}
+
+	return out
+}
+
+func (i *impl) Route(ctx context.Context, request RouteRequest) (*route.Response, error) {
+	requestBody, err := request.toBody()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("error while encoding route request: %s", err.Error())
+	}
+
+	var out route.Response
+	err = i.do(ctx, http.MethodPost, "/api/route", nil, body, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range out.Routes {
+		normalizeRoute(&out.Routes[index])
+	}
+
+	return &out, nil
+}
+
+func (i *impl) RoundTrip(ctx context.Context, start geojson.Point, request RoundTripRequest) ([]route.RoundTrip, error) {
+	startBytes, err := json.Marshal(start)
+	if err != nil {
+		return nil, fmt.Errorf("error while encoding start: %s", err.Error())
+	}
+
+	query := request.toQuery()
+	query.Set("r", base64.URLEncoding.EncodeToString(startBytes))
+
+	var out []route.RoundTrip
+	err = i.do(ctx, http.MethodGet, "/api/roundtrip", query, nil, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range out {
+		normalizeGeoJson(&out[index].GeoJson)
+	}
+
+	return out, nil
+}
+
+func (i *impl) Search(ctx context.Context, query string) ([]*address.Address, error) {
+	var out []*address.Address
+	err := i.do(ctx, http.MethodGet, "/api/search", url.Values{"q": {query}}, nil, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (i *impl) Locate(ctx context.Context, id int64) (geojson.Point, error) {
+	var out geojson.Point
+	err := i.do(ctx, http.MethodGet, "/api/locate", url.Values{"id": {strconv.FormatInt(id, 10)}}, nil, &out)
+	if err != nil {
+		return geojson.Point{}, err
+	}
+	return out, nil
+}
+
+// do sends the request and decodes the json response into out, it retries on 429 and 5xx responses
+func (i *impl) do(ctx context.Context, method string, path string, query url.Values, body []byte, out any) error {
+	target := i.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := i.send(ctx, method, target, body, out)
+
+		apiError, ok := err.(*Error)
+		if !ok || !apiError.Temporary() || attempt >= i.maxRetries {
+			return err
+		}
+
+		wait := i.backoff << attempt
+		if wait > maxBackoff || wait <= 0 {
+			wait = maxBackoff
+		}
+		if apiError.RetryAfter > wait {
+			wait = apiError.RetryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (i *impl) send(ctx context.Context, method string, target string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("error while creating request: %s", err.Error())
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if i.apiKey != "" {
+		request.Header.Set(apiKeyHeader, i.apiKey)
+	}
+
+	response, err := i.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("error while sending request: %s", err.Error())
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error while reading response: %s", err.Error())
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return newError(response, responseBody)
+	}
+
+	err = json.Unmarshal(responseBody, out)
+	if err != nil {
+		return fmt.Errorf("error while decoding response: %s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/client"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testRouteResponse = `{"version":1,"routes":[{"waypoints":[[11.5,48.1],[11.6,48.2]],"distance":1200,"time":90,
+"segments":[{"distance":1200,"time":90,"geojson":{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"LineString","coordinates":[[11.5,48.1],[11.6,48.2]]},"properties":{}}]}}]}]}`
+
+func TestRouteRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("X-Api-Key") != "key" {
+			t.Errorf("expected api key header")
+		}
+
+		if attempts < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testRouteResponse))
+	}))
+	defer server.Close()
+
+	c := client.New(client.Config{BaseURL: server.URL, ApiKey: "key", Backoff: time.Millisecond})
+
+	response, err := c.Route(context.Background(), client.RouteRequest{
+		Waypoints: []client.Waypoint{client.Point(geojson.NewPoint(11.5, 48.1)), client.Point(geojson.NewPoint(11.6, 48.2))},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	lines := response.Routes[0].Segments[0].Lines()
+	if len(lines) != 1 || len(lines[0]) != 2 || lines[0][1] != geojson.NewPoint(11.6, 48.2) {
+		t.Errorf("unexpected lines %v", lines)
+	}
+}
+
+func TestRouteError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"field":"waypoints","message":"at least two waypoints are required"}}`))
+	}))
+	defer server.Close()
+
+	c := client.New(client.Config{BaseURL: server.URL, Backoff: time.Millisecond})
+
+	_, err := c.Route(context.Background(), client.RouteRequest{})
+
+	var apiError *client.Error
+	if !errors.As(err, &apiError) {
+		t.Fatalf("expected an api error, got %v", err)
+	}
+
+	if apiError.StatusCode != http.StatusBadRequest || apiError.Field != "waypoints" || apiError.Message != "at least two waypoints are required" {
+		t.Errorf("unexpected error %+v", apiError)
+	}
+
+	if attempts != 1 {
+		t.Errorf("expected no retries of client errors, got %d attempts", attempts)
+	}
+}
+
+func TestRetryAfterContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := client.New(client.Config{BaseURL: server.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Search(ctx, "street")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context to end the retries, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is a response of the API with a status other than 2xx
+type Error struct {
+	StatusCode int
+	Field      string        // the invalid field of the request, if the API names it
+	Message    string        // the message of the error payload or the plain text body
+	RetryAfter time.Duration // the Retry-After header of 429 and 503 responses
+}
+
+func (e *Error) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("api error %d: %s: %s", e.StatusCode, e.Field, e.Message)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether the request may succeed, if it is sent again
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func newError(response *http.Response, body []byte) *Error {
+	out := &Error{
+		StatusCode: response.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+
+	var payload route.ErrorResponse
+	if json.Unmarshal(body, &payload) == nil && payload.Error.Message != "" {
+		out.Field = payload.Error.Field
+		out.Message = payload.Error.Message
+	}
+
+	if out.Message == "" {
+		out.Message = http.StatusText(response.StatusCode)
+	}
+
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		out.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return out
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type RouteRequest struct {
+	Waypoints    []Waypoint
+	Profile      string
+	Dimensions   *route.Dimensions
+	Departure    time.Time        // only used by the transit profile
+	AvoidAreas   *geojson.GeoJson // polygons and multi polygons, the route must not cross
+	Alternatives int
+	Debug        bool
+}
+
+type Waypoint = route.Waypoint
+
+// Point is a waypoint without further constraints
+func Point(location geojson.Point) Waypoint {
+	return Waypoint{Location: &location}
+}
+
+func (r RouteRequest) toBody() (route.Request, error) {
+	out := route.Request{
+		Version:      route.RequestVersion,
+		Waypoints:    r.Waypoints,
+		Profile:      r.Profile,
+		Dimensions:   r.Dimensions,
+		Alternatives: r.Alternatives,
+		Debug:        r.Debug,
+	}
+
+	if !r.Departure.IsZero() {
+		out.Departure = r.Departure.Format(time.RFC3339)
+	}
+
+	if r.AvoidAreas != nil {
+		areas, err := json.Marshal(r.AvoidAreas)
+		if err != nil {
+			return route.Request{}, fmt.Errorf("error while encoding avoid areas: %s", err.Error())
+		}
+		out.Avoid = &route.AvoidOptions{Areas: areas}
+	}
+
+	return out, nil
+}
+
+// RoundTripRequest are the parameters of GET /api/roundtrip, either Distance or Duration has to be set
+type RoundTripRequest struct {
+	Profile    string
+	Dimensions *route.Dimensions
+	Distance   float64 // meters
+	Duration   float64 // seconds
+	Seed       int64
+	Candidates int
+}
+
+func (r RoundTripRequest) toQuery() url.Values {
+	out := url.Values{}
+
+	if r.Profile != "" {
+		out.Set("profile", r.Profile)
+	}
+
+	numbers := []struct {
+		name  string
+		value float64
+	}{
+		{"distance", r.Distance},
+		{"duration", r.Duration},
+	}
+
+	if r.Dimensions != nil {
+		numbers = append(numbers, []struct {
+			name  string
+			value float64
+		}{
+			{"height", r.Dimensions.Height},
+			{"width", r.Dimensions.Width},
+			{"length", r.Dimensions.Length},
+			{"weight", r.Dimensions.Weight},
+			{"axleload", r.Dimensions.AxleLoad},
+		}...)
+	}
+
+	for _, number := range numbers {
+		if number.value != 0 {
+			out.Set(number.name, strconv.FormatFloat(number.value, 'f', -1, 64))
+		}
+	}
+
+	if r.Seed != 0 {
+		out.Set("seed", strconv.FormatInt(r.Seed, 10))
+	}
+
+	if r.Candidates != 0 {
+		out.Set("candidates", strconv.Itoa(r.Candidates))
+	}
+
+	return out
+}
+
+// normalizeRoute converts the decoded coordinates of all geometries into points, so Lines() works like on the server
+func normalizeRoute(in *route.Route) {
+	for index := range in.Segments {
+		normalizeGeoJson(&in.Segments[index].GeoJson)
+	}
+
+	if in.Debug != nil {
+		normalizeGeoJson(&in.Debug.SearchSpace)
+	}
+}
+
+// normalizeGeoJson replaces coordinate pairs, that encoding/json decodes as []interface{}, with geojson.Point
+func normalizeGeoJson(in *geojson.GeoJson) {
+	for featureIndex := range in.Features {
+		coordinates := in.Features[featureIndex].Geometry.Coordinates
+		for index, coordinate := range coordinates {
+			if point, ok := toPoint(coordinate); ok {
+				coordinates[index] = point
+			}
+		}
+	}
+}
+
+func toPoint(value any) (geojson.Point, bool) {
+	pair, ok := value.([]interface{})
+	if !ok || len(pair) != 2 {
+		return geojson.Point{}, false
+	}
+
+	lon, lonOk := pair[0].(float64)
+	lat, latOk := pair[1].(float64)
+	if !lonOk || !latOk {
+		return geojson.Point{}, false
+	}
+
+	return geojson.NewPoint(lon, lat), true
+}
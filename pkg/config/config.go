@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
 	"os"
+	"time"
 )
 
 type Config struct {
-	LoggerConfig   *LoggerConfig   `json:"logging"`
-	DatabaseConfig *DatabaseConfig `json:"database"`
-	ServerConfig   *ServerConfig   `json:"server"`
+	LoggerConfig   *LoggerConfig             `json:"logging"`
+	DatabaseConfig *DatabaseConfig           `json:"database"`
+	ServerConfig   *ServerConfig             `json:"server"`
+	Profiles       map[string]*ProfileConfig `json:"profiles"`
 }
 
 type LoggerConfig struct {
@@ -24,8 +26,75 @@ type DatabaseConfig struct {
 }
 
 type ServerConfig struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	AdminToken string `json:"adminToken"` // the admin api is disabled, if empty
+	GrpcPort   int    `json:"grpcPort"`   // the grpc api is disabled, if 0
+
+	ShutdownTimeout float64 `json:"shutdownTimeout"` // seconds to drain in-flight requests, defaults to DefaultShutdownTimeout
+
+	CorsOrigins       []string         `json:"corsOrigins"`       // allowed origins, every origin is allowed, if empty
+	ApiKeys           []string         `json:"apiKeys"`           // requests have to send one of the keys, if not empty
+	TrustForwardedFor bool             `json:"trustForwardedFor"` // use X-Forwarded-For as client ip, only behind a proxy
+	RateLimits        *RateLimitConfig `json:"rateLimits"`
+}
+
+// RateLimitConfig configures token buckets per api key and per client ip, limits that are not set are disabled
+type RateLimitConfig struct {
+	PerKey *RateLimitClassConfig `json:"perKey"`
+	PerIP  *RateLimitClassConfig `json:"perIp"`
+}
+
+// RateLimitClassConfig has separate budgets for cheap endpoints (search, locate, nearest, tiles)
+// and expensive endpoints (route, round trip, table, match)
+type RateLimitClassConfig struct {
+	Cheap     *TokenBucketConfig `json:"cheap"`
+	Expensive *TokenBucketConfig `json:"expensive"`
+}
+
+type TokenBucketConfig struct {
+	Rate  float64 `json:"rate"`  // tokens per second
+	Burst float64 `json:"burst"` // size of the bucket
+}
+
+// DefaultShutdownTimeout is the time in seconds, the server waits for in-flight requests on shutdown
+const DefaultShutdownTimeout = 30
+
+// GetShutdownTimeout returns the configured shutdown timeout or the default
+func (sc *ServerConfig) GetShutdownTimeout() time.Duration {
+	if sc.ShutdownTimeout <= 0 {
+		return DefaultShutdownTimeout * time.Second
+	}
+	return time.Duration(sc.ShutdownTimeout * float64(time.Second))
+}
+
+type ProfileConfig struct {
+	Vehicle       string             `json:"vehicle"`
+	MaxSpeed      float64            `json:"maxSpeed"`
+	HighwaySpeeds map[string]float64 `json:"highwaySpeeds"`
+	Priorities    []PriorityConfig   `json:"priorities"`
+	Access        *AccessConfig      `json:"access"`
+	TurnPenalties *TurnPenaltyConfig `json:"turnPenalties"`
+}
+
+type PriorityConfig struct {
+	Tag    string   `json:"tag"`
+	Values []string `json:"values"`
+	Factor float64  `json:"factor"`
+}
+
+type AccessConfig struct {
+	RoadTypes       []string `json:"roadTypes"`
+	AllowedHighways []string `json:"allowedHighways"`
+	DeniedHighways  []string `json:"deniedHighways"`
+	AccessTags      []string `json:"accessTags"`
+	OnewayTags      []string `json:"onewayTags"`
+}
+
+type TurnPenaltyConfig struct {
+	Left  float64 `json:"left"`
+	Right float64 `json:"right"`
+	UTurn float64 `json:"uTurn"`
 }
 
 func FromFile(path string) (*Config, error) {
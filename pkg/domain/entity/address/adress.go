@@ -1,7 +1,16 @@
 package address
 
+// OsmType is the type of the osm element of an address, nodes and ways have separate ids
+type OsmType string
+
+const (
+	Node OsmType = "node"
+	Way  OsmType = "way"
+)
+
 type Address struct {
-	OsmID int64
+	OsmID   int64
+	OsmType OsmType
 
 	Housenumber string
 	Street      string
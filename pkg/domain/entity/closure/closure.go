@@ -0,0 +1,36 @@
+package closure
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"time"
+)
+
+// Closure closes roads or reduces the speed on them while the router is running.
+// It applies to a whole way, to the segment between two neighbouring nodes or to all segments intersecting a polygon.
+type Closure struct {
+	ID          int64           `json:"id"`
+	WayID       int64           `json:"wayId,omitempty"`
+	Nodes       []int64         `json:"nodes,omitempty"`
+	Polygon     geojson.Polygon `json:"polygon,omitempty"`
+	SpeedFactor *float64        `json:"speedFactor"` // required, 0 closes the road, otherwise the travel time is divided by it
+	ValidFrom   *time.Time      `json:"validFrom,omitempty"`
+	ValidUntil  *time.Time      `json:"validUntil,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+}
+
+func (c *Closure) IsClosed() bool {
+	return c.SpeedFactor != nil && *c.SpeedFactor == 0
+}
+
+// IsActive checks if t is within the validity times, missing times are unbounded
+func (c *Closure) IsActive(t time.Time) bool {
+	if c.ValidFrom != nil && t.Before(*c.ValidFrom) {
+		return false
+	}
+
+	if c.ValidUntil != nil && !t.Before(*c.ValidUntil) {
+		return false
+	}
+
+	return true
+}
@@ -1,8 +1,26 @@
 package node
 
+import "math"
+
 type Node struct {
-	OsmID int64
-	Lat   float64
-	Lon   float64
-	Tags  map[string]string
+	OsmID  int64
+	Lat    float64
+	Lon    float64
+	Ele    float64 // only valid if HasEle
+	HasEle bool
+	Tags   map[string]string
+}
+
+// SetEle sets the elevation in meters, NaN marks the elevation as unknown
+func (n *Node) SetEle(ele float64) {
+	n.Ele = ele
+	n.HasEle = !math.IsNaN(ele)
+}
+
+// Elevation returns the elevation in meters or NaN if it is unknown
+func (n Node) Elevation() float64 {
+	if !n.HasEle {
+		return math.NaN()
+	}
+	return n.Ele
 }
@@ -0,0 +1,58 @@
+package route
+
+import (
+	"encoding/json"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+)
+
+// RequestVersion is the schema version of POST /api/route
+const RequestVersion = 1
+
+// Request is the json body of POST /api/route
+type Request struct {
+	Version      int           `json:"version"`
+	Waypoints    []Waypoint    `json:"waypoints"`
+	Profile      string        `json:"profile,omitempty"`
+	Dimensions   *Dimensions   `json:"dimensions,omitempty"`
+	Departure    string        `json:"departure,omitempty"` // RFC 3339, only used by the transit profile
+	Avoid        *AvoidOptions `json:"avoid,omitempty"`
+	Alternatives int           `json:"alternatives,omitempty"`
+	Format       string        `json:"format,omitempty"`
+	Debug        bool          `json:"debug,omitempty"`
+}
+
+type Waypoint struct {
+	Location         *geojson.Point `json:"location,omitempty"`
+	Heading          *float64       `json:"heading,omitempty"` // degrees clockwise from north
+	HeadingTolerance float64        `json:"headingTolerance,omitempty"`
+	Radius           float64        `json:"radius,omitempty"` // meters
+	NodeID           int64          `json:"nodeId,omitempty"` // replaces Location, if set
+}
+
+type Dimensions struct {
+	Height   float64 `json:"height,omitempty"`
+	Width    float64 `json:"width,omitempty"`
+	Length   float64 `json:"length,omitempty"`
+	Weight   float64 `json:"weight,omitempty"`
+	AxleLoad float64 `json:"axleload,omitempty"`
+}
+
+type AvoidOptions struct {
+	Areas json.RawMessage `json:"areas"` // any geojson with polygons
+}
+
+// Response is the json body of a successful POST /api/route
+type Response struct {
+	Version int     `json:"version"`
+	Routes  []Route `json:"routes"`
+}
+
+// ErrorResponse is the json body of a failed POST /api/route
+type ErrorResponse struct {
+	Error ErrorMessage `json:"error"`
+}
+
+type ErrorMessage struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
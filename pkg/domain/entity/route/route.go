@@ -0,0 +1,80 @@
+package route
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"time"
+)
+
+// Route is a route through all waypoints of a request
+type Route struct {
+	Waypoints      []geojson.Point `json:"waypoints"`
+	LengthInMeters float64         `json:"distance"`
+	LengthInTime   int64           `json:"time"`
+	Segments       []SegmentInfo   `json:"segments"`
+	Debug          *Debug          `json:"debug,omitempty"`
+}
+
+// SegmentInfo is the part of a route between two neighbouring waypoints
+type SegmentInfo struct {
+	LengthInMeters   float64         `json:"distance"`
+	LengthInTime     int64           `json:"time"`
+	Ascent           float64         `json:"ascent"`
+	Descent          float64         `json:"descent"`
+	ElevationProfile [][2]float64    `json:"elevationProfile"`
+	GeoJson          geojson.GeoJson `json:"geojson"`
+	Legs             []Leg           `json:"legs,omitempty"`
+}
+
+// Lines returns the coordinates of all line features of the segment
+func (s SegmentInfo) Lines() [][]geojson.Point {
+	var out [][]geojson.Point
+	for _, feature := range s.GeoJson.Features {
+		var line []geojson.Point
+		for _, coordinate := range feature.Geometry.Coordinates {
+			if point, ok := coordinate.(geojson.Point); ok {
+				line = append(line, point)
+			}
+		}
+
+		if len(line) > 0 {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// Leg is a part of a route that is travelled with a single mode
+type Leg struct {
+	Mode           string    `json:"mode"`
+	Route          string    `json:"route,omitempty"`
+	Headsign       string    `json:"headsign,omitempty"`
+	From           string    `json:"from,omitempty"`
+	To             string    `json:"to,omitempty"`
+	Departure      time.Time `json:"departure"`
+	Arrival        time.Time `json:"arrival"`
+	LengthInMeters float64   `json:"distance"`
+}
+
+// Debug describes how a route was found, it is only set for requests with debug.
+// Times are in milliseconds.
+type Debug struct {
+	Iterations   int     `json:"iterations"`
+	Queries      int64   `json:"queries"`
+	SnappingTime float64 `json:"snappingTime"`
+	SearchTime   float64 `json:"searchTime"`
+	PathTime     float64 `json:"pathTime"`
+
+	// SearchSpace contains a point for every settled node and a line for every relaxed edge
+	SearchSpace geojson.GeoJson `json:"searchSpace"`
+	Truncated   bool            `json:"truncated"`
+}
+
+// RoundTrip is a loop starting and ending at the same point
+type RoundTrip struct {
+	LengthInMeters float64         `json:"distance"`
+	LengthInTime   int64           `json:"time"`
+	Ascent         float64         `json:"ascent"`
+	Descent        float64         `json:"descent"`
+	Waypoints      []geojson.Point `json:"waypoints"`
+	GeoJson        geojson.GeoJson `json:"geojson"`
+}
@@ -0,0 +1,64 @@
+package transit
+
+import "time"
+
+// Feed is an imported timetable, ids are prefixed with the feed name to keep multiple feeds apart
+type Feed struct {
+	Name        string
+	Timezone    string
+	Stops       []Stop
+	Trips       []Trip
+	Connections []Connection
+	Services    []Service
+}
+
+type Stop struct {
+	ID     string
+	Name   string
+	Lat    float64
+	Lon    float64
+	Parent string
+	NodeID int64 // nearest pedestrian node, 0 if none was found
+}
+
+type Trip struct {
+	ID        string
+	ServiceID string
+	RouteName string
+	Mode      string
+	Headsign  string
+}
+
+// Connection is a vehicle driving from one stop to the next without stopping in between,
+// times are seconds since midnight of the service day
+type Connection struct {
+	TripID        string
+	DepartureStop string
+	ArrivalStop   string
+	DepartureTime int
+	ArrivalTime   int
+}
+
+type Service struct {
+	ID        string
+	Weekdays  [7]bool // indexed by time.Weekday
+	StartDate string  // YYYYMMDD, empty if only defined by exceptions
+	EndDate   string  // YYYYMMDD
+	Added     map[string]bool
+	Removed   map[string]bool
+}
+
+// Journey is the scheduled part of a trip, from the first boarding to the last alighting
+type Journey struct {
+	AccessStop *Stop
+	EgressStop *Stop
+	Legs       []Leg
+}
+
+// Leg is a ride with a single trip or, if Trip is nil, a transfer between stops of a station
+type Leg struct {
+	Trip      *Trip
+	Stops     []*Stop
+	Departure time.Time
+	Arrival   time.Time
+}
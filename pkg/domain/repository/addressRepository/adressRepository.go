@@ -2,9 +2,11 @@ package addressRepository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+	"time"
 )
 
 type AddressRepository interface {
@@ -13,8 +15,17 @@ type AddressRepository interface {
 	InsertAddress(address address.Address) error
 	InsertAddresses(addresses []address.Address) error
 
+	// DeleteAddress deletes the address of the osm element with the type and id
+	DeleteAddress(osmType address.OsmType, osmID int64) error
+
+	// SelectLastRowID and DeleteAfterRowID reset the addresses to a checkpoint of an import
+	SelectLastRowID() (int64, error)
+	DeleteAfterRowID(rowID int64) error
+
 	GetAddressesFromSearchQuery(address string) ([]*address.Address, error)
 	SelectAddressByID(id int64) (*address.Address, error)
+
+	Prepared() bool
 }
 
 type impl struct {
@@ -23,9 +34,15 @@ type impl struct {
 }
 
 type preparedStatements struct {
-	insertAddress     *sql.Stmt
-	selectAddresses   *sql.Stmt
-	selectAddressByID *sql.Stmt
+	insertAddress            *sql.Stmt
+	insertAddressElement     *sql.Stmt
+	deleteAddress            *sql.Stmt
+	deleteAddressElement     *sql.Stmt
+	selectLastRowID          *sql.Stmt
+	deleteAfterRowID         *sql.Stmt
+	deleteElementsAfterRowID *sql.Stmt
+	selectAddresses          *sql.Stmt
+	selectAddressByID        *sql.Stmt
 }
 
 func New(db database.Database) AddressRepository {
@@ -54,6 +71,36 @@ func (i *impl) prepareStatements() error {
 		return fmt.Errorf("error while preparing insert statement: %s", err.Error())
 	}
 
+	insertAddressElement, err := i.db.Prepare(insertAddressElement)
+	if err != nil {
+		return fmt.Errorf("error while preparing insert element statement: %s", err.Error())
+	}
+
+	deleteAddress, err := i.db.Prepare(deleteAddress)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete statement: %s", err.Error())
+	}
+
+	deleteAddressElement, err := i.db.Prepare(deleteAddressElement)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete element statement: %s", err.Error())
+	}
+
+	selectLastRowID, err := i.db.Prepare(selectLastRowID)
+	if err != nil {
+		return fmt.Errorf("error while preparing select last rowid statement: %s", err.Error())
+	}
+
+	deleteAfterRowID, err := i.db.Prepare(deleteAfterRowID)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete after rowid statement: %s", err.Error())
+	}
+
+	deleteElementsAfterRowID, err := i.db.Prepare(deleteElementsAfterRowID)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete elements after rowid statement: %s", err.Error())
+	}
+
 	selectAddresses, err := i.db.Prepare(selectAddresses)
 	if err != nil {
 		return fmt.Errorf("error while preparing select statement: %s", err.Error())
@@ -65,18 +112,29 @@ func (i *impl) prepareStatements() error {
 	}
 
 	i.preparedStatements.insertAddress = insertAddress
+	i.preparedStatements.insertAddressElement = insertAddressElement
+	i.preparedStatements.deleteAddress = deleteAddress
+	i.preparedStatements.deleteAddressElement = deleteAddressElement
+	i.preparedStatements.selectLastRowID = selectLastRowID
+	i.preparedStatements.deleteAfterRowID = deleteAfterRowID
+	i.preparedStatements.deleteElementsAfterRowID = deleteElementsAfterRowID
 	i.preparedStatements.selectAddresses = selectAddresses
 	i.preparedStatements.selectAddressByID = selectAddressByID
 
 	return nil
 }
 
+// Prepared reports whether Init() has prepared the statements
+func (i *impl) Prepared() bool {
+	return i.preparedStatements.selectAddressByID != nil
+}
+
 func (i *impl) InsertAddress(address address.Address) error {
 	if i.preparedStatements.insertAddress == nil {
 		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertAddress()")
 	}
 
-	_, err := i.preparedStatements.insertAddress.Exec(
+	result, err := i.preparedStatements.insertAddress.Exec(
 		address.OsmID,
 		address.Housenumber, address.Street, address.City, address.Postcode, address.Country,
 		address.Suburb, address.State, address.Province, address.Floor,
@@ -86,6 +144,69 @@ func (i *impl) InsertAddress(address address.Address) error {
 		return fmt.Errorf("error while inserting address: %s", err.Error())
 	}
 
+	rowID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error while reading address rowid: %s", err.Error())
+	}
+
+	_, err = i.preparedStatements.insertAddressElement.Exec(rowID, address.OsmType, address.OsmID)
+	if err != nil {
+		return fmt.Errorf("error while inserting address element: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) DeleteAddress(osmType address.OsmType, osmID int64) error {
+	if i.preparedStatements.deleteAddress == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call DeleteAddress()")
+	}
+
+	_, err := i.preparedStatements.deleteAddress.Exec(osmType, osmID)
+	if err != nil {
+		return fmt.Errorf("error while deleting address: %s", err.Error())
+	}
+
+	_, err = i.preparedStatements.deleteAddressElement.Exec(osmType, osmID)
+	if err != nil {
+		return fmt.Errorf("error while deleting address element: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) SelectLastRowID() (int64, error) {
+	if i.preparedStatements.selectLastRowID == nil {
+		return 0, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectLastRowID()")
+	}
+
+	var rowID int64
+	err := i.preparedStatements.selectLastRowID.QueryRow().Scan(&rowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error while selecting last rowid: %s", err.Error())
+	}
+
+	return rowID, nil
+}
+
+func (i *impl) DeleteAfterRowID(rowID int64) error {
+	if i.preparedStatements.deleteAfterRowID == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call DeleteAfterRowID()")
+	}
+
+	_, err := i.preparedStatements.deleteAfterRowID.Exec(rowID)
+	if err != nil {
+		return fmt.Errorf("error while deleting addresses: %s", err.Error())
+	}
+
+	_, err = i.preparedStatements.deleteElementsAfterRowID.Exec(rowID)
+	if err != nil {
+		return fmt.Errorf("error while deleting address elements: %s", err.Error())
+	}
+
 	return nil
 }
 
@@ -100,9 +221,10 @@ func (i *impl) InsertAddresses(addresses []address.Address) error {
 	}
 
 	insertAddress := tx.Stmt(i.preparedStatements.insertAddress)
+	insertAddressElement := tx.Stmt(i.preparedStatements.insertAddressElement)
 
 	for _, address := range addresses {
-		_, err = insertAddress.Exec(
+		result, err := insertAddress.Exec(
 			address.OsmID,
 			address.Housenumber, address.Street, address.City, address.Postcode, address.Country,
 			address.Suburb, address.State, address.Province, address.Floor,
@@ -111,6 +233,16 @@ func (i *impl) InsertAddresses(addresses []address.Address) error {
 		if err != nil {
 			return fmt.Errorf("error while inserting address: %s", err.Error())
 		}
+
+		rowID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("error while reading address rowid: %s", err.Error())
+		}
+
+		_, err = insertAddressElement.Exec(rowID, address.OsmType, address.OsmID)
+		if err != nil {
+			return fmt.Errorf("error while inserting address element: %s", err.Error())
+		}
 	}
 
 	err = tx.Commit()
@@ -122,6 +254,8 @@ func (i *impl) InsertAddresses(addresses []address.Address) error {
 }
 
 func (i *impl) GetAddressesFromSearchQuery(query string) ([]*address.Address, error) {
+	defer database.ObserveQuery("address", "GetAddressesFromSearchQuery", time.Now())
+
 	if i.preparedStatements.selectAddresses == nil {
 		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call GetAddressesFromSearchQuery()")
 	}
@@ -137,7 +271,7 @@ func (i *impl) GetAddressesFromSearchQuery(query string) ([]*address.Address, er
 	for rows.Next() {
 		var address address.Address
 		err := rows.Scan(
-			&address.OsmID,
+			&address.OsmID, &address.OsmType,
 			&address.Housenumber, &address.Street, &address.City, &address.Postcode, &address.Country,
 			&address.Suburb, &address.State, &address.Province, &address.Floor,
 			&address.Name,
@@ -154,6 +288,8 @@ func (i *impl) GetAddressesFromSearchQuery(query string) ([]*address.Address, er
 }
 
 func (i *impl) SelectAddressByID(id int64) (*address.Address, error) {
+	defer database.ObserveQuery("address", "SelectAddressByID", time.Now())
+
 	if i.preparedStatements.selectAddressByID == nil {
 		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectAddressByID()")
 	}
@@ -168,7 +304,7 @@ func (i *impl) SelectAddressByID(id int64) (*address.Address, error) {
 	var address address.Address
 	for rows.Next() {
 		err := rows.Scan(
-			&address.OsmID,
+			&address.OsmID, &address.OsmType,
 			&address.Housenumber, &address.Street, &address.City, &address.Postcode, &address.Country,
 			&address.Suburb, &address.State, &address.Province, &address.Floor,
 			&address.Name,
@@ -18,6 +18,16 @@ CREATE VIRTUAL TABLE IF NOT EXISTS address USING fts5(
 	
 	Name, --text
 );
+
+-- address_element finds the address of an osm element without a full text scan,
+-- nodes and ways are kept apart by their type, as their ids overlap
+CREATE TABLE IF NOT EXISTS address_element (
+    address_rowid INTEGER PRIMARY KEY,
+    osm_type TEXT NOT NULL,
+    osm_id INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS address_element_osm ON address_element (osm_type, osm_id);
 `
 
 	insertAddress = `
@@ -27,25 +37,51 @@ INSERT INTO address (
 	Suburb, State, Province, Floor, 
 	Name
 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`
+
+	insertAddressElement = `
+INSERT INTO address_element (address_rowid, osm_type, osm_id) VALUES (?, ?, ?);
+`
+
+	deleteAddress = `
+DELETE FROM address WHERE rowid IN (SELECT address_rowid FROM address_element WHERE osm_type = ? AND osm_id = ?);
+`
+
+	deleteAddressElement = `
+DELETE FROM address_element WHERE osm_type = ? AND osm_id = ?;
+`
+
+	selectLastRowID = `
+SELECT rowid FROM address ORDER BY rowid DESC LIMIT 1;
+`
+
+	deleteAfterRowID = `
+DELETE FROM address WHERE rowid > ?;
+`
+
+	deleteElementsAfterRowID = `
+DELETE FROM address_element WHERE address_rowid > ?;
 `
 
 	selectAddresses = `
 SELECT
-    OsmID,
+    address.OsmID, COALESCE(address_element.osm_type, ''),
 	Housenumber, Street, City, Postcode, Country,
 	Suburb, State, Province, Floor,
 	Name
 FROM address(?)
+LEFT JOIN address_element ON address_element.address_rowid = address.rowid
 LIMIT 5;
 `
 
 	selectAddressByID = `
 SELECT
-	OsmID,
+	address.OsmID, COALESCE(address_element.osm_type, ''),
 	Housenumber, Street, City, Postcode, Country,
 	Suburb, State, Province, Floor,
 	Name
 FROM address
-WHERE OsmID = ?;
+LEFT JOIN address_element ON address_element.address_rowid = address.rowid
+WHERE address.OsmID = ?;
 `
 )
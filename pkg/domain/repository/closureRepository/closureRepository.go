@@ -0,0 +1,195 @@
+package closureRepository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/closure"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+	"time"
+)
+
+type ClosureRepository interface {
+	Init() error
+
+	InsertClosure(closure closure.Closure) (int64, error)
+	SelectClosures() ([]*closure.Closure, error)
+	DeleteClosure(id int64) (bool, error)
+
+	Prepared() bool
+}
+
+type impl struct {
+	db                 database.Database
+	preparedStatements preparedStatements
+}
+
+type preparedStatements struct {
+	insertClosure  *sql.Stmt
+	selectClosures *sql.Stmt
+	deleteClosure  *sql.Stmt
+}
+
+func New(db database.Database) ClosureRepository {
+	return &impl{
+		db: db,
+	}
+}
+
+func (i *impl) Init() error {
+	_, err := i.db.Exec(dataModel)
+	if err != nil {
+		return fmt.Errorf("error while running data model: %s", err.Error())
+	}
+
+	err = i.prepareStatements()
+	if err != nil {
+		return fmt.Errorf("error while preparing statements: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) prepareStatements() error {
+	statements := []struct {
+		name  string
+		query string
+		stmt  **sql.Stmt
+	}{
+		{"insertClosure", insertClosure, &i.preparedStatements.insertClosure},
+		{"selectClosures", selectClosures, &i.preparedStatements.selectClosures},
+		{"deleteClosure", deleteClosure, &i.preparedStatements.deleteClosure},
+	}
+
+	for _, statement := range statements {
+		stmt, err := i.db.Prepare(statement.query)
+		if err != nil {
+			return fmt.Errorf("error while preparing %s statement: %s", statement.name, err.Error())
+		}
+		*statement.stmt = stmt
+	}
+
+	return nil
+}
+
+// Prepared reports whether Init() has prepared the statements
+func (i *impl) Prepared() bool {
+	return i.preparedStatements.deleteClosure != nil
+}
+
+func (i *impl) InsertClosure(c closure.Closure) (int64, error) {
+	defer database.ObserveQuery("closure", "InsertClosure", time.Now())
+
+	if i.preparedStatements.insertClosure == nil {
+		return 0, fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertClosure()")
+	}
+
+	var nodeA, nodeB int64
+	if len(c.Nodes) == 2 {
+		nodeA, nodeB = c.Nodes[0], c.Nodes[1]
+	}
+
+	polygon := ""
+	if len(c.Polygon) > 0 {
+		polygonBytes, err := json.Marshal(c.Polygon)
+		if err != nil {
+			return 0, fmt.Errorf("error while marshalling polygon: %s", err.Error())
+		}
+		polygon = string(polygonBytes)
+	}
+
+	result, err := i.preparedStatements.insertClosure.Exec(c.WayID, nodeA, nodeB, polygon, *c.SpeedFactor, toUnix(c.ValidFrom), toUnix(c.ValidUntil), c.Reason)
+	if err != nil {
+		return 0, fmt.Errorf("error while inserting closure: %s", err.Error())
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error while reading closure id: %s", err.Error())
+	}
+
+	return id, nil
+}
+
+func (i *impl) SelectClosures() ([]*closure.Closure, error) {
+	defer database.ObserveQuery("closure", "SelectClosures", time.Now())
+
+	if i.preparedStatements.selectClosures == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectClosures()")
+	}
+
+	rows, err := i.preparedStatements.selectClosures.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting closures: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var out []*closure.Closure
+	for rows.Next() {
+		var c closure.Closure
+		var nodeA, nodeB int64
+		var polygon string
+		var speedFactor float64
+		var validFrom, validUntil sql.NullInt64
+
+		err = rows.Scan(&c.ID, &c.WayID, &nodeA, &nodeB, &polygon, &speedFactor, &validFrom, &validUntil, &c.Reason)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning closure: %s", err.Error())
+		}
+		c.SpeedFactor = &speedFactor
+
+		if nodeA != 0 || nodeB != 0 {
+			c.Nodes = []int64{nodeA, nodeB}
+		}
+
+		if polygon != "" {
+			err = json.Unmarshal([]byte(polygon), &c.Polygon)
+			if err != nil {
+				return nil, fmt.Errorf("error while unmarshalling polygon of closure %d: %s", c.ID, err.Error())
+			}
+		}
+
+		c.ValidFrom = fromUnix(validFrom)
+		c.ValidUntil = fromUnix(validUntil)
+
+		out = append(out, &c)
+	}
+
+	return out, nil
+}
+
+// DeleteClosure returns false, if no closure with this id exists
+func (i *impl) DeleteClosure(id int64) (bool, error) {
+	defer database.ObserveQuery("closure", "DeleteClosure", time.Now())
+
+	if i.preparedStatements.deleteClosure == nil {
+		return false, fmt.Errorf("statements not prepared: you need to call Init() before you can call DeleteClosure()")
+	}
+
+	result, err := i.preparedStatements.deleteClosure.Exec(id)
+	if err != nil {
+		return false, fmt.Errorf("error while deleting closure: %s", err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error while reading deleted rows: %s", err.Error())
+	}
+
+	return affected > 0, nil
+}
+
+func toUnix(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.Unix(), Valid: true}
+}
+
+func fromUnix(value sql.NullInt64) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := time.Unix(value.Int64, 0).UTC()
+	return &t
+}
@@ -0,0 +1,29 @@
+package closureRepository
+
+const (
+	dataModel = `
+CREATE TABLE IF NOT EXISTS closure (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    way_id INTEGER NOT NULL,
+    node_a INTEGER NOT NULL,
+    node_b INTEGER NOT NULL,
+    polygon TEXT NOT NULL, -- json encoded rings, empty if unused
+    speed_factor REAL NOT NULL,
+    valid_from INTEGER, -- unix seconds
+    valid_until INTEGER,
+    reason TEXT NOT NULL
+) STRICT;
+`
+
+	insertClosure = `
+INSERT INTO closure (way_id, node_a, node_b, polygon, speed_factor, valid_from, valid_until, reason) VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+`
+
+	selectClosures = `
+SELECT id, way_id, node_a, node_b, polygon, speed_factor, valid_from, valid_until, reason FROM closure ORDER BY id ASC;
+`
+
+	deleteClosure = `
+DELETE FROM closure WHERE id = ?;
+`
+)
@@ -5,9 +5,16 @@ const (
 `
 
 	selectCrossingsFromWayID = `
-SELECT osm_id, lat, lon, tags, is_crossing FROM node 
+SELECT osm_id, lat, lon, ele, tags, is_crossing FROM node 
 	JOIN wayToNodeRelation AS relation ON node.osm_id = relation.node_id 
 	WHERE relation.way_id = ? 
 	ORDER BY relation.position ASC;
 `
+
+	selectWayIDsInBoundingBox = `
+SELECT DISTINCT relation.way_id FROM node
+	JOIN wayToNodeRelation AS relation ON node.osm_id = relation.node_id
+	WHERE node.lat BETWEEN ? AND ? AND node.lon BETWEEN ? AND ?
+	ORDER BY relation.way_id ASC;
+`
 )
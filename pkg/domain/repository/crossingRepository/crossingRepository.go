@@ -8,11 +8,15 @@ import (
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/crossing"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
 	"sync"
+	"time"
 )
 
 type CrossingRepository interface {
 	Init() error
 	SelectCrossingsFromWayID(wayID int64) ([]*crossing.Crossing, error)
+	SelectWayIDsInBoundingBox(minLat, minLon, maxLat, maxLon float64) ([]int64, error)
+
+	Prepared() bool
 }
 
 type impl struct {
@@ -23,7 +27,8 @@ type impl struct {
 }
 
 type preparedStatements struct {
-	selectCrossingsFromWayID *sql.Stmt
+	selectCrossingsFromWayID  *sql.Stmt
+	selectWayIDsInBoundingBox *sql.Stmt
 }
 
 func New(db database.Database) CrossingRepository {
@@ -53,10 +58,23 @@ func (i *impl) prepareStatements() error {
 	}
 	i.preparedStatements.selectCrossingsFromWayID = selectCrossingsFromWayID
 
+	selectWayIDsInBoundingBox, err := i.db.Prepare(selectWayIDsInBoundingBox)
+	if err != nil {
+		return fmt.Errorf("error while preparing statement: %s", err.Error())
+	}
+	i.preparedStatements.selectWayIDsInBoundingBox = selectWayIDsInBoundingBox
+
 	return nil
 }
 
+// Prepared reports whether Init() has prepared the statements
+func (i *impl) Prepared() bool {
+	return i.preparedStatements.selectWayIDsInBoundingBox != nil
+}
+
 func (i *impl) SelectCrossingsFromWayID(wayID int64) ([]*crossing.Crossing, error) {
+	defer database.ObserveQuery("crossing", "SelectCrossingsFromWayID", time.Now())
+
 	if i.preparedStatements.selectCrossingsFromWayID == nil {
 		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectCrossingsFromWay()")
 	}
@@ -80,11 +98,14 @@ func decodeCrossings(rows *sql.Rows) ([]*crossing.Crossing, error) {
 	for rows.Next() {
 		var crossing crossing.Crossing
 		var buf []byte
-		err := rows.Scan(&crossing.OsmID, &crossing.Lat, &crossing.Lon, &buf, &crossing.IsCrossing)
+		var ele sql.NullFloat64
+		err := rows.Scan(&crossing.OsmID, &crossing.Lat, &crossing.Lon, &ele, &buf, &crossing.IsCrossing)
 		if err != nil {
 			return nil, fmt.Errorf("error while scanning crossing id: %s", err.Error())
 		}
 
+		crossing.Ele, crossing.HasEle = ele.Float64, ele.Valid
+
 		buffer := bytes.NewBuffer(buf)
 		crossing.Tags, err = decodeTags(buffer)
 		if err != nil {
@@ -97,6 +118,34 @@ func decodeCrossings(rows *sql.Rows) ([]*crossing.Crossing, error) {
 	return crossings, nil
 }
 
+// SelectWayIDsInBoundingBox returns the ways with at least one node in the bounding box
+func (i *impl) SelectWayIDsInBoundingBox(minLat, minLon, maxLat, maxLon float64) ([]int64, error) {
+	defer database.ObserveQuery("crossing", "SelectWayIDsInBoundingBox", time.Now())
+
+	if i.preparedStatements.selectWayIDsInBoundingBox == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectWayIDsInBoundingBox()")
+	}
+
+	rows, err := i.preparedStatements.selectWayIDsInBoundingBox.Query(minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting ways in bounding box: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var out []int64
+	for rows.Next() {
+		var wayID int64
+		err = rows.Scan(&wayID)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning way id: %s", err.Error())
+		}
+
+		out = append(out, wayID)
+	}
+
+	return out, nil
+}
+
 func decodeTags(buffer *bytes.Buffer) (map[string]string, error) {
 	var tags map[string]string
 	err := json.NewDecoder(buffer).Decode(&tags)
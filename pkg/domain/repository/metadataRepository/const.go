@@ -0,0 +1,19 @@
+package metadataRepository
+
+const (
+	dataModel = `
+CREATE TABLE IF NOT EXISTS metadata (
+    key TEXT PRIMARY KEY NOT NULL,
+    value TEXT NOT NULL
+) STRICT;
+`
+
+	selectValue = `
+SELECT value FROM metadata WHERE key = ?;
+`
+
+	updateValue = `
+INSERT INTO metadata (key, value) VALUES (?, ?)
+	ON CONFLICT (key) DO UPDATE SET value = excluded.value;
+`
+)
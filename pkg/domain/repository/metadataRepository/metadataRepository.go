@@ -0,0 +1,105 @@
+package metadataRepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+)
+
+// MetadataRepository stores key value pairs describing the state of the database, e.g. the applied replication sequence
+type MetadataRepository interface {
+	Init() error
+
+	// SelectValue returns false, if the key is not set
+	SelectValue(key string) (string, bool, error)
+	UpdateValue(key string, value string) error
+
+	Prepared() bool
+}
+
+type impl struct {
+	db                 database.Database
+	preparedStatements preparedStatements
+}
+
+type preparedStatements struct {
+	selectValue *sql.Stmt
+	updateValue *sql.Stmt
+}
+
+func New(db database.Database) MetadataRepository {
+	return &impl{
+		db: db,
+	}
+}
+
+func (i *impl) Init() error {
+	_, err := i.db.Exec(dataModel)
+	if err != nil {
+		return fmt.Errorf("error while running data model: %s", err.Error())
+	}
+
+	err = i.prepareStatements()
+	if err != nil {
+		return fmt.Errorf("error while preparing statements: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) prepareStatements() error {
+	statements := []struct {
+		name  string
+		query string
+		stmt  **sql.Stmt
+	}{
+		{"selectValue", selectValue, &i.preparedStatements.selectValue},
+		{"updateValue", updateValue, &i.preparedStatements.updateValue},
+	}
+
+	for _, statement := range statements {
+		stmt, err := i.db.Prepare(statement.query)
+		if err != nil {
+			return fmt.Errorf("error while preparing %s statement: %s", statement.name, err.Error())
+		}
+		*statement.stmt = stmt
+	}
+
+	return nil
+}
+
+// Prepared reports whether Init() has prepared the statements
+func (i *impl) Prepared() bool {
+	return i.preparedStatements.updateValue != nil
+}
+
+func (i *impl) SelectValue(key string) (string, bool, error) {
+	if i.preparedStatements.selectValue == nil {
+		return "", false, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectValue()")
+	}
+
+	var value string
+	err := i.preparedStatements.selectValue.QueryRow(key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("error while selecting value: %s", err.Error())
+	}
+
+	return value, true, nil
+}
+
+func (i *impl) UpdateValue(key string, value string) error {
+	if i.preparedStatements.updateValue == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call UpdateValue()")
+	}
+
+	_, err := i.preparedStatements.updateValue.Exec(key, value)
+	if err != nil {
+		return fmt.Errorf("error while updating value: %s", err.Error())
+	}
+
+	return nil
+}
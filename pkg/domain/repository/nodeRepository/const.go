@@ -6,8 +6,17 @@ CREATE TABLE IF NOT EXISTS node (
     osm_id INTEGER PRIMARY KEY UNIQUE NOT NULL,
     lat REAL NOT NULL,
     lon REAL NOT NULL,
+    ele REAL, -- NULL if unknown
     tags BLOB -- JSON
 ) STRICT;
+`
+
+	selectHasElevationColumn = `
+SELECT COUNT(*) FROM pragma_table_info('node') WHERE name = 'ele';
+`
+
+	addElevationColumn = `
+ALTER TABLE node ADD COLUMN ele REAL;
 `
 	createIndices = `
 CREATE INDEX IF NOT EXISTS node_osm_id_idx ON node (osm_id);
@@ -16,12 +25,22 @@ CREATE INDEX IF NOT EXISTS idx_lon ON node(lon);
 `
 
 	insertNode = `
-INSERT INTO node (osm_id, lat, lon, tags) VALUES (?, ?, ?, ?)
-	ON CONFLICT (osm_id) DO UPDATE SET lat = excluded.lat, lon = excluded.lon, tags = excluded.tags;
+INSERT INTO node (osm_id, lat, lon, ele, tags) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (osm_id) DO UPDATE SET lat = excluded.lat, lon = excluded.lon, ele = excluded.ele, tags = excluded.tags;
+`
+
+	// insertNodes inserts insertNodesRows nodes at once, the values are filled in by Init()
+	insertNodes = `
+INSERT INTO node (osm_id, lat, lon, ele, tags) VALUES %s
+	ON CONFLICT (osm_id) DO UPDATE SET lat = excluded.lat, lon = excluded.lon, ele = excluded.ele, tags = excluded.tags;
+`
+
+	deleteNode = `
+DELETE FROM node WHERE osm_id = ?;
 `
 
 	selectNodeFromID = `
-SELECT osm_id, lat, lon, tags FROM node WHERE osm_id = ?;
+SELECT osm_id, lat, lon, ele, tags FROM node WHERE osm_id = ?;
 `
 
 	selectNodeIDsFromWayID = `
@@ -31,7 +50,7 @@ SELECT node_id FROM wayToNodeRelation
 `
 
 	selectNodesFromWayID = `
-SELECT osm_id, lat, lon, tags FROM node 
+SELECT osm_id, lat, lon, ele, tags FROM node 
 	JOIN wayToNodeRelation AS relation ON node.osm_id = relation.node_id 
 	WHERE relation.way_id = ? 
 	ORDER BY relation.position ASC;
@@ -41,8 +60,13 @@ SELECT osm_id, lat, lon, tags FROM node
 SELECT AVG(lat), AVG(lon) FROM wayToNodeRelation JOIN node ON wayToNodeRelation.node_id = node.osm_id WHERE way_id = ?;
 `
 
+	selectSampleNode = `
+SELECT osm_id, lat, lon, ele, tags FROM node
+	WHERE osm_id = (SELECT node_id FROM wayToNodeRelation LIMIT 1);
+`
+
 	selectNearNodes = `
-SELECT node.osm_id, node.lat, node.lon, node.tags FROM node
+SELECT node.osm_id, node.lat, node.lon, node.ele, node.tags FROM node
   	WHERE node.lat BETWEEN ? AND ? AND node.lon BETWEEN ? AND ?
 `
 )
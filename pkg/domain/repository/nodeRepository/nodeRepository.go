@@ -8,6 +8,7 @@ import (
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
 	"sync"
+	"time"
 )
 
 type NodeRepository interface {
@@ -16,6 +17,8 @@ type NodeRepository interface {
 	InsertNode(node node.Node) error
 	InsertNodes(nodes []node.Node) error
 
+	DeleteNode(id int64) error
+
 	SelectNodeFromID(id int64) (*node.Node, error)
 
 	SelectNodeIDsFromWayID(wayID int64) ([]int64, error)
@@ -24,6 +27,11 @@ type NodeRepository interface {
 	SelectCenterOfWayID(wayID int64) (lat, lon float64, err error)
 
 	SelectNearNodesApprox(lat float64, lon float64, radius float64) ([]*node.Node, error)
+
+	// SelectSampleNode returns any node, that is part of a way
+	SelectSampleNode() (*node.Node, error)
+
+	Prepared() bool
 }
 
 type impl struct {
@@ -34,7 +42,9 @@ type impl struct {
 }
 
 type preparedStatements struct {
-	insertNode *sql.Stmt
+	insertNode  *sql.Stmt
+	insertNodes *sql.Stmt
+	deleteNode  *sql.Stmt
 
 	selectNodeFromID *sql.Stmt
 
@@ -44,6 +54,8 @@ type preparedStatements struct {
 	selectCenterOfWayID *sql.Stmt
 
 	selectNearNodes *sql.Stmt
+
+	selectSampleNode *sql.Stmt
 }
 
 func New(db database.Database) NodeRepository {
@@ -58,6 +70,11 @@ func (i *impl) Init(createIndices bool) error {
 		return fmt.Errorf("error while creating data model: %s", err.Error())
 	}
 
+	err = i.migrateElevationColumn()
+	if err != nil {
+		return fmt.Errorf("error while migrating elevation column: %s", err.Error())
+	}
+
 	if createIndices {
 		err = i.InitIndices()
 		if err != nil {
@@ -82,12 +99,51 @@ func (i *impl) InitIndices() error {
 	return nil
 }
 
+// migrateElevationColumn adds the ele column to databases imported before elevation support
+func (i *impl) migrateElevationColumn() error {
+	rows, err := i.db.Query(selectHasElevationColumn)
+	if err != nil {
+		return fmt.Errorf("error while checking for elevation column: %s", err.Error())
+	}
+
+	var count int
+	for rows.Next() {
+		err = rows.Scan(&count)
+		if err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("error while scanning elevation column count: %s", err.Error())
+		}
+	}
+	_ = rows.Close()
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = i.db.Exec(addElevationColumn)
+	if err != nil {
+		return fmt.Errorf("error while adding elevation column: %s", err.Error())
+	}
+
+	return nil
+}
+
 func (i *impl) prepareStatements() error {
 	insertNode, err := i.db.Prepare(insertNode)
 	if err != nil {
 		return fmt.Errorf("error while preparing insert node statement: %s", err.Error())
 	}
 
+	insertNodes, err := i.db.Prepare(fmt.Sprintf(insertNodes, database.Values(nodeColumns, insertNodesRows)))
+	if err != nil {
+		return fmt.Errorf("error while preparing insert nodes statement: %s", err.Error())
+	}
+
+	deleteNode, err := i.db.Prepare(deleteNode)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete node statement: %s", err.Error())
+	}
+
 	selectNodeIDsFromWayID, err := i.db.Prepare(selectNodeIDsFromWayID)
 	if err != nil {
 		return fmt.Errorf("error while preparing insert way to node relation statement: %s", err.Error())
@@ -113,7 +169,14 @@ func (i *impl) prepareStatements() error {
 		return fmt.Errorf("error while preparing select center of way statement: %s", err.Error())
 	}
 
+	selectSampleNode, err := i.db.Prepare(selectSampleNode)
+	if err != nil {
+		return fmt.Errorf("error while preparing select sample node statement: %s", err.Error())
+	}
+
 	i.preparedStatements.insertNode = insertNode
+	i.preparedStatements.insertNodes = insertNodes
+	i.preparedStatements.deleteNode = deleteNode
 
 	i.preparedStatements.selectNodeFromID = selectNodeFromID
 
@@ -124,9 +187,16 @@ func (i *impl) prepareStatements() error {
 
 	i.preparedStatements.selectNearNodes = selectNearNodes
 
+	i.preparedStatements.selectSampleNode = selectSampleNode
+
 	return nil
 }
 
+// Prepared reports whether Init() has prepared the statements
+func (i *impl) Prepared() bool {
+	return i.preparedStatements.selectSampleNode != nil
+}
+
 func (i *impl) encodeTags(tags map[string]string) ([]byte, error) {
 	i.bufferLock.Lock()
 	defer i.bufferLock.Unlock()
@@ -139,7 +209,8 @@ func (i *impl) encodeTags(tags map[string]string) ([]byte, error) {
 		return nil, fmt.Errorf("error while encoding tags: %s", err.Error())
 	}
 
-	return i.buf.Bytes(), nil
+	// the buffer is reused, the encoded tags are kept until the batch of rows is inserted
+	return bytes.Clone(i.buf.Bytes()), nil
 }
 
 func decodeTags(buf *bytes.Buffer) (map[string]string, error) {
@@ -161,7 +232,7 @@ func (i *impl) InsertNode(node node.Node) error {
 		return fmt.Errorf("error while encoding tags: %s", err.Error())
 	}
 
-	_, err = i.preparedStatements.insertNode.Exec(node.OsmID, node.Lat, node.Lon, tags)
+	_, err = i.preparedStatements.insertNode.Exec(node.OsmID, node.Lat, node.Lon, encodeElevation(node), tags)
 	if err != nil {
 		return fmt.Errorf("error while inserting node: %s", err.Error())
 	}
@@ -169,9 +240,15 @@ func (i *impl) InsertNode(node node.Node) error {
 	return nil
 }
 
+// nodeColumns is the number of values of a node, insertNodesRows the number of nodes inserted by one statement
+const (
+	nodeColumns     = 5
+	insertNodesRows = 256
+)
+
 func (i *impl) InsertNodes(nodes []node.Node) error {
-	if i.preparedStatements.insertNode == nil {
-		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertNode()")
+	if i.preparedStatements.insertNode == nil || i.preparedStatements.insertNodes == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertNodes()")
 	}
 
 	tx, err := i.db.Begin()
@@ -180,15 +257,32 @@ func (i *impl) InsertNodes(nodes []node.Node) error {
 	}
 
 	insertNode := tx.Stmt(i.preparedStatements.insertNode)
+	insertNodes := tx.Stmt(i.preparedStatements.insertNodes)
 
+	values := make([]any, 0, nodeColumns*insertNodesRows)
 	for _, node := range nodes {
 		tags, err := i.encodeTags(node.Tags)
 		if err != nil {
+			_ = tx.Rollback()
 			return fmt.Errorf("error while encoding tags: %s", err.Error())
 		}
 
-		_, err = insertNode.Exec(node.OsmID, node.Lat, node.Lon, tags)
+		values = append(values, node.OsmID, node.Lat, node.Lon, encodeElevation(node), tags)
+		if len(values) == cap(values) {
+			_, err = insertNodes.Exec(values...)
+			if err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("error while inserting nodes: %s", err.Error())
+			}
+			values = values[:0]
+		}
+	}
+
+	// the nodes not filling a whole statement are inserted one by one
+	for start := 0; start < len(values); start += nodeColumns {
+		_, err = insertNode.Exec(values[start : start+nodeColumns]...)
 		if err != nil {
+			_ = tx.Rollback()
 			return fmt.Errorf("error while inserting node: %s", err.Error())
 		}
 	}
@@ -201,7 +295,22 @@ func (i *impl) InsertNodes(nodes []node.Node) error {
 	return nil
 }
 
+func (i *impl) DeleteNode(id int64) error {
+	if i.preparedStatements.deleteNode == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call DeleteNode()")
+	}
+
+	_, err := i.preparedStatements.deleteNode.Exec(id)
+	if err != nil {
+		return fmt.Errorf("error while deleting node: %s", err.Error())
+	}
+
+	return nil
+}
+
 func (i *impl) SelectNodeFromID(id int64) (*node.Node, error) {
+	defer database.ObserveQuery("node", "SelectNodeFromID", time.Now())
+
 	if i.preparedStatements.selectNodeFromID == nil {
 		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectNodeFromID()")
 	}
@@ -224,6 +333,8 @@ func (i *impl) SelectNodeFromID(id int64) (*node.Node, error) {
 }
 
 func (i *impl) SelectNodeIDsFromWayID(wayID int64) ([]int64, error) {
+	defer database.ObserveQuery("node", "SelectNodeIDsFromWayID", time.Now())
+
 	rows, err := i.preparedStatements.selectNodeIDsFromWayID.Query(wayID)
 	if err != nil {
 		return nil, fmt.Errorf("error while querying nodes from way: %s", err.Error())
@@ -243,16 +354,23 @@ func (i *impl) SelectNodeIDsFromWayID(wayID int64) ([]int64, error) {
 	return nodes, nil
 }
 
+func encodeElevation(node node.Node) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: node.Ele, Valid: node.HasEle}
+}
+
 func decodeNodes(rows *sql.Rows) ([]*node.Node, error) {
 	var nodes []*node.Node
 	for rows.Next() {
 		var node node.Node
 		var buf []byte
-		err := rows.Scan(&node.OsmID, &node.Lat, &node.Lon, &buf)
+		var ele sql.NullFloat64
+		err := rows.Scan(&node.OsmID, &node.Lat, &node.Lon, &ele, &buf)
 		if err != nil {
 			return nil, fmt.Errorf("error while scanning node id: %s", err.Error())
 		}
 
+		node.Ele, node.HasEle = ele.Float64, ele.Valid
+
 		buffer := bytes.NewBuffer(buf)
 		node.Tags, err = decodeTags(buffer)
 		if err != nil {
@@ -266,6 +384,8 @@ func decodeNodes(rows *sql.Rows) ([]*node.Node, error) {
 }
 
 func (i *impl) SelectNodesFromWayID(wayID int64) ([]*node.Node, error) {
+	defer database.ObserveQuery("node", "SelectNodesFromWayID", time.Now())
+
 	if i.preparedStatements.selectNodesFromWayID == nil {
 		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectNodeIDsFromWayID()")
 	}
@@ -285,6 +405,8 @@ func (i *impl) SelectNodesFromWayID(wayID int64) ([]*node.Node, error) {
 }
 
 func (i *impl) SelectCenterOfWayID(wayID int64) (lat, lon float64, err error) {
+	defer database.ObserveQuery("node", "SelectCenterOfWayID", time.Now())
+
 	if i.preparedStatements.selectCenterOfWayID == nil {
 		return 0, 0, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectCenterOfWayID()")
 	}
@@ -298,6 +420,8 @@ func (i *impl) SelectCenterOfWayID(wayID int64) (lat, lon float64, err error) {
 }
 
 func (i *impl) SelectNearNodesApprox(lat float64, lon float64, radius float64) ([]*node.Node, error) {
+	defer database.ObserveQuery("node", "SelectNearNodesApprox", time.Now())
+
 	if i.preparedStatements.selectNearNodes == nil {
 		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectNearNodesApprox()")
 	}
@@ -326,3 +450,27 @@ func (i *impl) SelectNearNodesApprox(lat float64, lon float64, radius float64) (
 
 	return nodes, nil
 }
+
+func (i *impl) SelectSampleNode() (*node.Node, error) {
+	defer database.ObserveQuery("node", "SelectSampleNode", time.Now())
+
+	if i.preparedStatements.selectSampleNode == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectSampleNode()")
+	}
+
+	rows, err := i.preparedStatements.selectSampleNode.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while querying sample node: %s", err.Error())
+	}
+
+	nodes, err := decodeNodes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding nodes: %s", err.Error())
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no node found")
+	}
+
+	return nodes[0], nil
+}
@@ -0,0 +1,172 @@
+package osmdatarepository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmpbfreaderdata"
+	"io"
+)
+
+// mergeKey orders the elements like a file sorted by type and id: nodes, ways and relations, each by ascending id
+type mergeKey struct {
+	rank int
+	id   int64
+}
+
+func (k mergeKey) less(other mergeKey) bool {
+	if k.rank != other.rank {
+		return k.rank < other.rank
+	}
+	return k.id < other.id
+}
+
+type mergeSource struct {
+	file   string
+	reader *osmReader
+
+	head    any
+	key     mergeKey
+	version int32
+	done    bool
+
+	offset   int64 // the end of the last blob read completely
+	advanced bool  // whether the offset changed since the progress was reported
+}
+
+// next reads the next element of the file and checks, that the file is sorted
+func (s *mergeSource) next() error {
+	data, err := s.reader.Next()
+	for err == nil {
+		blobEnd, ok := data.(osmpbfreaderdata.BlobEnd)
+		if !ok {
+			break
+		}
+
+		// the ends of the blobs only tell the progress, merged files cannot be continued at an offset
+		s.offset, s.advanced = blobEnd.Offset, true
+		data, err = s.reader.Next()
+	}
+
+	if errors.Is(err, io.EOF) {
+		s.done = true
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error while reading %s: %s", s.file, err.Error())
+	}
+
+	var key mergeKey
+	var version int32
+	switch v := data.(type) {
+	case osmpbfreaderdata.Node:
+		key, version = mergeKey{rank: 0, id: v.ID}, v.Info.Version
+	case osmpbfreaderdata.Way:
+		key, version = mergeKey{rank: 1, id: v.ID}, v.Info.Version
+	case osmpbfreaderdata.Relation:
+		key, version = mergeKey{rank: 2, id: v.ID}, v.Info.Version
+	default:
+		return fmt.Errorf("unknown data type: %T", v)
+	}
+
+	if s.head != nil && !s.key.less(key) {
+		return fmt.Errorf("%s is not sorted by type and id, sort it before merging (e.g. with osmium sort)", s.file)
+	}
+
+	s.head, s.key, s.version = data, key, version
+	return nil
+}
+
+// ProcessMerged reads several files at once, like a single file. Elements contained in more than one file,
+// e.g. along the borders of neighbouring extracts, are only processed once, in the version with the highest number.
+// The files have to be sorted by type and id, as the extracts of Geofabrik and files written by osmium are.
+func (o *impl) ProcessMerged(files []string, processor OsmDataProcessor, filter OsmDataFilter) error {
+	parallelization := o.parallelization / len(files)
+	if parallelization < 1 {
+		parallelization = 1
+	}
+
+	sources := make([]*mergeSource, len(files))
+	for index, file := range files {
+		reader := &osmReader{
+			parallelization: parallelization,
+		}
+		defer reader.Stop()
+
+		err := reader.ReadFrom(file, 0, filter)
+		if err != nil {
+			return fmt.Errorf("error while reading file %s: %s", file, err.Error())
+		}
+
+		sources[index] = &mergeSource{file: file, reader: reader}
+
+		err = sources[index].next()
+		if err != nil {
+			return err
+		}
+	}
+
+	progressor, _ := processor.(OsmDataProgressor)
+
+	for {
+		if progressor != nil {
+			reportMergeProgress(progressor, sources)
+		}
+
+		var newest *mergeSource
+		for _, source := range sources {
+			if source.done {
+				continue
+			}
+
+			if newest == nil || source.key.less(newest.key) || (source.key == newest.key && source.version > newest.version) {
+				newest = source
+			}
+		}
+
+		if newest == nil {
+			err := processor.OnFinish()
+			if err != nil {
+				return fmt.Errorf("error while finishing processing: %s", err.Error())
+			}
+			return nil
+		}
+
+		switch v := newest.head.(type) {
+		case osmpbfreaderdata.Node:
+			processor.ProcessNode(v)
+		case osmpbfreaderdata.Way:
+			processor.ProcessWay(v)
+		case osmpbfreaderdata.Relation:
+			processor.ProcessRelation(v)
+		}
+
+		// the duplicates in the other files are skipped
+		key := newest.key
+		for _, source := range sources {
+			if source.done || source.key != key {
+				continue
+			}
+
+			err := source.next()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reportMergeProgress reports the bytes read from all files, if any file finished a blob
+func reportMergeProgress(progressor OsmDataProgressor, sources []*mergeSource) {
+	advanced := false
+	var bytes int64
+	for _, source := range sources {
+		advanced = advanced || source.advanced
+		source.advanced = false
+		bytes += source.offset
+	}
+
+	if advanced {
+		progressor.Progress(bytes)
+	}
+}
@@ -21,14 +21,15 @@ func (o *osmReader) Stop() {
 	}
 }
 
-func (o *osmReader) Read(filePath string, filter filter.Filter) error {
+// ReadFrom starts reading at the offset of a osmpbfreaderdata.BlobEnd, which Next returns after each blob
+func (o *osmReader) ReadFrom(filePath string, offset int64, filter filter.Filter) error {
 	var err error
 	o.file, err = os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("error while opening file: %s", err.Error())
 	}
 
-	o.decoder = osmpbfreader.New(o.file)
+	o.decoder = osmpbfreader.NewResumable(o.file, offset)
 	err = o.decoder.Start(osmpbfreader.ProcsCount(o.parallelization), filter)
 	if err != nil {
 		return fmt.Errorf("error while starting decoder: %s", err.Error())
@@ -38,7 +39,7 @@ func (o *osmReader) Read(filePath string, filter filter.Filter) error {
 
 func (o *osmReader) Next() (any, error) {
 	if o.decoder == nil {
-		return nil, errors.New("no decoder loaded: you need to call Read() before you can call Next()")
+		return nil, errors.New("no decoder loaded: you need to call ReadFrom() before you can call Next()")
 	}
 
 	data, err := o.decoder.Decode()
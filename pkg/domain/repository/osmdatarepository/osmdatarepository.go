@@ -13,7 +13,21 @@ type OsmDataProcessor interface {
 	ProcessWay(way osmpbfreaderdata.Way)
 	ProcessRelation(relation osmpbfreaderdata.Relation)
 
-	OnFinish()
+	// OnFinish is called after the last element, an error aborts the processing
+	OnFinish() error
+}
+
+// OsmDataCheckpointer can be implemented by an OsmDataProcessor to persist its progress. Checkpoint is called
+// after all elements before the offset have been processed, ProcessFrom continues at this offset.
+// An error aborts the processing, so that no later checkpoint is saved.
+type OsmDataCheckpointer interface {
+	Checkpoint(offset int64) error
+}
+
+// OsmDataProgressor can be implemented by an OsmDataProcessor to report its progress. Progress is called after each
+// blob with the number of bytes read, summed over all files when they are merged.
+type OsmDataProgressor interface {
+	Progress(bytes int64)
 }
 
 type OsmDataFilter interface {
@@ -48,6 +62,8 @@ func (i *BinaryOsmDataFilter) FilterRelations() bool {
 
 type OsmDataRepository interface {
 	Process(file string, processor OsmDataProcessor, filter OsmDataFilter) error
+	ProcessFrom(file string, offset int64, processor OsmDataProcessor, filter OsmDataFilter) error
+	ProcessMerged(files []string, processor OsmDataProcessor, filter OsmDataFilter) error
 }
 
 type impl struct {
@@ -61,18 +77,30 @@ func New(parallelization int) OsmDataRepository {
 }
 
 func (o *impl) Process(file string, processor OsmDataProcessor, filter OsmDataFilter) error {
+	return o.ProcessFrom(file, 0, processor, filter)
+}
+
+func (o *impl) ProcessFrom(file string, offset int64, processor OsmDataProcessor, filter OsmDataFilter) error {
 	reader := &osmReader{
 		parallelization: o.parallelization,
 	}
-	err := reader.Read(file, filter)
+	defer reader.Stop()
+
+	err := reader.ReadFrom(file, offset, filter)
 	if err != nil {
 		return fmt.Errorf("error while reading file: %s", err.Error())
 	}
 
+	checkpointer, _ := processor.(OsmDataCheckpointer)
+	progressor, _ := processor.(OsmDataProgressor)
+
 	for {
 		data, err := reader.Next()
 		if errors.Is(err, io.EOF) {
-			processor.OnFinish()
+			err = processor.OnFinish()
+			if err != nil {
+				return fmt.Errorf("error while finishing processing: %s", err.Error())
+			}
 			return nil
 		}
 
@@ -87,6 +115,16 @@ func (o *impl) Process(file string, processor OsmDataProcessor, filter OsmDataFi
 			processor.ProcessWay(v)
 		case osmpbfreaderdata.Relation:
 			processor.ProcessRelation(v)
+		case osmpbfreaderdata.BlobEnd:
+			if checkpointer != nil {
+				err = checkpointer.Checkpoint(v.Offset)
+				if err != nil {
+					return fmt.Errorf("error while saving checkpoint: %s", err.Error())
+				}
+			}
+			if progressor != nil {
+				progressor.Progress(v.Offset)
+			}
 		default:
 			return fmt.Errorf("unknown data type: %T", v)
 		}
@@ -0,0 +1,111 @@
+package transitRepository
+
+const (
+	dataModel = `
+CREATE TABLE IF NOT EXISTS transitFeed (
+    name TEXT PRIMARY KEY NOT NULL,
+    timezone TEXT NOT NULL
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS transitStop (
+    id TEXT PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL,
+    lat REAL NOT NULL,
+    lon REAL NOT NULL,
+    parent TEXT NOT NULL,
+    node_id INTEGER NOT NULL
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS transitTrip (
+    id TEXT PRIMARY KEY NOT NULL,
+    service_id TEXT NOT NULL,
+    route_name TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    headsign TEXT NOT NULL
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS transitConnection (
+    trip_id TEXT NOT NULL,
+    departure_stop TEXT NOT NULL,
+    arrival_stop TEXT NOT NULL,
+    departure_time INTEGER NOT NULL,
+    arrival_time INTEGER NOT NULL
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS transitService (
+    id TEXT PRIMARY KEY NOT NULL,
+    weekdays INTEGER NOT NULL, -- bit i is set if the service runs on time.Weekday(i)
+    start_date TEXT NOT NULL,
+    end_date TEXT NOT NULL
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS transitServiceException (
+    service_id TEXT NOT NULL,
+    date TEXT NOT NULL,
+    added INTEGER NOT NULL
+) STRICT;
+
+CREATE INDEX IF NOT EXISTS transitConnection_departure_time_idx ON transitConnection (departure_time);
+`
+
+	insertFeed = `
+INSERT INTO transitFeed (name, timezone) VALUES (?, ?)
+	ON CONFLICT (name) DO UPDATE SET timezone = excluded.timezone;
+`
+
+	insertStop = `
+INSERT INTO transitStop (id, name, lat, lon, parent, node_id) VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE SET name = excluded.name, lat = excluded.lat, lon = excluded.lon, parent = excluded.parent, node_id = excluded.node_id;
+`
+
+	insertTrip = `
+INSERT INTO transitTrip (id, service_id, route_name, mode, headsign) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE SET service_id = excluded.service_id, route_name = excluded.route_name, mode = excluded.mode, headsign = excluded.headsign;
+`
+
+	insertConnection = `
+INSERT INTO transitConnection (trip_id, departure_stop, arrival_stop, departure_time, arrival_time) VALUES (?, ?, ?, ?, ?);
+`
+
+	insertService = `
+INSERT INTO transitService (id, weekdays, start_date, end_date) VALUES (?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE SET weekdays = excluded.weekdays, start_date = excluded.start_date, end_date = excluded.end_date;
+`
+
+	insertServiceException = `
+INSERT INTO transitServiceException (service_id, date, added) VALUES (?, ?, ?);
+`
+
+	deleteFeedConnections = `
+DELETE FROM transitConnection WHERE substr(trip_id, 1, length(?1) + 1) = ?1 || ':';
+`
+
+	deleteFeedServiceExceptions = `
+DELETE FROM transitServiceException WHERE substr(service_id, 1, length(?1) + 1) = ?1 || ':';
+`
+
+	selectFeeds = `
+SELECT name, timezone FROM transitFeed ORDER BY name;
+`
+
+	selectStops = `
+SELECT id, name, lat, lon, parent, node_id FROM transitStop;
+`
+
+	selectTrips = `
+SELECT id, service_id, route_name, mode, headsign FROM transitTrip;
+`
+
+	selectConnections = `
+SELECT trip_id, departure_stop, arrival_stop, departure_time, arrival_time FROM transitConnection
+	ORDER BY departure_time ASC, arrival_time ASC;
+`
+
+	selectServices = `
+SELECT id, weekdays, start_date, end_date FROM transitService;
+`
+
+	selectServiceExceptions = `
+SELECT service_id, date, added FROM transitServiceException;
+`
+)
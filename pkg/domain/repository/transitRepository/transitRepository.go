@@ -0,0 +1,377 @@
+package transitRepository
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/transit"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+	"time"
+)
+
+type TransitRepository interface {
+	Init() error
+
+	InsertFeed(feed transit.Feed) error
+
+	SelectFeedTimezones() (map[string]string, error)
+	SelectStops() ([]*transit.Stop, error)
+	SelectTrips() ([]*transit.Trip, error)
+	SelectConnections() ([]*transit.Connection, error)
+	SelectServices() ([]*transit.Service, error)
+
+	Prepared() bool
+}
+
+type impl struct {
+	db                 database.Database
+	preparedStatements preparedStatements
+}
+
+type preparedStatements struct {
+	insertFeed                  *sql.Stmt
+	insertStop                  *sql.Stmt
+	insertTrip                  *sql.Stmt
+	insertConnection            *sql.Stmt
+	insertService               *sql.Stmt
+	insertServiceException      *sql.Stmt
+	deleteFeedConnections       *sql.Stmt
+	deleteFeedServiceExceptions *sql.Stmt
+	selectFeeds                 *sql.Stmt
+	selectStops                 *sql.Stmt
+	selectTrips                 *sql.Stmt
+	selectConnections           *sql.Stmt
+	selectServices              *sql.Stmt
+	selectServiceExceptions     *sql.Stmt
+}
+
+func New(db database.Database) TransitRepository {
+	return &impl{
+		db: db,
+	}
+}
+
+func (i *impl) Init() error {
+	_, err := i.db.Exec(dataModel)
+	if err != nil {
+		return fmt.Errorf("error while running data model: %s", err.Error())
+	}
+
+	err = i.prepareStatements()
+	if err != nil {
+		return fmt.Errorf("error while preparing statements: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) prepareStatements() error {
+	statements := []struct {
+		name  string
+		query string
+		stmt  **sql.Stmt
+	}{
+		{"insertFeed", insertFeed, &i.preparedStatements.insertFeed},
+		{"insertStop", insertStop, &i.preparedStatements.insertStop},
+		{"insertTrip", insertTrip, &i.preparedStatements.insertTrip},
+		{"insertConnection", insertConnection, &i.preparedStatements.insertConnection},
+		{"insertService", insertService, &i.preparedStatements.insertService},
+		{"insertServiceException", insertServiceException, &i.preparedStatements.insertServiceException},
+		{"deleteFeedConnections", deleteFeedConnections, &i.preparedStatements.deleteFeedConnections},
+		{"deleteFeedServiceExceptions", deleteFeedServiceExceptions, &i.preparedStatements.deleteFeedServiceExceptions},
+		{"selectFeeds", selectFeeds, &i.preparedStatements.selectFeeds},
+		{"selectStops", selectStops, &i.preparedStatements.selectStops},
+		{"selectTrips", selectTrips, &i.preparedStatements.selectTrips},
+		{"selectConnections", selectConnections, &i.preparedStatements.selectConnections},
+		{"selectServices", selectServices, &i.preparedStatements.selectServices},
+		{"selectServiceExceptions", selectServiceExceptions, &i.preparedStatements.selectServiceExceptions},
+	}
+
+	for _, statement := range statements {
+		stmt, err := i.db.Prepare(statement.query)
+		if err != nil {
+			return fmt.Errorf("error while preparing %s statement: %s", statement.name, err.Error())
+		}
+		*statement.stmt = stmt
+	}
+
+	return nil
+}
+
+// Prepared reports whether Init() has prepared the statements
+func (i *impl) Prepared() bool {
+	return i.preparedStatements.selectServiceExceptions != nil
+}
+
+// InsertFeed replaces all data of a feed with the same name in a single transaction
+func (i *impl) InsertFeed(feed transit.Feed) error {
+	if i.preparedStatements.insertFeed == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertFeed()")
+	}
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %s", err.Error())
+	}
+
+	err = i.insertFeed(tx, feed)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing transaction: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) insertFeed(tx *sql.Tx, feed transit.Feed) error {
+	_, err := tx.Stmt(i.preparedStatements.insertFeed).Exec(feed.Name, feed.Timezone)
+	if err != nil {
+		return fmt.Errorf("error while inserting feed: %s", err.Error())
+	}
+
+	_, err = tx.Stmt(i.preparedStatements.deleteFeedConnections).Exec(feed.Name)
+	if err != nil {
+		return fmt.Errorf("error while deleting old connections: %s", err.Error())
+	}
+
+	_, err = tx.Stmt(i.preparedStatements.deleteFeedServiceExceptions).Exec(feed.Name)
+	if err != nil {
+		return fmt.Errorf("error while deleting old service exceptions: %s", err.Error())
+	}
+
+	insertStop := tx.Stmt(i.preparedStatements.insertStop)
+	for _, stop := range feed.Stops {
+		_, err = insertStop.Exec(stop.ID, stop.Name, stop.Lat, stop.Lon, stop.Parent, stop.NodeID)
+		if err != nil {
+			return fmt.Errorf("error while inserting stop: %s", err.Error())
+		}
+	}
+
+	insertTrip := tx.Stmt(i.preparedStatements.insertTrip)
+	for _, trip := range feed.Trips {
+		_, err = insertTrip.Exec(trip.ID, trip.ServiceID, trip.RouteName, trip.Mode, trip.Headsign)
+		if err != nil {
+			return fmt.Errorf("error while inserting trip: %s", err.Error())
+		}
+	}
+
+	insertConnection := tx.Stmt(i.preparedStatements.insertConnection)
+	for _, connection := range feed.Connections {
+		_, err = insertConnection.Exec(connection.TripID, connection.DepartureStop, connection.ArrivalStop, connection.DepartureTime, connection.ArrivalTime)
+		if err != nil {
+			return fmt.Errorf("error while inserting connection: %s", err.Error())
+		}
+	}
+
+	insertService := tx.Stmt(i.preparedStatements.insertService)
+	insertServiceException := tx.Stmt(i.preparedStatements.insertServiceException)
+	for _, service := range feed.Services {
+		_, err = insertService.Exec(service.ID, encodeWeekdays(service.Weekdays), service.StartDate, service.EndDate)
+		if err != nil {
+			return fmt.Errorf("error while inserting service: %s", err.Error())
+		}
+
+		for date := range service.Added {
+			_, err = insertServiceException.Exec(service.ID, date, true)
+			if err != nil {
+				return fmt.Errorf("error while inserting service exception: %s", err.Error())
+			}
+		}
+
+		for date := range service.Removed {
+			_, err = insertServiceException.Exec(service.ID, date, false)
+			if err != nil {
+				return fmt.Errorf("error while inserting service exception: %s", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func encodeWeekdays(weekdays [7]bool) int {
+	out := 0
+	for day, active := range weekdays {
+		if active {
+			out |= 1 << day
+		}
+	}
+	return out
+}
+
+func decodeWeekdays(encoded int) [7]bool {
+	var out [7]bool
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		out[day] = encoded&(1<<day) != 0
+	}
+	return out
+}
+
+func (i *impl) SelectFeedTimezones() (map[string]string, error) {
+	defer database.ObserveQuery("transit", "SelectFeedTimezones", time.Now())
+
+	if i.preparedStatements.selectFeeds == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectFeedTimezones()")
+	}
+
+	rows, err := i.preparedStatements.selectFeeds.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting feeds: %s", err.Error())
+	}
+	defer rows.Close()
+
+	out := make(map[string]string)
+	for rows.Next() {
+		var name, timezone string
+		err = rows.Scan(&name, &timezone)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning feed: %s", err.Error())
+		}
+		out[name] = timezone
+	}
+
+	return out, nil
+}
+
+func (i *impl) SelectStops() ([]*transit.Stop, error) {
+	defer database.ObserveQuery("transit", "SelectStops", time.Now())
+
+	if i.preparedStatements.selectStops == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectStops()")
+	}
+
+	rows, err := i.preparedStatements.selectStops.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting stops: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var out []*transit.Stop
+	for rows.Next() {
+		var stop transit.Stop
+		err = rows.Scan(&stop.ID, &stop.Name, &stop.Lat, &stop.Lon, &stop.Parent, &stop.NodeID)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning stop: %s", err.Error())
+		}
+		out = append(out, &stop)
+	}
+
+	return out, nil
+}
+
+func (i *impl) SelectTrips() ([]*transit.Trip, error) {
+	defer database.ObserveQuery("transit", "SelectTrips", time.Now())
+
+	if i.preparedStatements.selectTrips == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectTrips()")
+	}
+
+	rows, err := i.preparedStatements.selectTrips.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting trips: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var out []*transit.Trip
+	for rows.Next() {
+		var trip transit.Trip
+		err = rows.Scan(&trip.ID, &trip.ServiceID, &trip.RouteName, &trip.Mode, &trip.Headsign)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning trip: %s", err.Error())
+		}
+		out = append(out, &trip)
+	}
+
+	return out, nil
+}
+
+func (i *impl) SelectConnections() ([]*transit.Connection, error) {
+	defer database.ObserveQuery("transit", "SelectConnections", time.Now())
+
+	if i.preparedStatements.selectConnections == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectConnections()")
+	}
+
+	rows, err := i.preparedStatements.selectConnections.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting connections: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var out []*transit.Connection
+	for rows.Next() {
+		var connection transit.Connection
+		err = rows.Scan(&connection.TripID, &connection.DepartureStop, &connection.ArrivalStop, &connection.DepartureTime, &connection.ArrivalTime)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning connection: %s", err.Error())
+		}
+		out = append(out, &connection)
+	}
+
+	return out, nil
+}
+
+func (i *impl) SelectServices() ([]*transit.Service, error) {
+	defer database.ObserveQuery("transit", "SelectServices", time.Now())
+
+	if i.preparedStatements.selectServices == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectServices()")
+	}
+
+	rows, err := i.preparedStatements.selectServices.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting services: %s", err.Error())
+	}
+
+	services := make(map[string]*transit.Service)
+	var out []*transit.Service
+	for rows.Next() {
+		var service transit.Service
+		var weekdays int
+		err = rows.Scan(&service.ID, &weekdays, &service.StartDate, &service.EndDate)
+		if err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("error while scanning service: %s", err.Error())
+		}
+
+		service.Weekdays = decodeWeekdays(weekdays)
+		service.Added = make(map[string]bool)
+		service.Removed = make(map[string]bool)
+
+		services[service.ID] = &service
+		out = append(out, &service)
+	}
+	_ = rows.Close()
+
+	rows, err = i.preparedStatements.selectServiceExceptions.Query()
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting service exceptions: %s", err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serviceID, date string
+		var added bool
+		err = rows.Scan(&serviceID, &date, &added)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning service exception: %s", err.Error())
+		}
+
+		service, ok := services[serviceID]
+		if !ok {
+			continue
+		}
+
+		if added {
+			service.Added[date] = true
+		} else {
+			service.Removed[date] = true
+		}
+	}
+
+	return out, nil
+}
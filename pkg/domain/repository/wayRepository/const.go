@@ -29,6 +29,16 @@ INSERT INTO way (osm_id, tags) VALUES (?, ?)
 
 	insertWayToNodeRelation = `
 INSERT INTO wayToNodeRelation (node_id, way_id, position) VALUES (?, ?, ?);
+`
+
+	// insertWays and insertWayToNodeRelations insert several rows at once, the values are filled in by Init()
+	insertWays = `
+INSERT INTO way (osm_id, tags) VALUES %s
+	ON CONFLICT (osm_id) DO UPDATE SET tags = excluded.tags;
+`
+
+	insertWayToNodeRelations = `
+INSERT INTO wayToNodeRelation (node_id, way_id, position) VALUES %s;
 `
 
 	updateCrossings = `
@@ -42,6 +52,46 @@ WHEN node_id IN (
 ) THEN true
 ELSE false
 END;
+`
+
+	updateCrossingsInRange = `
+UPDATE wayToNodeRelation
+SET is_crossing = EXISTS (
+  SELECT 1
+  FROM wayToNodeRelation AS other
+  WHERE other.node_id = wayToNodeRelation.node_id AND other.rowid != wayToNodeRelation.rowid
+)
+WHERE rowid > ? AND rowid <= ?;
+`
+
+	deleteWay = `
+DELETE FROM way WHERE osm_id = ?;
+`
+
+	deleteWayToNodeRelations = `
+DELETE FROM wayToNodeRelation WHERE way_id = ?;
+`
+
+	selectLastRelationRowID = `
+SELECT COALESCE(MAX(rowid), 0) FROM wayToNodeRelation;
+`
+
+	deleteRelationsAfterRowID = `
+DELETE FROM wayToNodeRelation WHERE rowid > ?;
+`
+
+	updateCrossingsOfNode = `
+UPDATE wayToNodeRelation
+SET is_crossing = (
+  SELECT COUNT(*) >= 2
+  FROM wayToNodeRelation AS other
+  WHERE other.node_id = wayToNodeRelation.node_id
+)
+WHERE node_id = ?;
+`
+
+	selectWayFromID = `
+SELECT osm_id, tags FROM way WHERE osm_id = ?;
 `
 
 	selectWayIDsFromNodeID = `
@@ -8,6 +8,7 @@ import (
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
 	"sync"
+	"time"
 )
 
 type WayRepository interface {
@@ -17,12 +18,28 @@ type WayRepository interface {
 
 	InsertWays(ways []way.Way) error
 
+	// DeleteWay deletes the way and its relations to nodes
+	DeleteWay(wayID int64) error
+
+	// SelectLastRelationRowID and DeleteRelationsAfterRowID reset the relations to a checkpoint of an import,
+	// the relations are the only rows of the way tables, that would be duplicated by inserting a way again
+	SelectLastRelationRowID() (int64, error)
+	DeleteRelationsAfterRowID(rowID int64) error
+
+	SelectWayFromID(wayID int64) (*way.Way, error)
 	SelectWayIDsFromNode(nodeID int64) ([]int64, error)
 	SelectWaysFromNode(nodeID int64) ([]*way.Way, error)
 
 	SelectWaysFromTwoNodeIDs(nodeID1 int64, nodeID2 int64) ([]*way.Way, error)
 
 	UpdateCrossings() error
+	// UpdateCrossingsInRange updates is_crossing of the relations with fromRowID < rowid <= toRowID,
+	// it splits UpdateCrossings into parts, whose progress can be reported
+	UpdateCrossingsInRange(fromRowID int64, toRowID int64) error
+	// UpdateCrossingsOfNodes updates is_crossing of all ways at the nodes, it is the incremental UpdateCrossings
+	UpdateCrossingsOfNodes(nodeIDs []int64) error
+
+	Prepared() bool
 }
 
 type impl struct {
@@ -33,15 +50,26 @@ type impl struct {
 }
 
 type preparedStatements struct {
-	insertWay               *sql.Stmt
-	insertWayToNodeRelation *sql.Stmt
+	insertWay                *sql.Stmt
+	insertWays               *sql.Stmt
+	insertWayToNodeRelation  *sql.Stmt
+	insertWayToNodeRelations *sql.Stmt
+
+	deleteWay                *sql.Stmt
+	deleteWayToNodeRelations *sql.Stmt
+
+	selectLastRelationRowID   *sql.Stmt
+	deleteRelationsAfterRowID *sql.Stmt
 
+	selectWayFromID        *sql.Stmt
 	selectWayIDsFromNodeID *sql.Stmt
 	selectWaysFromNodeID   *sql.Stmt
 
 	selectWaysFromTwoNodeIDs *sql.Stmt
 
-	updateCrossings *sql.Stmt
+	updateCrossings        *sql.Stmt
+	updateCrossingsInRange *sql.Stmt
+	updateCrossingsOfNode  *sql.Stmt
 }
 
 func New(db database.Database) WayRepository {
@@ -86,16 +114,51 @@ func (i *impl) prepareStatements() error {
 		return fmt.Errorf("error while preparing insert way statement: %s", err.Error())
 	}
 
+	insertWays, err := i.db.Prepare(fmt.Sprintf(insertWays, database.Values(wayColumns, insertWaysRows)))
+	if err != nil {
+		return fmt.Errorf("error while preparing insert ways statement: %s", err.Error())
+	}
+
 	insertWayToNodeRelation, err := i.db.Prepare(insertWayToNodeRelation)
 	if err != nil {
 		return fmt.Errorf("error while preparing insert way to node relation statement: %s", err.Error())
 	}
 
+	insertWayToNodeRelations, err := i.db.Prepare(fmt.Sprintf(insertWayToNodeRelations, database.Values(relationColumns, insertRelationsRows)))
+	if err != nil {
+		return fmt.Errorf("error while preparing insert way to node relations statement: %s", err.Error())
+	}
+
+	deleteWay, err := i.db.Prepare(deleteWay)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete way statement: %s", err.Error())
+	}
+
+	deleteWayToNodeRelations, err := i.db.Prepare(deleteWayToNodeRelations)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete way to node relations statement: %s", err.Error())
+	}
+
+	selectLastRelationRowID, err := i.db.Prepare(selectLastRelationRowID)
+	if err != nil {
+		return fmt.Errorf("error while preparing select last relation rowid statement: %s", err.Error())
+	}
+
+	deleteRelationsAfterRowID, err := i.db.Prepare(deleteRelationsAfterRowID)
+	if err != nil {
+		return fmt.Errorf("error while preparing delete relations after rowid statement: %s", err.Error())
+	}
+
 	selectWayIDsFromNodeID, err := i.db.Prepare(selectWayIDsFromNodeID)
 	if err != nil {
 		return fmt.Errorf("error while preparing select wayids ids from node statement: %s", err.Error())
 	}
 
+	selectWayFromID, err := i.db.Prepare(selectWayFromID)
+	if err != nil {
+		return fmt.Errorf("error while preparing select way from id statement: %s", err.Error())
+	}
+
 	selectWaysFromNodeID, err := i.db.Prepare(selectWaysFromNodeID)
 	if err != nil {
 		return fmt.Errorf("error while preparing select way ids from node statement: %s", err.Error())
@@ -111,19 +174,45 @@ func (i *impl) prepareStatements() error {
 		return fmt.Errorf("error while preparing update crossings statement: %s", err.Error())
 	}
 
+	updateCrossingsInRange, err := i.db.Prepare(updateCrossingsInRange)
+	if err != nil {
+		return fmt.Errorf("error while preparing update crossings in range statement: %s", err.Error())
+	}
+
+	updateCrossingsOfNode, err := i.db.Prepare(updateCrossingsOfNode)
+	if err != nil {
+		return fmt.Errorf("error while preparing update crossings of node statement: %s", err.Error())
+	}
+
 	i.preparedStatements.insertWay = insertWay
+	i.preparedStatements.insertWays = insertWays
 	i.preparedStatements.insertWayToNodeRelation = insertWayToNodeRelation
+	i.preparedStatements.insertWayToNodeRelations = insertWayToNodeRelations
+
+	i.preparedStatements.deleteWay = deleteWay
+	i.preparedStatements.deleteWayToNodeRelations = deleteWayToNodeRelations
 
+	i.preparedStatements.selectLastRelationRowID = selectLastRelationRowID
+	i.preparedStatements.deleteRelationsAfterRowID = deleteRelationsAfterRowID
+
+	i.preparedStatements.selectWayFromID = selectWayFromID
 	i.preparedStatements.selectWayIDsFromNodeID = selectWayIDsFromNodeID
 	i.preparedStatements.selectWaysFromNodeID = selectWaysFromNodeID
 
 	i.preparedStatements.selectWaysFromTwoNodeIDs = selectWaysFromTwoNodeIDs
 
 	i.preparedStatements.updateCrossings = updateCrossings
+	i.preparedStatements.updateCrossingsInRange = updateCrossingsInRange
+	i.preparedStatements.updateCrossingsOfNode = updateCrossingsOfNode
 
 	return nil
 }
 
+// Prepared reports whether Init() has prepared the statements
+func (i *impl) Prepared() bool {
+	return i.preparedStatements.updateCrossings != nil
+}
+
 func (i *impl) encodeTags(tags map[string]string) ([]byte, error) {
 	i.bufferLock.Lock()
 	defer i.bufferLock.Unlock()
@@ -136,7 +225,8 @@ func (i *impl) encodeTags(tags map[string]string) ([]byte, error) {
 		return nil, fmt.Errorf("error while encoding tags: %s", err.Error())
 	}
 
-	return i.buf.Bytes(), nil
+	// the buffer is reused, the encoded tags are kept until the batch of rows is inserted
+	return bytes.Clone(i.buf.Bytes()), nil
 }
 
 func decodeTags(buf *bytes.Buffer) (map[string]string, error) {
@@ -152,13 +242,22 @@ func (i *impl) InsertWay(w way.Way) error {
 	return i.InsertWays([]way.Way{w})
 }
 
+// wayColumns and relationColumns are the number of values of a row, insertWaysRows and insertRelationsRows the
+// number of rows inserted by one statement
+const (
+	wayColumns          = 2
+	insertWaysRows      = 512
+	relationColumns     = 3
+	insertRelationsRows = 512
+)
+
 func (i *impl) InsertWays(ways []way.Way) error {
-	if i.preparedStatements.insertWay == nil {
-		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertNode()")
+	if i.preparedStatements.insertWay == nil || i.preparedStatements.insertWays == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertWays()")
 	}
 
-	if i.preparedStatements.insertWayToNodeRelation == nil {
-		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertNode()")
+	if i.preparedStatements.insertWayToNodeRelation == nil || i.preparedStatements.insertWayToNodeRelations == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call InsertWays()")
 	}
 
 	tx, err := i.db.Begin()
@@ -167,27 +266,60 @@ func (i *impl) InsertWays(ways []way.Way) error {
 	}
 
 	insertWay := tx.Stmt(i.preparedStatements.insertWay)
+	insertWays := tx.Stmt(i.preparedStatements.insertWays)
 	insertWayToNodeRelation := tx.Stmt(i.preparedStatements.insertWayToNodeRelation)
+	insertWayToNodeRelations := tx.Stmt(i.preparedStatements.insertWayToNodeRelations)
+
+	wayValues := make([]any, 0, wayColumns*insertWaysRows)
+	relationValues := make([]any, 0, relationColumns*insertRelationsRows)
 
 	for _, way := range ways {
 		tags, err := i.encodeTags(way.Tags)
 		if err != nil {
+			_ = tx.Rollback()
 			return fmt.Errorf("error while encoding tags: %s", err.Error())
 		}
 
-		_, err = insertWay.Exec(way.OsmID, tags)
-		if err != nil {
-			return fmt.Errorf("error while inserting way: %s", err.Error())
+		wayValues = append(wayValues, way.OsmID, tags)
+		if len(wayValues) == cap(wayValues) {
+			_, err = insertWays.Exec(wayValues...)
+			if err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("error while inserting ways: %s", err.Error())
+			}
+			wayValues = wayValues[:0]
 		}
 
 		for position, nodeId := range way.Nodes {
-			_, err = insertWayToNodeRelation.Exec(nodeId, way.OsmID, position)
-			if err != nil {
-				return fmt.Errorf("error while inserting way to node relation: %s", err.Error())
+			relationValues = append(relationValues, nodeId, way.OsmID, position)
+			if len(relationValues) == cap(relationValues) {
+				_, err = insertWayToNodeRelations.Exec(relationValues...)
+				if err != nil {
+					_ = tx.Rollback()
+					return fmt.Errorf("error while inserting way to node relations: %s", err.Error())
+				}
+				relationValues = relationValues[:0]
 			}
 		}
 	}
 
+	// the rows not filling a whole statement are inserted one by one
+	for start := 0; start < len(wayValues); start += wayColumns {
+		_, err = insertWay.Exec(wayValues[start : start+wayColumns]...)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("error while inserting way: %s", err.Error())
+		}
+	}
+
+	for start := 0; start < len(relationValues); start += relationColumns {
+		_, err = insertWayToNodeRelation.Exec(relationValues[start : start+relationColumns]...)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("error while inserting way to node relation: %s", err.Error())
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("error while committing transaction: %s", err.Error())
@@ -196,7 +328,91 @@ func (i *impl) InsertWays(ways []way.Way) error {
 	return nil
 }
 
+func (i *impl) DeleteWay(wayID int64) error {
+	if i.preparedStatements.deleteWay == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call DeleteWay()")
+	}
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %s", err.Error())
+	}
+
+	_, err = tx.Stmt(i.preparedStatements.deleteWayToNodeRelations).Exec(wayID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("error while deleting way to node relations: %s", err.Error())
+	}
+
+	_, err = tx.Stmt(i.preparedStatements.deleteWay).Exec(wayID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("error while deleting way: %s", err.Error())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing transaction: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) SelectLastRelationRowID() (int64, error) {
+	if i.preparedStatements.selectLastRelationRowID == nil {
+		return 0, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectLastRelationRowID()")
+	}
+
+	var rowID int64
+	err := i.preparedStatements.selectLastRelationRowID.QueryRow().Scan(&rowID)
+	if err != nil {
+		return 0, fmt.Errorf("error while selecting last relation rowid: %s", err.Error())
+	}
+
+	return rowID, nil
+}
+
+func (i *impl) DeleteRelationsAfterRowID(rowID int64) error {
+	if i.preparedStatements.deleteRelationsAfterRowID == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call DeleteRelationsAfterRowID()")
+	}
+
+	_, err := i.preparedStatements.deleteRelationsAfterRowID.Exec(rowID)
+	if err != nil {
+		return fmt.Errorf("error while deleting relations: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) SelectWayFromID(wayID int64) (*way.Way, error) {
+	defer database.ObserveQuery("way", "SelectWayFromID", time.Now())
+
+	if i.preparedStatements.selectWayFromID == nil {
+		return nil, fmt.Errorf("statements not prepared: you need to call Init() before you can call SelectWayFromID()")
+	}
+
+	rows, err := i.preparedStatements.selectWayFromID.Query(wayID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying way from id: %s", err.Error())
+	}
+	defer rows.Close()
+
+	ways, err := decodeWays(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding ways: %s", err.Error())
+	}
+
+	if len(ways) == 0 {
+		return nil, fmt.Errorf("way %d not found", wayID)
+	}
+
+	return ways[0], nil
+}
+
 func (i *impl) SelectWayIDsFromNode(nodeID int64) ([]int64, error) {
+	defer database.ObserveQuery("way", "SelectWayIDsFromNode", time.Now())
+
 	rows, err := i.preparedStatements.selectWayIDsFromNodeID.Query(nodeID)
 	if err != nil {
 		return nil, fmt.Errorf("error while querying ways from node: %s", err.Error())
@@ -217,6 +433,8 @@ func (i *impl) SelectWayIDsFromNode(nodeID int64) ([]int64, error) {
 }
 
 func (i *impl) SelectWaysFromNode(nodeID int64) ([]*way.Way, error) {
+	defer database.ObserveQuery("way", "SelectWaysFromNode", time.Now())
+
 	rows, err := i.preparedStatements.selectWaysFromNodeID.Query(nodeID)
 	if err != nil {
 		return nil, fmt.Errorf("error while querying ways from node: %s", err.Error())
@@ -232,6 +450,8 @@ func (i *impl) SelectWaysFromNode(nodeID int64) ([]*way.Way, error) {
 }
 
 func (i *impl) SelectWaysFromTwoNodeIDs(nodeID1 int64, nodeID2 int64) ([]*way.Way, error) {
+	defer database.ObserveQuery("way", "SelectWaysFromTwoNodeIDs", time.Now())
+
 	rows, err := i.preparedStatements.selectWaysFromTwoNodeIDs.Query(nodeID1, nodeID2)
 	if err != nil {
 		return nil, fmt.Errorf("error while querying ways from two nodes: %s", err.Error())
@@ -276,3 +496,44 @@ func (i *impl) UpdateCrossings() error {
 
 	return nil
 }
+
+func (i *impl) UpdateCrossingsInRange(fromRowID int64, toRowID int64) error {
+	if i.preparedStatements.updateCrossingsInRange == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call UpdateCrossingsInRange()")
+	}
+
+	_, err := i.preparedStatements.updateCrossingsInRange.Exec(fromRowID, toRowID)
+	if err != nil {
+		return fmt.Errorf("error while updating crossings: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) UpdateCrossingsOfNodes(nodeIDs []int64) error {
+	if i.preparedStatements.updateCrossingsOfNode == nil {
+		return fmt.Errorf("statements not prepared: you need to call Init() before you can call UpdateCrossingsOfNodes()")
+	}
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %s", err.Error())
+	}
+
+	updateCrossingsOfNode := tx.Stmt(i.preparedStatements.updateCrossingsOfNode)
+
+	for _, nodeID := range nodeIDs {
+		_, err = updateCrossingsOfNode.Exec(nodeID)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("error while updating crossings of node %d: %s", nodeID, err.Error())
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing transaction: %s", err.Error())
+	}
+
+	return nil
+}
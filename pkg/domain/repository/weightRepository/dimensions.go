@@ -0,0 +1,110 @@
+package weightRepository
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VehicleDimensions are the dimensions of a vehicle in meters and metric tonnes, zero values are not checked
+type VehicleDimensions struct {
+	Height   float64
+	Width    float64
+	Length   float64
+	Weight   float64
+	AxleLoad float64
+}
+
+// Vehicle is a profile together with the dimensions of the concrete vehicle of a request
+type Vehicle struct {
+	Profile    *Profile
+	Dimensions VehicleDimensions
+}
+
+var (
+	metricRegex   = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*(m|t|kg|lbs|st)?$`)
+	imperialRegex = regexp.MustCompile(`^([0-9]+)'\s*(?:([0-9]+(?:\.[0-9]+)?)")?$`)
+)
+
+const (
+	meterPerFoot  = 0.3048
+	meterPerInch  = 0.0254
+	tonnesPerKg   = 0.001
+	tonnesPerLbs  = 0.000453592
+	tonnesPerShTn = 0.907185
+)
+
+// isWithinLimits checks the maxheight, maxwidth, maxlength, maxweight and maxaxleload tags against the dimensions
+func (d VehicleDimensions) isWithinLimits(tags map[string]string) bool {
+	limits := []struct {
+		tag   string
+		value float64
+	}{
+		{"maxheight", d.Height},
+		{"maxwidth", d.Width},
+		{"maxlength", d.Length},
+		{"maxweight", d.Weight},
+		{"maxaxleload", d.AxleLoad},
+	}
+
+	for _, limit := range limits {
+		if limit.value <= 0 {
+			continue
+		}
+
+		value, ok := tags[limit.tag]
+		if !ok {
+			continue
+		}
+
+		maximum, ok := parseLimit(value)
+		if ok && maximum < limit.value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseLimit parses OSM length and weight values into meters or tonnes,
+// values like "none", "default" or "below_default" can not be checked and return false
+func parseLimit(value string) (float64, bool) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, ",", "."))
+
+	if match := metricRegex.FindStringSubmatch(value); match != nil {
+		number, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, false
+		}
+
+		switch match[2] {
+		case "kg":
+			return number * tonnesPerKg, true
+		case "lbs":
+			return number * tonnesPerLbs, true
+		case "st":
+			return number * tonnesPerShTn, true
+		default:
+			return number, true
+		}
+	}
+
+	if match := imperialRegex.FindStringSubmatch(value); match != nil {
+		feet, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, false
+		}
+
+		inches := 0.0
+		if match[2] != "" {
+			inches, err = strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				return 0, false
+			}
+		}
+
+		return feet*meterPerFoot + inches*meterPerInch, true
+	}
+
+	return 0, false
+}
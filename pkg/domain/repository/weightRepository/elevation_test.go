@@ -0,0 +1,104 @@
+package weightRepository_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/crossing"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"io"
+	"math"
+	"testing"
+)
+
+// wayWeights returns the weights from the first to the last node and back on a way with three nodes,
+// the middle node has elevation middle, NaN marks an unknown elevation
+func wayWeights(t *testing.T, repository weightRepository.WeightRepository, profileName string, first, middle, last float64) (float64, float64) {
+	t.Helper()
+
+	profile, err := repository.GetProfile(profileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	nodes := []*crossing.Crossing{
+		{Node: node.Node{OsmID: 1, Lat: 48.0, Lon: 11.0}, IsCrossing: true},
+		{Node: node.Node{OsmID: 2, Lat: 48.001, Lon: 11.0}},
+		{Node: node.Node{OsmID: 3, Lat: 48.002, Lon: 11.0}, IsCrossing: true},
+	}
+	for index, ele := range []float64{first, middle, last} {
+		nodes[index].SetEle(ele)
+	}
+
+	over := way.Way{OsmID: 10, Tags: map[string]string{"highway": "residential"}, Nodes: []int64{1, 2, 3}}
+	vehicle := weightRepository.Vehicle{Profile: profile}
+
+	forward := repository.CalculateWeights(nil, nodes[0], &over, nodes, node.Node{}, vehicle)
+	backward := repository.CalculateWeights(nil, nodes[2], &over, nodes, node.Node{}, vehicle)
+	return forward[3], backward[1]
+}
+
+func TestElevationTime(t *testing.T) {
+	profiles, err := weightRepository.NewProfiles(map[string]*config.ProfileConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	repository := weightRepository.New(profiles, logging.New(logging.LevelError, io.Discard))
+
+	unknown := math.NaN()
+	tests := []struct {
+		name                string
+		profile             string
+		first, middle, last float64
+		uphill, downhill    func(flat float64) float64
+	}{
+		{
+			"bike climbs slower and rolls faster", "bike", 100, 110, 120,
+			func(flat float64) float64 { return flat + 20*8 },
+			func(flat float64) float64 { return math.Max(flat-20*2, flat*0.6) },
+		},
+		{
+			"pedestrian on a gentle slope", "pedestrian", 100, 105, 110,
+			func(flat float64) float64 { return flat + 10*6 },
+			func(flat float64) float64 { return math.Max(flat-10*2, flat*0.75) },
+		},
+		{
+			"pedestrian is slowed down by a steep descent", "pedestrian", 100, 130, 160,
+			func(flat float64) float64 { return flat + 60*6 },
+			func(flat float64) float64 { return flat + 60*2 },
+		},
+		{
+			"car is not affected", "car", 100, 110, 120,
+			func(flat float64) float64 { return flat },
+			func(flat float64) float64 { return flat },
+		},
+		{
+			"unknown elevation of the middle node", "bike", 100, unknown, 120,
+			func(flat float64) float64 { return flat },
+			func(flat float64) float64 { return flat },
+		},
+		{
+			"zero elevation is known", "bike", 0, 0, 10,
+			func(flat float64) float64 { return flat + 10*8 },
+			func(flat float64) float64 { return math.Max(flat-10*2, flat*0.6) },
+		},
+	}
+
+	for _, test := range tests {
+		flatForward, flatBackward := wayWeights(t, repository, test.profile, unknown, unknown, unknown)
+		if flatForward != flatBackward {
+			t.Fatalf("%s: flat weights differ: %f and %f", test.name, flatForward, flatBackward)
+		}
+
+		// the way goes uphill from the first to the last node, so the ascent and descent are swapped backwards
+		forward, backward := wayWeights(t, repository, test.profile, test.first, test.middle, test.last)
+		if expected := test.uphill(flatForward); math.Abs(forward-expected) > 0.0001 {
+			t.Errorf("%s: uphill weight %f, expected %f", test.name, forward, expected)
+		}
+		if expected := test.downhill(flatBackward); math.Abs(backward-expected) > 0.0001 {
+			t.Errorf("%s: downhill weight %f, expected %f", test.name, backward, expected)
+		}
+	}
+}
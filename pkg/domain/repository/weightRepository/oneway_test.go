@@ -0,0 +1,66 @@
+package weightRepository_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/crossing"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"io"
+	"testing"
+)
+
+// reachableNodes returns the number of nodes of a way with three nodes, that the profile can reach from the middle
+func reachableNodes(t *testing.T, repository weightRepository.WeightRepository, profileName string, tags map[string]string) int {
+	t.Helper()
+
+	profile, err := repository.GetProfile(profileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	nodes := []*crossing.Crossing{
+		{Node: node.Node{OsmID: 1, Lat: 48.0, Lon: 11.0}, IsCrossing: true},
+		{Node: node.Node{OsmID: 2, Lat: 48.001, Lon: 11.0}},
+		{Node: node.Node{OsmID: 3, Lat: 48.002, Lon: 11.0}, IsCrossing: true},
+	}
+
+	over := way.Way{OsmID: 10, Tags: tags, Nodes: []int64{1, 2, 3}}
+	return len(repository.CutPathNodes(nodes[1], &over, nodes, profile))
+}
+
+func TestOnewayPerProfile(t *testing.T) {
+	profiles, err := weightRepository.NewProfiles(map[string]*config.ProfileConfig{
+		"scooter": {Vehicle: "bike", Access: &config.AccessConfig{OnewayTags: []string{"oneway"}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	repository := weightRepository.New(profiles, logging.New(logging.LevelError, io.Discard))
+
+	tests := []struct {
+		profile  string
+		tags     map[string]string
+		expected int
+	}{
+		{"car", map[string]string{"highway": "residential", "oneway": "yes"}, 2},
+		{"car", map[string]string{"highway": "residential", "oneway": "-1"}, 2},
+		{"car", map[string]string{"highway": "residential", "junction": "roundabout"}, 2},
+		{"car", map[string]string{"highway": "residential", "oneway": "yes", "oneway:bicycle": "no"}, 2},
+		{"bike", map[string]string{"highway": "residential", "oneway": "yes"}, 2},
+		{"bike", map[string]string{"highway": "residential", "oneway": "yes", "oneway:bicycle": "no"}, 3},
+		{"bike", map[string]string{"highway": "residential", "oneway:bicycle": "yes"}, 2},
+		{"pedestrian", map[string]string{"highway": "residential", "oneway": "yes"}, 3},
+		{"pedestrian", map[string]string{"highway": "residential", "junction": "roundabout"}, 3},
+		{"pedestrian", map[string]string{"highway": "footway", "oneway:foot": "yes"}, 2},
+		{"scooter", map[string]string{"highway": "residential", "oneway": "yes", "oneway:bicycle": "no"}, 2},
+	}
+
+	for _, test := range tests {
+		if actual := reachableNodes(t, repository, test.profile, test.tags); actual != test.expected {
+			t.Errorf("%s on %v: %d reachable nodes, expected %d", test.profile, test.tags, actual, test.expected)
+		}
+	}
+}
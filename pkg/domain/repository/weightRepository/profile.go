@@ -0,0 +1,457 @@
+package weightRepository
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"math"
+	"slices"
+	"sort"
+)
+
+const DefaultProfileName = "car"
+
+// Profile describes how a vehicle uses the road network. The built-in profiles car, bike, pedestrian and truck
+// can be overwritten and extended by the profiles of the configuration file.
+type Profile struct {
+	Name        string
+	VehicleType VehicleType
+	MaxSpeed    float64
+
+	highwaySpeeds   map[string]float64
+	priorities      []priority
+	roadTypes       map[roadType]bool
+	allowedHighways map[string]bool
+	deniedHighways  map[string]bool
+	accessTags      []string
+	onewayTags      []string
+	turnPenalties   turnPenalties
+}
+
+type priority struct {
+	tag    string
+	values map[string]bool
+	factor float64
+}
+
+type turnPenalties struct {
+	left  float64
+	right float64
+	uturn float64
+}
+
+var deniedAccessValues = map[string]bool{
+	"no":           true,
+	"private":      true,
+	"agricultural": true,
+	"forestry":     true,
+	"use_sidepath": true,
+}
+
+var allowedAccessValues = map[string]bool{
+	"yes":        true,
+	"designated": true,
+	"permissive": true,
+}
+
+// Access describes if a vehicle may use a way
+type Access int
+
+const (
+	AccessDenied Access = iota
+	AccessAllowed
+	// AccessDestination allows the way only to reach or leave a destination, e.g. hgv=destination
+	AccessDestination
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessAllowed:
+		return "yes"
+	case AccessDestination:
+		return "destination"
+	default:
+		return "no"
+	}
+}
+
+func defaultProfiles() map[string]*Profile {
+	return map[string]*Profile{
+		"car": {
+			Name:        "car",
+			VehicleType: Car,
+			MaxSpeed:    maxVehicleTypeSpeed[Car],
+			roadTypes:   roadTypeSet(livingStreet, cycleStreet, urban, rural, ruralDual, motorway),
+			accessTags:  []string{"access", "vehicle", "motor_vehicle", "motorcar"},
+			onewayTags:  vehicleOnewayTags,
+			turnPenalties: turnPenalties{
+				left:  15,
+				right: 10,
+				uturn: 30,
+			},
+		},
+		"bike": {
+			Name:        "bike",
+			VehicleType: Bike,
+			MaxSpeed:    maxVehicleTypeSpeed[Bike],
+			roadTypes:   roadTypeSet(unknown, livingStreet, cycleStreet, urban, rural),
+			accessTags:  []string{"access", "vehicle", "bicycle"},
+			onewayTags:  []string{"oneway", "oneway:bicycle"},
+		},
+		"pedestrian": {
+			Name:        "pedestrian",
+			VehicleType: Pedestrian,
+			MaxSpeed:    maxVehicleTypeSpeed[Pedestrian],
+			roadTypes:   roadTypeSet(unknown, livingStreet, cycleStreet, urban),
+			accessTags:  []string{"access", "foot"},
+			onewayTags:  []string{"oneway:foot"},
+		},
+		"truck": {
+			Name:        "truck",
+			VehicleType: Truck,
+			MaxSpeed:    maxVehicleTypeSpeed[Truck],
+			roadTypes:   roadTypeSet(livingStreet, cycleStreet, urban, rural, ruralDual, motorway),
+			accessTags:  []string{"access", "vehicle", "motor_vehicle", "hgv"},
+			onewayTags:  vehicleOnewayTags,
+			turnPenalties: turnPenalties{
+				left:  30,
+				right: 20,
+				uturn: 90,
+			},
+		},
+	}
+}
+
+func roadTypeSet(types ...roadType) map[roadType]bool {
+	out := make(map[roadType]bool, len(types))
+	for _, t := range types {
+		out[t] = true
+	}
+	return out
+}
+
+func stringSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+
+	out := make(map[string]bool, len(values))
+	for _, value := range values {
+		out[value] = true
+	}
+	return out
+}
+
+// NewProfiles validates the configured profiles and merges them with the built-in profiles
+func NewProfiles(profileConfigs map[string]*config.ProfileConfig) (map[string]*Profile, error) {
+	profiles := defaultProfiles()
+
+	for name, profileConfig := range profileConfigs {
+		profile, err := newProfile(name, profileConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile \"%s\": %s", name, err.Error())
+		}
+
+		profiles[name] = profile
+	}
+
+	return profiles, nil
+}
+
+func newProfile(name string, profileConfig *config.ProfileConfig) (*Profile, error) {
+	if name == "" {
+		return nil, fmt.Errorf("profile name must not be empty")
+	}
+
+	if profileConfig == nil {
+		return nil, fmt.Errorf("no profile config provided")
+	}
+
+	vehicleType, err := parseVehicleType(profileConfig.Vehicle)
+	if err != nil {
+		return nil, err
+	}
+
+	// a configured profile starts with the defaults of its vehicle type
+	profile := *defaultProfiles()[vehicleType.String()]
+	profile.Name = name
+
+	if profileConfig.MaxSpeed < 0 {
+		return nil, fmt.Errorf("maxSpeed must be positive, got %f", profileConfig.MaxSpeed)
+	}
+	if profileConfig.MaxSpeed > 0 {
+		profile.MaxSpeed = profileConfig.MaxSpeed
+	}
+
+	if len(profileConfig.HighwaySpeeds) > 0 {
+		profile.highwaySpeeds = make(map[string]float64, len(profileConfig.HighwaySpeeds))
+		for highway, speed := range profileConfig.HighwaySpeeds {
+			if speed <= 0 {
+				return nil, fmt.Errorf("speed for highway \"%s\" must be positive, got %f", highway, speed)
+			}
+			profile.highwaySpeeds[highway] = speed
+		}
+	}
+
+	for index, priorityConfig := range profileConfig.Priorities {
+		if priorityConfig.Tag == "" {
+			return nil, fmt.Errorf("priorities[%d]: tag must not be empty", index)
+		}
+
+		if priorityConfig.Factor <= 0 {
+			return nil, fmt.Errorf("priorities[%d]: factor must be positive, got %f", index, priorityConfig.Factor)
+		}
+
+		profile.priorities = append(profile.priorities, priority{
+			tag:    priorityConfig.Tag,
+			values: stringSet(priorityConfig.Values),
+			factor: priorityConfig.Factor,
+		})
+	}
+
+	if access := profileConfig.Access; access != nil {
+		if len(access.RoadTypes) > 0 {
+			profile.roadTypes = make(map[roadType]bool, len(access.RoadTypes))
+			for _, name := range access.RoadTypes {
+				rt, err := parseRoadType(name)
+				if err != nil {
+					return nil, err
+				}
+				profile.roadTypes[rt] = true
+			}
+		}
+
+		profile.allowedHighways = stringSet(access.AllowedHighways)
+		profile.deniedHighways = stringSet(access.DeniedHighways)
+
+		if len(access.AccessTags) > 0 {
+			profile.accessTags = access.AccessTags
+		}
+
+		if len(access.OnewayTags) > 0 {
+			profile.onewayTags = access.OnewayTags
+		}
+	}
+
+	if penalties := profileConfig.TurnPenalties; penalties != nil {
+		if penalties.Left < 0 || penalties.Right < 0 || penalties.UTurn < 0 {
+			return nil, fmt.Errorf("turn penalties must not be negative")
+		}
+
+		profile.turnPenalties = turnPenalties{
+			left:  penalties.Left,
+			right: penalties.Right,
+			uturn: penalties.UTurn,
+		}
+	}
+
+	return &profile, nil
+}
+
+func profileNames(profiles map[string]*Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *Profile) wayAccess(way way.Way, dimensions VehicleDimensions) Access {
+	highway, ok := way.Tags["highway"]
+	if !ok {
+		return AccessDenied
+	}
+
+	if p.deniedHighways[highway] {
+		return AccessDenied
+	}
+
+	if p.allowedHighways != nil && !p.allowedHighways[highway] {
+		return AccessDenied
+	}
+
+	if !dimensions.isWithinLimits(way.Tags) {
+		return AccessDenied
+	}
+
+	access, explicit := p.tagAccess(way.Tags)
+	if access == AccessDenied {
+		return AccessDenied
+	}
+
+	// an explicit permission overrides the road type restrictions
+	if !explicit && !p.roadTypes[getRoadType(way)] {
+		return AccessDenied
+	}
+
+	return access
+}
+
+// isNodePassable checks barriers and restrictions tagged on a node of a way
+func (p *Profile) isNodePassable(n node.Node, dimensions VehicleDimensions) bool {
+	if len(n.Tags) == 0 {
+		return true
+	}
+
+	access, _ := p.tagAccess(n.Tags)
+	return access != AccessDenied && dimensions.isWithinLimits(n.Tags)
+}
+
+// tagAccess evaluates the access tags of the profile, the most specific tag decides.
+// explicit is true, if a tag more specific than the generic access tag decided.
+func (p *Profile) tagAccess(tags map[string]string) (access Access, explicit bool) {
+	for index := len(p.accessTags) - 1; index >= 0; index-- {
+		value, ok := tags[p.accessTags[index]]
+		if !ok {
+			continue
+		}
+
+		if deniedAccessValues[value] {
+			return AccessDenied, true
+		}
+
+		if value == "destination" {
+			return AccessDestination, index > 0
+		}
+
+		if allowedAccessValues[value] {
+			return AccessAllowed, index > 0
+		}
+
+		break
+	}
+
+	return AccessAllowed, false
+}
+
+// vehicleOnewayTags are the oneway tags of the motor vehicles, they are also used for the oneway attribute of a way
+var vehicleOnewayTags = []string{"oneway"}
+
+// onewayDirection returns 1 for ways, that the profile may only use in their direction, -1 for reversed oneways and 0 otherwise
+func (p *Profile) onewayDirection(way way.Way) int {
+	return onewayDirection(way, p.onewayTags)
+}
+
+// onewayDirection evaluates the oneway tags like the access tags, the most specific tag decides. Roundabouts are
+// oneways for all profiles, that consider the generic oneway tag, pedestrians may walk around them in both directions.
+func onewayDirection(way way.Way, onewayTags []string) int {
+	for index := len(onewayTags) - 1; index >= 0; index-- {
+		switch way.Tags[onewayTags[index]] {
+		case "yes", "true", "1":
+			return 1
+		case "-1", "reverse":
+			return -1
+		case "no", "false", "0":
+			return 0
+		}
+	}
+
+	if !slices.Contains(onewayTags, "oneway") {
+		return 0
+	}
+
+	if j, ok := way.Tags["junction"]; ok && (j == "roundabout" || j == "circular") {
+		return 1
+	}
+
+	return 0
+}
+
+func (p *Profile) maximumWayFactor() float64 {
+	maxPriority := 1.0
+	for _, prio := range p.priorities {
+		if prio.factor > 1 {
+			maxPriority *= prio.factor
+		}
+	}
+
+	return 1 / (p.MaxSpeed / 3.6) / maxPriority
+}
+
+func (p *Profile) calcWaySpeed(way way.Way) float64 {
+	speed, ok := p.highwaySpeeds[way.Tags["highway"]]
+	if !ok {
+		speed = calcMaxWaySpeed(way)
+	} else if v, ok := way.Tags["maxspeed"]; ok && v != "" {
+		speed = math.Min(speed, calcMaxSpeed(v))
+	}
+
+	if p.VehicleType == Truck {
+		if v, ok := way.Tags["maxspeed:hgv"]; ok && v != "" {
+			speed = math.Min(speed, calcMaxSpeed(v))
+		}
+	}
+
+	return speed
+}
+
+func (p *Profile) calcPriority(way way.Way) float64 {
+	out := 1.0
+	for _, prio := range p.priorities {
+		value, ok := way.Tags[prio.tag]
+		if !ok {
+			continue
+		}
+
+		if prio.values != nil && !prio.values[value] {
+			continue
+		}
+
+		out *= prio.factor
+	}
+	return out
+}
+
+func (p *Profile) calcWayFactor(way way.Way) float64 {
+	maxWaySpeed := math.Min(p.calcWaySpeed(way), p.MaxSpeed)
+
+	return 1 / (maxWaySpeed / 3.6) / p.calcPriority(way)
+}
+
+func (p *Profile) calcCrossingFactor(prev, curr, next *node.Node) float64 {
+	if prev == nil || curr == nil || next == nil {
+		return 0
+	}
+
+	if p.turnPenalties == (turnPenalties{}) {
+		return 0
+	}
+
+	phi1 := sphericmath.CalculateBearing(
+		sphericmath.NewPoint(curr.Lat, curr.Lon),
+		sphericmath.NewPoint(prev.Lat, prev.Lon),
+	)
+
+	phi2 := sphericmath.CalculateBearing(
+		sphericmath.NewPoint(curr.Lat, curr.Lon),
+		sphericmath.NewPoint(next.Lat, next.Lon),
+	)
+
+	if math.IsNaN(phi1) || math.IsNaN(phi2) {
+		return 0
+	}
+
+	phi := phi2 - phi1 + math.Pi
+	phi = math.Mod(phi+math.Pi, 2*math.Pi) - math.Pi
+
+	// straight
+	if math.Abs(phi) < (math.Pi / 2) {
+		return 0
+	}
+
+	// uturn
+	if math.Abs(phi) > (math.Pi - tenDegree) {
+		return p.turnPenalties.uturn
+	}
+
+	// left
+	if phi < 0 {
+		return p.turnPenalties.left
+	}
+
+	// right
+	return p.turnPenalties.right
+}
@@ -1,6 +1,7 @@
 package weightRepository
 
 import (
+	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
 	"math"
 	"strconv"
@@ -39,6 +40,16 @@ func (r roadType) String() string {
 	}
 }
 
+func parseRoadType(name string) (roadType, error) {
+	for r := unknown; r <= motorway; r++ {
+		if r.String() == name {
+			return r, nil
+		}
+	}
+
+	return unknown, fmt.Errorf("unknown road type: \"%s\"", name)
+}
+
 var typeToMaxSpeed = map[roadType]float64{
 	unknown:      walkingSpeedBias,
 	livingStreet: walkingSpeedBias,
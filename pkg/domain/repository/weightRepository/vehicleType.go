@@ -1,9 +1,8 @@
 package weightRepository
 
 import (
-	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
-	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
 	"math"
 	"strconv"
 	"strings"
@@ -15,88 +14,49 @@ const (
 	Car VehicleType = iota
 	Bike
 	Pedestrian
+	Truck
 )
 
 var maxVehicleTypeSpeed = map[VehicleType]float64{
 	Car:        160,
 	Bike:       30,
 	Pedestrian: walkingSpeedBias,
-}
-
-func (v VehicleType) isWayTypeAllowed(way way.Way) bool {
-
-	wayRoadType := getRoadType(way)
-	switch v {
-	case Car:
-		return wayRoadType > unknown
-	case Bike:
-		return wayRoadType < ruralDual
-	case Pedestrian:
-		return wayRoadType < rural
-	default:
-		return false
-	}
-}
-
-func (v VehicleType) maxmimumWayFactor() float64 {
-	return 1 / (maxVehicleTypeSpeed[v] / 3.6)
-}
-
-func (v VehicleType) calcWayFactor(way way.Way) float64 {
-	maxWaySpeed := calcMaxWaySpeed(way)
-
-	maxWaySpeed = math.Min(maxWaySpeed, maxVehicleTypeSpeed[v])
-
-	return 1 / (maxWaySpeed / 3.6)
+	Truck:      80,
 }
 
 const (
 	tenDegree = 10 * math.Pi / 180
 )
 
-func (v VehicleType) calcCrossingFactor(prev, curr, next *node.Node) float64 {
-	if prev == nil || curr == nil || next == nil {
-		return 0
-	}
-
-	if v != Car {
-		return 0
-	}
-
-	phi1 := sphericmath.CalculateBearing(
-		sphericmath.NewPoint(curr.Lat, curr.Lon),
-		sphericmath.NewPoint(prev.Lat, prev.Lon),
-	)
-
-	phi2 := sphericmath.CalculateBearing(
-		sphericmath.NewPoint(curr.Lat, curr.Lon),
-		sphericmath.NewPoint(next.Lat, next.Lon),
-	)
-
-	if math.IsNaN(phi1) || math.IsNaN(phi2) {
-		return 0
-	}
-
-	phi := phi2 - phi1 + math.Pi
-	phi = math.Mod(phi+math.Pi, 2*math.Pi) - math.Pi
-
-	// straight
-	if math.Abs(phi) < (math.Pi / 2) {
-		return 0
-	}
+const (
+	bikeAscentPenalty     = 8.0 // seconds per meter of ascent, a cyclist climbs approx. 450 m/h
+	bikeDescentBonus      = 2.0 // seconds per meter of descent
+	bikeMinimumTimeFactor = 0.6
+
+	pedestrianAscentPenalty     = 6.0 // seconds per meter of ascent, Naismith's rule: 1h per 600m
+	pedestrianDescentBonus      = 2.0 // seconds per meter of descent on gentle slopes (Langmuir)
+	pedestrianSteepDescentSlope = 0.2 // approx. 12 degrees, steeper descents slow pedestrians down
+	pedestrianMinimumTimeFactor = 0.75
+)
 
-	// uturn
-	if math.Abs(phi) > (math.Pi - tenDegree) {
-		return 30
-	}
+// calcElevationTime adjusts the travel time on a segment for its ascent and descent.
+// Cars and trucks are not affected, as their speed is limited by the road and not by the slope.
+func (v VehicleType) calcElevationTime(baseTime float64, segment pathSegment) float64 {
+	switch v {
+	case Bike:
+		time := baseTime + segment.ascent*bikeAscentPenalty - segment.descent*bikeDescentBonus
+		return math.Max(time, baseTime*bikeMinimumTimeFactor)
+	case Pedestrian:
+		descentTime := -segment.descent * pedestrianDescentBonus
+		if segment.length > 0 && segment.descent/segment.length > pedestrianSteepDescentSlope {
+			descentTime = segment.descent * pedestrianDescentBonus
+		}
 
-	// left
-	if phi < 0 {
-		return 15
+		time := baseTime + segment.ascent*pedestrianAscentPenalty + descentTime
+		return math.Max(time, baseTime*pedestrianMinimumTimeFactor)
+	default:
+		return baseTime
 	}
-
-	// right
-	return 10
 }
 
 func (v VehicleType) String() string {
@@ -107,11 +67,23 @@ func (v VehicleType) String() string {
 		return "bike"
 	case Pedestrian:
 		return "pedestrian"
+	case Truck:
+		return "truck"
 	default:
 		return "unknown"
 	}
 }
 
+func parseVehicleType(name string) (VehicleType, error) {
+	for _, v := range []VehicleType{Car, Bike, Pedestrian, Truck} {
+		if v.String() == name {
+			return v, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown vehicle type: \"%s\"", name)
+}
+
 func calcMaxWaySpeed(way way.Way) float64 {
 	speedBias := minimumSpeedBias
 	if v, ok := way.Tags["maxspeed"]; ok && v != "" {
@@ -1,6 +1,7 @@
 package weightRepository
 
 import (
+	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/crossing"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
@@ -19,32 +20,77 @@ const (
 )
 
 type WeightRepository interface {
-	IsWayAllowed(way way.Way, vehicleType VehicleType) bool
-	MaximumWayFactor(vehicleType VehicleType) float64
-	CalculateWeights(prevNode *node.Node, from *crossing.Crossing, over *way.Way, to []*crossing.Crossing, end node.Node, vehicleType VehicleType) map[int64]float64
-	CalculateDistances(from *node.Node, over *way.Way, pathNodes []*crossing.Crossing, end *node.Node) float64
-	CutPathNodes(from *crossing.Crossing, over *way.Way, pathNodes []*crossing.Crossing) []*crossing.Crossing
+	GetProfile(name string) (*Profile, error)
+	ProfileNames() []string
+
+	GetWayAccess(way way.Way, vehicle Vehicle) Access
+	GetWayAttributes(way way.Way) WayAttributes
+	MaximumWayFactor(profile *Profile) float64
+	CalculateWeights(prevNode *node.Node, from *crossing.Crossing, over *way.Way, to []*crossing.Crossing, end node.Node, vehicle Vehicle) map[int64]float64
+	CalculateDistances(from *node.Node, over *way.Way, pathNodes []*crossing.Crossing, end *node.Node, profile *Profile) float64
+	// CutPathNodes cuts the nodes of the way to the part, that the profile can reach from the from node without crossing
+	CutPathNodes(from *crossing.Crossing, over *way.Way, pathNodes []*crossing.Crossing, profile *Profile) []*crossing.Crossing
+}
+
+// WayAttributes are the values, that the router derives from the tags of a way
+type WayAttributes struct {
+	RoadType string
+	MaxSpeed float64 // km/h
+	Oneway   int     // 1 in the direction of the way, -1 against it, 0 in both directions, for motor vehicles
+
+	// Access is the access of every profile without vehicle dimensions
+	Access map[string]Access
 }
 
 type impl struct {
-	logger logging.Logger
+	logger   logging.Logger
+	profiles map[string]*Profile
 }
 
-func New(logger logging.Logger) WeightRepository {
+func New(profiles map[string]*Profile, logger logging.Logger) WeightRepository {
 	return &impl{
-		logger: logger,
+		logger:   logger,
+		profiles: profiles,
+	}
+}
+
+func (i *impl) GetProfile(name string) (*Profile, error) {
+	profile, ok := i.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile \"%s\", available profiles: %v", name, i.ProfileNames())
 	}
+
+	return profile, nil
+}
+
+func (i *impl) ProfileNames() []string {
+	return profileNames(i.profiles)
 }
 
-func (i *impl) IsWayAllowed(way way.Way, vehicleType VehicleType) bool {
-	return vehicleType.isWayTypeAllowed(way)
+func (i *impl) GetWayAccess(way way.Way, vehicle Vehicle) Access {
+	return vehicle.Profile.wayAccess(way, vehicle.Dimensions)
+}
+
+func (i *impl) GetWayAttributes(way way.Way) WayAttributes {
+	out := WayAttributes{
+		RoadType: getRoadType(way).String(),
+		MaxSpeed: calcMaxWaySpeed(way),
+		Oneway:   onewayDirection(way, vehicleOnewayTags),
+		Access:   make(map[string]Access, len(i.profiles)),
+	}
+
+	for name, profile := range i.profiles {
+		out.Access[name] = profile.wayAccess(way, VehicleDimensions{})
+	}
+
+	return out
 }
 
-func (i *impl) MaximumWayFactor(vehicleType VehicleType) float64 {
-	return vehicleType.maxmimumWayFactor()
+func (i *impl) MaximumWayFactor(profile *Profile) float64 {
+	return profile.maximumWayFactor()
 }
 
-func (i *impl) CalculateWeights(prevNode *node.Node, from *crossing.Crossing, over *way.Way, to []*crossing.Crossing, end node.Node, vehicleType VehicleType) map[int64]float64 {
+func (i *impl) CalculateWeights(prevNode *node.Node, from *crossing.Crossing, over *way.Way, to []*crossing.Crossing, end node.Node, vehicle Vehicle) map[int64]float64 {
 	if from == nil {
 		i.logger.Error().Msg("from node is nil")
 		return make(map[int64]float64)
@@ -55,31 +101,39 @@ func (i *impl) CalculateWeights(prevNode *node.Node, from *crossing.Crossing, ov
 		return make(map[int64]float64)
 	}
 
-	to = i.CutPathNodes(from, over, to)
+	to = i.CutPathNodes(from, over, to, vehicle.Profile)
 	if to == nil {
 		i.logger.Error().Msg("to nodes are nil after cutting")
 		return make(map[int64]float64)
 	}
 
-	distancesToCrossings := i.calculateDistances(*from, to, end)
+	profile := vehicle.Profile
+	isBlocked := func(n *crossing.Crossing) bool {
+		return !profile.isNodePassable(n.Node, vehicle.Dimensions)
+	}
+
+	distancesToCrossings := i.calculateDistances(*from, to, end, isBlocked)
 
 	out := make(map[int64]float64)
-	for crossing, length := range distancesToCrossings {
-		out[crossing.OsmID] = length*
-			vehicleType.calcWayFactor(*over) +
-			vehicleType.calcCrossingFactor(prevNode, &from.Node, &crossing.Node)
+	for crossing, segment := range distancesToCrossings {
+		if segment.blocked > 0 {
+			continue
+		}
+
+		out[crossing.OsmID] = profile.VehicleType.calcElevationTime(segment.length*profile.calcWayFactor(*over), segment) +
+			profile.calcCrossingFactor(prevNode, &from.Node, &crossing.Node)
 	}
 
 	return out
 }
 
-func (i *impl) CutPathNodes(from *crossing.Crossing, over *way.Way, pathNodes []*crossing.Crossing) []*crossing.Crossing {
-	if from == nil || over == nil || pathNodes == nil {
-		i.logger.Error().Msg("from, over or pathNodes are nil")
+func (i *impl) CutPathNodes(from *crossing.Crossing, over *way.Way, pathNodes []*crossing.Crossing, profile *Profile) []*crossing.Crossing {
+	if from == nil || over == nil || pathNodes == nil || profile == nil {
+		i.logger.Error().Msg("from, over, pathNodes or profile are nil")
 		return nil
 	}
 
-	pathNodes = i.cutOneway(*from, *over, pathNodes)
+	pathNodes = i.cutOneway(*from, *over, pathNodes, profile)
 	if pathNodes == nil {
 		i.logger.Error().Msg("to nodes are nil after cutting oneway")
 		return nil
@@ -94,7 +148,7 @@ func (i *impl) CutPathNodes(from *crossing.Crossing, over *way.Way, pathNodes []
 	return pathNodes
 }
 
-func (i *impl) cutOneway(from crossing.Crossing, over way.Way, to []*crossing.Crossing) []*crossing.Crossing {
+func (i *impl) cutOneway(from crossing.Crossing, over way.Way, to []*crossing.Crossing, profile *Profile) []*crossing.Crossing {
 	fromIndex := -1
 	for i, n := range to {
 		if n.OsmID == from.OsmID {
@@ -107,21 +161,14 @@ func (i *impl) cutOneway(from crossing.Crossing, over way.Way, to []*crossing.Cr
 		return nil
 	}
 
-	if oneway, ok := over.Tags["oneway"]; ok && !(oneway == "no" || oneway == "false" || oneway == "0") {
-		if oneway == "yes" || oneway == "true" || oneway == "1" {
-			return to[fromIndex:]
-		}
-
-		if oneway == "-1" || oneway == "reverse" {
-			return to[:fromIndex+1]
-		}
-	}
-
-	if j, ok := over.Tags["junction"]; ok && (j == "roundabout" || j == "circular") {
+	switch profile.onewayDirection(over) {
+	case 1:
 		return to[fromIndex:]
+	case -1:
+		return to[:fromIndex+1]
+	default:
+		return to
 	}
-
-	return to
 }
 
 func (i *impl) cutCrossing(from crossing.Crossing, to []*crossing.Crossing) []*crossing.Crossing {
@@ -157,7 +204,7 @@ func (i *impl) cutCrossing(from crossing.Crossing, to []*crossing.Crossing) []*c
 	return to[cutFrom : cutTo+1]
 }
 
-func (i *impl) CalculateDistances(from *node.Node, over *way.Way, pathNodes []*crossing.Crossing, end *node.Node) float64 {
+func (i *impl) CalculateDistances(from *node.Node, over *way.Way, pathNodes []*crossing.Crossing, end *node.Node, profile *Profile) float64 {
 	if from == nil {
 		i.logger.Error().Msg("from node is nil")
 		return math.NaN()
@@ -173,62 +220,121 @@ func (i *impl) CalculateDistances(from *node.Node, over *way.Way, pathNodes []*c
 		return math.NaN()
 	}
 
-	pathNodes = i.CutPathNodes(&crossing.Crossing{Node: *from}, over, pathNodes)
+	pathNodes = i.CutPathNodes(&crossing.Crossing{Node: *from}, over, pathNodes, profile)
 	if pathNodes == nil {
 		i.logger.Error().Msg("to nodes are nil after cutting")
 		return math.NaN()
 	}
 
-	distances := i.calculateDistances(crossing.Crossing{Node: *from}, pathNodes, *end)
+	distances := i.calculateDistances(crossing.Crossing{Node: *from}, pathNodes, *end, nil)
 
-	for n, dist := range distances {
+	for n, segment := range distances {
 		if n.OsmID == end.OsmID {
-			return dist
+			return segment.length
 		}
 	}
 
 	return math.NaN()
 }
 
-func (i *impl) calculateDistances(from crossing.Crossing, to []*crossing.Crossing, end node.Node) map[*crossing.Crossing]float64 {
-	out := make(map[*crossing.Crossing]float64)
+// pathSegment describes the travelled part of a way between two nodes
+type pathSegment struct {
+	length  float64
+	ascent  float64
+	descent float64
+	blocked int // number of impassable nodes passed or reached
+}
+
+// calculateDistances returns the segments from the from node to the ends of the way and the end node.
+// isBlocked may be nil, if no nodes are impassable.
+func (i *impl) calculateDistances(from crossing.Crossing, to []*crossing.Crossing, end node.Node, isBlocked func(n *crossing.Crossing) bool) map[*crossing.Crossing]pathSegment {
+	out := make(map[*crossing.Crossing]pathSegment)
+
+	blocked := make([]int, len(to))
+	if isBlocked != nil {
+		for index, n := range to {
+			if isBlocked(n) {
+				blocked[index] = 1
+			}
+		}
+	}
 
-	fullLength := 0.0
-	leftLength := 0.0
-	endLength := math.NaN()
+	// cumulative values along the way direction, starting at to[0]
+	cumulative := make([]pathSegment, len(to))
+	cumulative[0].blocked = blocked[0]
+	fromIndex := 0
+	endIndex := -1
 
-	prevNode := to[0]
+	for index := 1; index < len(to); index++ {
+		prevNode := to[index-1]
+		n := to[index]
 
-	for _, n := range to[1:] {
 		dist := sphericmath.CalcDistanceInMeters(
 			sphericmath.NewPoint(prevNode.Lat, prevNode.Lon),
 			sphericmath.NewPoint(n.Lat, n.Lon),
 		)
 
-		fullLength += dist
+		ascent, descent := elevationChange(prevNode.Elevation(), n.Elevation())
+
+		cumulative[index] = pathSegment{
+			length:  cumulative[index-1].length + dist,
+			ascent:  cumulative[index-1].ascent + ascent,
+			descent: cumulative[index-1].descent + descent,
+			blocked: cumulative[index-1].blocked + blocked[index],
+		}
 
 		if n.OsmID == end.OsmID {
-			endLength = fullLength
+			endIndex = index
 		}
 
 		if from.OsmID == n.OsmID {
-			leftLength = fullLength
+			fromIndex = index
 		}
-
-		prevNode = n
 	}
 
 	if to[0].OsmID != from.OsmID {
-		out[to[0]] = leftLength
+		out[to[0]] = segmentBetween(cumulative, blocked, fromIndex, 0)
 	}
 
 	if to[len(to)-1].OsmID != from.OsmID {
-		out[to[len(to)-1]] = fullLength - leftLength
+		out[to[len(to)-1]] = segmentBetween(cumulative, blocked, fromIndex, len(to)-1)
 	}
 
-	if !math.IsNaN(endLength) {
-		out[&crossing.Crossing{Node: end}] = math.Abs(leftLength - endLength)
+	if endIndex != -1 {
+		out[&crossing.Crossing{Node: end}] = segmentBetween(cumulative, blocked, fromIndex, endIndex)
 	}
 
 	return out
 }
+
+// segmentBetween returns the segment travelled from index a to index b, ascent and descent swap when travelling against the way direction.
+// The node at index a is not counted as blocked, as the vehicle already stands there.
+func segmentBetween(cumulative []pathSegment, blocked []int, a int, b int) pathSegment {
+	if b >= a {
+		return pathSegment{
+			length:  cumulative[b].length - cumulative[a].length,
+			ascent:  cumulative[b].ascent - cumulative[a].ascent,
+			descent: cumulative[b].descent - cumulative[a].descent,
+			blocked: cumulative[b].blocked - cumulative[a].blocked,
+		}
+	}
+
+	return pathSegment{
+		length:  cumulative[a].length - cumulative[b].length,
+		ascent:  cumulative[a].descent - cumulative[b].descent,
+		descent: cumulative[a].ascent - cumulative[b].ascent,
+		blocked: cumulative[a].blocked - blocked[a] - cumulative[b].blocked + blocked[b],
+	}
+}
+
+func elevationChange(fromEle float64, toEle float64) (ascent float64, descent float64) {
+	if math.IsNaN(fromEle) || math.IsNaN(toEle) {
+		return 0, 0
+	}
+
+	if toEle > fromEle {
+		return toEle - fromEle, 0
+	}
+
+	return 0, fromEle - toEle
+}
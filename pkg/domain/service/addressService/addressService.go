@@ -4,11 +4,12 @@ import (
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/addressRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
 	"regexp"
 )
 
-const bulkInsertBufferSize = 2 << 9
+const bulkInsertBufferSize = 1 << 14
 
 type AddressService interface {
 	InsertAddress(address address.Address) error
@@ -16,6 +17,12 @@ type AddressService interface {
 	InsertAddressBulk(address address.Address) error
 	CommitBulkInsert() error
 
+	// ReplaceAddress removes the address of the osm element and inserts the new address, if it is not nil
+	ReplaceAddress(osmType address.OsmType, osmID int64, address *address.Address) error
+
+	SelectLastRowID() (int64, error)
+	DeleteAfterRowID(rowID int64) error
+
 	GetSearchResultsFromAddress(address string) ([]*address.Address, error)
 	SelectAddressByID(id int64) (*address.Address, error)
 }
@@ -23,12 +30,14 @@ type AddressService interface {
 type impl struct {
 	logger            logging.Logger
 	addressRepository addressRepository.AddressRepository
+	writer            database.Writer
 	bulkInsertBuffer  []address.Address
 }
 
-func New(addressRepository addressRepository.AddressRepository, logger logging.Logger) AddressService {
+func New(addressRepository addressRepository.AddressRepository, writer database.Writer, logger logging.Logger) AddressService {
 	return &impl{
 		addressRepository: addressRepository,
+		writer:            writer,
 		logger:            logger,
 	}
 }
@@ -39,25 +48,63 @@ func (i *impl) InsertAddress(address address.Address) error {
 
 func (i *impl) InsertAddressBulk(n address.Address) error {
 	if len(i.bulkInsertBuffer) == bulkInsertBufferSize {
-		err := i.CommitBulkInsert()
-		if err != nil {
-			return fmt.Errorf("error while committing bulk insert: %s", err.Error())
-		}
+		i.flushBulkInsert()
 	}
 
 	i.bulkInsertBuffer = append(i.bulkInsertBuffer, n)
 	return nil
 }
 
+// flushBulkInsert hands the buffer to the writer, the addresses are inserted while the next buffer is filled
+func (i *impl) flushBulkInsert() {
+	if len(i.bulkInsertBuffer) == 0 {
+		return
+	}
+
+	addresses := i.bulkInsertBuffer
+	i.bulkInsertBuffer = make([]address.Address, 0, bulkInsertBufferSize)
+
+	i.writer.Write(func() error {
+		err := i.addressRepository.InsertAddresses(addresses)
+		if err != nil {
+			return fmt.Errorf("error while inserting address: %s", err.Error())
+		}
+		return nil
+	})
+}
+
+// CommitBulkInsert inserts the buffer and waits until all buffers handed to the writer are inserted
 func (i *impl) CommitBulkInsert() error {
-	err := i.addressRepository.InsertAddresses(i.bulkInsertBuffer)
+	i.flushBulkInsert()
+
+	err := i.writer.Wait()
 	if err != nil {
-		return fmt.Errorf("error while inserting address: %s", err.Error())
+		return fmt.Errorf("error while committing bulk insert: %s", err.Error())
 	}
-	i.bulkInsertBuffer = make([]address.Address, 0, bulkInsertBufferSize)
 	return nil
 }
 
+func (i *impl) ReplaceAddress(osmType address.OsmType, osmID int64, address *address.Address) error {
+	err := i.addressRepository.DeleteAddress(osmType, osmID)
+	if err != nil {
+		return fmt.Errorf("error while deleting address: %s", err.Error())
+	}
+
+	if address == nil {
+		return nil
+	}
+
+	return i.addressRepository.InsertAddress(*address)
+}
+
+func (i *impl) SelectLastRowID() (int64, error) {
+	return i.addressRepository.SelectLastRowID()
+}
+
+func (i *impl) DeleteAfterRowID(rowID int64) error {
+	return i.addressRepository.DeleteAfterRowID(rowID)
+}
+
 func (i *impl) SelectAddressByID(id int64) (*address.Address, error) {
 	return i.addressRepository.SelectAddressByID(id)
 }
@@ -0,0 +1,238 @@
+package graphService
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/closure"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/crossing"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"math"
+	"time"
+)
+
+// closureIndex looks up the closures of an edge, it is rebuilt on every change and never modified afterward
+type closureIndex struct {
+	closures  []*closure.Closure
+	byWay     map[int64][]*closure.Closure
+	byNodes   map[EdgeKey][]*closure.Closure
+	byPolygon []polygonClosure
+}
+
+type polygonClosure struct {
+	closure  *closure.Closure
+	area     sphericmath.Area
+	min, max sphericmath.Point
+}
+
+func newClosureIndex(closures []*closure.Closure, filter func(c *closure.Closure) bool) *closureIndex {
+	out := &closureIndex{
+		byWay:   make(map[int64][]*closure.Closure),
+		byNodes: make(map[EdgeKey][]*closure.Closure),
+	}
+
+	for _, c := range closures {
+		if filter != nil && !filter(c) {
+			continue
+		}
+
+		out.closures = append(out.closures, c)
+
+		if c.WayID != 0 {
+			out.byWay[c.WayID] = append(out.byWay[c.WayID], c)
+		}
+
+		if len(c.Nodes) == 2 {
+			key := NewEdgeKey(c.Nodes[0], c.Nodes[1])
+			out.byNodes[key] = append(out.byNodes[key], c)
+		}
+
+		if len(c.Polygon) > 0 {
+			area := toArea(c.Polygon)
+			min, max := area.BoundingBox()
+			out.byPolygon = append(out.byPolygon, polygonClosure{closure: c, area: area, min: min, max: max})
+		}
+	}
+
+	return out
+}
+
+func toArea(polygon geojson.Polygon) sphericmath.Area {
+	rings := make([]sphericmath.Polygon, len(polygon))
+	for index, ring := range polygon {
+		rings[index] = make(sphericmath.Polygon, len(ring))
+		for pointIndex, point := range ring {
+			rings[index][pointIndex] = sphericmath.NewPoint(point.Lon(), point.Lat())
+		}
+	}
+
+	return sphericmath.Area{
+		Outer: rings[0],
+		Holes: rings[1:],
+	}
+}
+
+// withAvoidAreas adds the areas of a single query as closures, that close all edges crossing them
+func (c *closureIndex) withAvoidAreas(areas []geojson.Polygon) *closureIndex {
+	if len(areas) == 0 {
+		return c
+	}
+
+	var closures []*closure.Closure
+	if c != nil {
+		closures = append(closures, c.closures...)
+	}
+
+	// avoided areas are closed
+	closed := 0.0
+	for _, area := range areas {
+		closures = append(closures, &closure.Closure{Polygon: area, SpeedFactor: &closed})
+	}
+
+	return newClosureIndex(closures, nil)
+}
+
+// active returns the closures that are valid at t, or nil if there are none
+func (c *closureIndex) active(t time.Time) *closureIndex {
+	if c == nil || len(c.closures) == 0 {
+		return nil
+	}
+
+	out := newClosureIndex(c.closures, func(c *closure.Closure) bool {
+		return c.IsActive(t)
+	})
+
+	if len(out.closures) == 0 {
+		return nil
+	}
+
+	return out
+}
+
+// edgeFactor returns the smallest speed factor of all closures on the segments between the indices from and to of a way,
+// 1 if the edge is not affected and 0 if it is closed
+func (c *closureIndex) edgeFactor(wayID int64, nodes []*crossing.Crossing, from int, to int) float64 {
+	if c == nil {
+		return 1
+	}
+
+	factor := 1.0
+	apply := func(closures []*closure.Closure) {
+		for _, cl := range closures {
+			if *cl.SpeedFactor < factor {
+				factor = *cl.SpeedFactor
+			}
+		}
+	}
+
+	apply(c.byWay[wayID])
+
+	if from > to {
+		from, to = to, from
+	}
+
+	for index := from; index < to && factor > 0; index++ {
+		apply(c.byNodes[NewEdgeKey(nodes[index].OsmID, nodes[index+1].OsmID)])
+
+		a := sphericmath.NewPoint(nodes[index].Lat, nodes[index].Lon)
+		b := sphericmath.NewPoint(nodes[index+1].Lat, nodes[index+1].Lon)
+		for _, pc := range c.byPolygon {
+			if *pc.closure.SpeedFactor >= factor || !pc.intersects(a, b) {
+				continue
+			}
+			factor = *pc.closure.SpeedFactor
+		}
+	}
+
+	return factor
+}
+
+func (p polygonClosure) intersects(a, b sphericmath.Point) bool {
+	if math.Max(a.Lat(), b.Lat()) < p.min.Lat() || math.Min(a.Lat(), b.Lat()) > p.max.Lat() ||
+		math.Max(a.Lon(), b.Lon()) < p.min.Lon() || math.Min(a.Lon(), b.Lon()) > p.max.Lon() {
+		return false
+	}
+	return p.area.IntersectsSegment(a, b)
+}
+
+// nearestIndex finds the position of id in the nodes of a way, that is closest to the position from
+func nearestIndex(nodes []*crossing.Crossing, id int64, from int) int {
+	out := -1
+	for index, n := range nodes {
+		if n.OsmID != id {
+			continue
+		}
+
+		if out == -1 || abs(index-from) < abs(out-from) {
+			out = index
+		}
+	}
+	return out
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func (i *impl) LoadClosures() error {
+	i.closureLock.Lock()
+	defer i.closureLock.Unlock()
+
+	closures, err := i.closureRepository.SelectClosures()
+	if err != nil {
+		return fmt.Errorf("error while selecting closures: %s", err.Error())
+	}
+
+	i.closures.Store(newClosureIndex(closures, nil))
+	return nil
+}
+
+func (i *impl) GetClosures() []*closure.Closure {
+	index := i.closures.Load()
+	if index == nil {
+		return nil
+	}
+	return index.closures
+}
+
+func (i *impl) AddClosure(c closure.Closure) (*closure.Closure, error) {
+	i.closureLock.Lock()
+	defer i.closureLock.Unlock()
+
+	id, err := i.closureRepository.InsertClosure(c)
+	if err != nil {
+		return nil, fmt.Errorf("error while inserting closure: %s", err.Error())
+	}
+	c.ID = id
+
+	closures := append([]*closure.Closure{}, i.GetClosures()...)
+	i.closures.Store(newClosureIndex(append(closures, &c), nil))
+	return &c, nil
+}
+
+// RemoveClosure returns false, if no closure with this id exists
+func (i *impl) RemoveClosure(id int64) (bool, error) {
+	i.closureLock.Lock()
+	defer i.closureLock.Unlock()
+
+	deleted, err := i.closureRepository.DeleteClosure(id)
+	if err != nil {
+		return false, fmt.Errorf("error while deleting closure: %s", err.Error())
+	}
+
+	if !deleted {
+		return false, nil
+	}
+
+	var closures []*closure.Closure
+	for _, c := range i.GetClosures() {
+		if c.ID != id {
+			closures = append(closures, c)
+		}
+	}
+
+	i.closures.Store(newClosureIndex(closures, nil))
+	return true, nil
+}
@@ -1,10 +1,13 @@
 package graphService
 
 import (
+	"errors"
 	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/closure"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/crossing"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/closureRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/crossingRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/nodeRepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/wayRepository"
@@ -14,18 +17,70 @@ import (
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
 	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	nearNodesApproxDistance = 0.001 // approx. 1km
-	defaultVehicleType      = weightRepository.Car
+	// NearNodesRadius is the distance in degrees around a position, in which near nodes are searched
+	NearNodesRadius   = 0.001
+	destinationRadius = 1000 // meters around start and end, in which destination-only ways may be used
 )
 
+// ErrNoNearNode is returned, if there is no usable node close to the requested position
+var ErrNoNearNode = errors.New("no near node found")
+
+// Query describes a single route search between two nodes
+type Query struct {
+	Vehicle weightRepository.Vehicle
+	Start   node.Node
+	End     node.Node
+
+	// Targets are further nodes, that are reached like End, e.g. by one-to-many searches
+	Targets []node.Node
+
+	// EdgePenalties multiplies the weights of edges, e.g. to avoid edges that are already used by a route
+	EdgePenalties map[EdgeKey]float64
+
+	// AvoidAreas are polygons, that may not be crossed by any edge
+	AvoidAreas []geojson.Polygon
+
+	// Stats counts the database queries of the search, may be nil
+	Stats *QueryStats
+}
+
+// EdgeKey identifies the edge between two crossings independent of the direction
+type EdgeKey struct {
+	A int64
+	B int64
+}
+
+func NewEdgeKey(a int64, b int64) EdgeKey {
+	if a > b {
+		a, b = b, a
+	}
+	return EdgeKey{A: a, B: b}
+}
+
 type GraphService interface {
-	GetEdges(end node.Node) func(prevId, id int64) map[int64]float64
-	GetHeuristic(end node.Node) func(id int64) float64
-	CalculatePathInformation(path []int64) (way []geojson.Point, lengthInMeters float64, err error)
-	GetNearestNode(lat float64, lon float64) (*node.Node, error)
+	GetProfile(name string) (*weightRepository.Profile, error)
+	GetEdges(query Query) func(prevId, id int64) map[int64]float64
+	// GetReverseEdges returns the edges leading to a node with their weights, it searches the ways to a node backwards
+	GetReverseEdges(query Query) func(nextId, id int64) map[int64]float64
+	GetHeuristic(query Query) func(id int64) float64
+	// CalculatePathInformation follows the path along the ways, that the vehicle can use
+	CalculatePathInformation(path []int64, vehicle weightRepository.Vehicle, stats *QueryStats) (way []geojson.Point, elevations []float64, lengthInMeters float64, err error)
+	GetNearestNode(lat float64, lon float64, vehicle weightRepository.Vehicle) (*node.Node, error)
+	GetNearNodes(lat float64, lon float64, vehicle weightRepository.Vehicle, stats *QueryStats) ([]*node.Node, error)
+	GetUsableNodes(lat float64, lon float64, radius float64, vehicle weightRepository.Vehicle, stats *QueryStats) ([]*node.Node, error)
+	GetNetwork(minLat, minLon, maxLat, maxLon float64) ([]NetworkWay, error)
+
+	LoadClosures() error
+	GetClosures() []*closure.Closure
+	AddClosure(closure closure.Closure) (*closure.Closure, error)
+	RemoveClosure(id int64) (bool, error)
 }
 
 type impl struct {
@@ -33,28 +88,40 @@ type impl struct {
 	crossingRepository crossingRepository.CrossingRepository
 	wayRepository      wayRepository.WayRepository
 	weightRepository   weightRepository.WeightRepository
+	closureRepository  closureRepository.ClosureRepository
 	logger             logging.Logger
 
 	visitedNodes int
+
+	closures    atomic.Pointer[closureIndex]
+	closureLock sync.Mutex
 }
 
-func New(nodeRepository nodeRepository.NodeRepository, crossingRepository crossingRepository.CrossingRepository, wayRepository wayRepository.WayRepository, weightRepository weightRepository.WeightRepository, logger logging.Logger) GraphService {
+func New(nodeRepository nodeRepository.NodeRepository, crossingRepository crossingRepository.CrossingRepository, wayRepository wayRepository.WayRepository, weightRepository weightRepository.WeightRepository, closureRepository closureRepository.ClosureRepository, logger logging.Logger) GraphService {
 	return &impl{
 		nodeRepository:     nodeRepository,
 		crossingRepository: crossingRepository,
 		wayRepository:      wayRepository,
 		weightRepository:   weightRepository,
+		closureRepository:  closureRepository,
 		logger:             logger,
 	}
 }
 
-func (i *impl) GetEdges(end node.Node) func(prevId, id int64) map[int64]float64 {
+func (i *impl) GetProfile(name string) (*weightRepository.Profile, error) {
+	return i.weightRepository.GetProfile(name)
+}
+
+func (i *impl) GetEdges(query Query) func(prevId, id int64) map[int64]float64 {
+	closures := i.closures.Load().active(time.Now()).withAvoidAreas(query.AvoidAreas)
+
 	return func(prevId int64, id int64) map[int64]float64 {
-		return i.getEdges(prevId, id, end)
+		return i.getEdges(prevId, id, query, closures)
 	}
 }
 
-func (i *impl) getEdges(prevId, id int64, end node.Node) map[int64]float64 {
+func (i *impl) getEdges(prevId, id int64, query Query, closures *closureIndex) map[int64]float64 {
+	query.Stats.Add(2)
 	ways, err := i.wayRepository.SelectWaysFromNode(id)
 	if err != nil {
 		i.logger.Error().Msgf("error while selecting ways from node: %s", err.Error())
@@ -68,10 +135,12 @@ func (i *impl) getEdges(prevId, id int64, end node.Node) map[int64]float64 {
 
 	out := make(map[int64]float64)
 	for _, w := range ways {
-		if !i.weightRepository.IsWayAllowed(*w, defaultVehicleType) {
+		access := i.weightRepository.GetWayAccess(*w, query.Vehicle)
+		if access == weightRepository.AccessDenied {
 			continue
 		}
 
+		query.Stats.Add(1)
 		crossings, err := i.crossingRepository.SelectCrossingsFromWayID(w.OsmID)
 		if err != nil {
 			i.logger.Error().Msgf("error while selecting nodes from way: %s", err.Error())
@@ -79,10 +148,12 @@ func (i *impl) getEdges(prevId, id int64, end node.Node) map[int64]float64 {
 		}
 
 		var fromCrossing *crossing.Crossing
-		for _, n := range crossings {
+		fromIndex := -1
+		for index, n := range crossings {
 
 			if n.OsmID == id {
 				fromCrossing = n
+				fromIndex = index
 				break
 			}
 		}
@@ -92,8 +163,30 @@ func (i *impl) getEdges(prevId, id int64, end node.Node) map[int64]float64 {
 			continue
 		}
 
-		weights := i.weightRepository.CalculateWeights(prevNode, fromCrossing, w, crossings, end, defaultVehicleType)
+		if access == weightRepository.AccessDestination && !isNearEndpoint(fromCrossing.Node, query) {
+			continue
+		}
+
+		weights := i.weightRepository.CalculateWeights(prevNode, fromCrossing, w, crossings, query.End, query.Vehicle)
+		for _, target := range targetsOnWay(query.Targets, crossings) {
+			if v, ok := i.weightRepository.CalculateWeights(prevNode, fromCrossing, w, crossings, target, query.Vehicle)[target.OsmID]; ok {
+				weights[target.OsmID] = v
+			}
+		}
+
 		for k, v := range weights {
+			if closures != nil {
+				factor := closures.edgeFactor(w.OsmID, crossings, fromIndex, nearestIndex(crossings, k, fromIndex))
+				if factor <= 0 {
+					continue
+				}
+				v /= factor
+			}
+
+			if penalty, ok := query.EdgePenalties[NewEdgeKey(id, k)]; ok {
+				v *= penalty
+			}
+
 			if prevV, ok := out[k]; ok && prevV < v {
 				continue
 			}
@@ -104,8 +197,174 @@ func (i *impl) getEdges(prevId, id int64, end node.Node) map[int64]float64 {
 	return out
 }
 
-func (i *impl) GetHeuristic(end node.Node) func(id int64) float64 {
+func (i *impl) GetReverseEdges(query Query) func(nextId, id int64) map[int64]float64 {
+	closures := i.closures.Load().active(time.Now()).withAvoidAreas(query.AvoidAreas)
+
+	return func(_ int64, id int64) map[int64]float64 {
+		return i.getReverseEdges(id, query, closures)
+	}
+}
+
+// getReverseEdges calculates the edges of the neighbouring crossings forwards and keeps the edges to the node, so
+// that oneways are followed in their direction. Turn costs depend on the next node and are left out.
+func (i *impl) getReverseEdges(id int64, query Query, closures *closureIndex) map[int64]float64 {
+	query.Stats.Add(1)
+	ways, err := i.wayRepository.SelectWaysFromNode(id)
+	if err != nil {
+		i.logger.Error().Msgf("error while selecting ways from node: %s", err.Error())
+		return make(map[int64]float64)
+	}
+
+	// the weights of edges to a target are only calculated, if the target is passed as end
+	end := query.End
+	for _, target := range query.Targets {
+		if target.OsmID == id {
+			end = target
+			break
+		}
+	}
+
+	out := make(map[int64]float64)
+	for _, w := range ways {
+		access := i.weightRepository.GetWayAccess(*w, query.Vehicle)
+		if access == weightRepository.AccessDenied {
+			continue
+		}
+
+		query.Stats.Add(1)
+		crossings, err := i.crossingRepository.SelectCrossingsFromWayID(w.OsmID)
+		if err != nil {
+			i.logger.Error().Msgf("error while selecting nodes from way: %s", err.Error())
+			continue
+		}
+
+		for toIndex, n := range crossings {
+			if n.OsmID != id {
+				continue
+			}
+
+			previous, next := previousCrossing(crossings, toIndex), nextCrossing(crossings, toIndex)
+
+			// targets between the neighbouring crossings reach the node without passing a crossing
+			fromIndices := append([]int{previous, next}, targetIndices(query.Targets, crossings, previous, next)...)
+
+			for _, fromIndex := range fromIndices {
+				if fromIndex == -1 || fromIndex == toIndex {
+					continue
+				}
+
+				fromCrossing := crossings[fromIndex]
+				if access == weightRepository.AccessDestination && !isNearEndpoint(fromCrossing.Node, query) {
+					continue
+				}
+
+				v, ok := i.weightRepository.CalculateWeights(nil, fromCrossing, w, crossings, end, query.Vehicle)[id]
+				if !ok {
+					continue
+				}
+
+				if closures != nil {
+					factor := closures.edgeFactor(w.OsmID, crossings, fromIndex, toIndex)
+					if factor <= 0 {
+						continue
+					}
+					v /= factor
+				}
+
+				if penalty, ok := query.EdgePenalties[NewEdgeKey(fromCrossing.OsmID, id)]; ok {
+					v *= penalty
+				}
+
+				if prevV, ok := out[fromCrossing.OsmID]; ok && prevV < v {
+					continue
+				}
+				out[fromCrossing.OsmID] = v
+			}
+		}
+	}
+
+	return out
+}
+
+// previousCrossing and nextCrossing find the crossings next to a position in the nodes of a way, or -1
+func previousCrossing(nodes []*crossing.Crossing, index int) int {
+	for index--; index >= 0; index-- {
+		if nodes[index].IsCrossing {
+			return index
+		}
+	}
+	return -1
+}
+
+func nextCrossing(nodes []*crossing.Crossing, index int) int {
+	for index++; index < len(nodes); index++ {
+		if nodes[index].IsCrossing {
+			return index
+		}
+	}
+	return -1
+}
+
+// targetsOnWay returns the targets, that are nodes of the way
+func targetsOnWay(targets []node.Node, crossings []*crossing.Crossing) []node.Node {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	var out []node.Node
+	for _, target := range targets {
+		for _, c := range crossings {
+			if c.OsmID == target.OsmID {
+				out = append(out, target)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// targetIndices returns the positions of the targets, that are nodes of the way between the indices from and to.
+// An index of -1 leaves that side unbounded.
+func targetIndices(targets []node.Node, nodes []*crossing.Crossing, from int, to int) []int {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	if to == -1 {
+		to = len(nodes) - 1
+	}
+
+	var out []int
+	for index := max(from, 0); index <= to; index++ {
+		for _, target := range targets {
+			if nodes[index].OsmID == target.OsmID {
+				out = append(out, index)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// isNearEndpoint checks if n is close enough to the start, the end or a target of the query to use destination-only ways
+func isNearEndpoint(n node.Node, query Query) bool {
+	point := sphericmath.NewPoint(n.Lat, n.Lon)
+
+	for _, endpoint := range append([]node.Node{query.Start, query.End}, query.Targets...) {
+		if sphericmath.CalcDistanceInMeters(point, sphericmath.NewPoint(endpoint.Lat, endpoint.Lon)) <= destinationRadius {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *impl) GetHeuristic(query Query) func(id int64) float64 {
+	end := query.End
+	maximumWayFactor := i.weightRepository.MaximumWayFactor(query.Vehicle.Profile)
+
 	return func(nodeId int64) float64 {
+		query.Stats.Add(1)
 		node, err := i.nodeRepository.SelectNodeFromID(nodeId)
 		if err != nil {
 			i.logger.Error().Msgf("error while selecting node from id: %s", err.Error())
@@ -115,31 +374,33 @@ func (i *impl) GetHeuristic(end node.Node) func(id int64) float64 {
 		return sphericmath.CalcDistanceInMeters(
 			sphericmath.NewPoint(end.Lat, end.Lon),
 			sphericmath.NewPoint(node.Lat, node.Lon),
-		) * (i.weightRepository.MaximumWayFactor(defaultVehicleType) * 2)
+		) * (maximumWayFactor * 2)
 	}
 }
 
-func (i *impl) CalculatePathInformation(path []int64) (outPath []geojson.Point, lengthInMeters float64, err error) {
+func (i *impl) CalculatePathInformation(path []int64, vehicle weightRepository.Vehicle, stats *QueryStats) (outPath []geojson.Point, elevations []float64, lengthInMeters float64, err error) {
+	stats.Add(1)
 	prevNode, err := i.nodeRepository.SelectNodeFromID(path[0])
 	if err != nil {
-		return nil, 0.0, fmt.Errorf("error while selecting node from id: %s", err.Error())
+		return nil, nil, 0.0, fmt.Errorf("error while selecting node from id: %s", err.Error())
 	}
 
 	var points []geojson.Point
 
 	for _, nodeId := range path[1:] {
+		stats.Add(2)
 		n, err := i.nodeRepository.SelectNodeFromID(nodeId)
 		if err != nil {
-			return nil, 0.0, fmt.Errorf("error while selecting node from id: %s", err.Error())
+			return nil, nil, 0.0, fmt.Errorf("error while selecting node from id: %s", err.Error())
 		}
 
 		ways, err := i.wayRepository.SelectWaysFromTwoNodeIDs(prevNode.OsmID, n.OsmID)
 		if err != nil {
-			return nil, 0.0, fmt.Errorf("error while selecting ways from two nodes: %s", err.Error())
+			return nil, nil, 0.0, fmt.Errorf("error while selecting ways from two nodes: %s", err.Error())
 		}
 
 		if len(ways) == 0 {
-			return nil, 0.0, fmt.Errorf("no way found between node %d and %d", prevNode.OsmID, n.OsmID)
+			return nil, nil, 0.0, fmt.Errorf("no way found between node %d and %d", prevNode.OsmID, n.OsmID)
 		}
 
 		var way *way.Way
@@ -151,14 +412,15 @@ func (i *impl) CalculatePathInformation(path []int64) (outPath []geojson.Point,
 		}
 
 		for _, w := range ways {
+			stats.Add(1)
 			cPathNodes, err := i.crossingRepository.SelectCrossingsFromWayID(w.OsmID)
 			if err != nil {
-				return nil, 0.0, fmt.Errorf("error while selecting nodes from way: %s", err.Error())
+				return nil, nil, 0.0, fmt.Errorf("error while selecting nodes from way: %s", err.Error())
 			}
 
-			cPathNodes = i.weightRepository.CutPathNodes(&crossing.Crossing{Node: *prevNode}, w, cPathNodes)
+			cPathNodes = i.weightRepository.CutPathNodes(&crossing.Crossing{Node: *prevNode}, w, cPathNodes, vehicle.Profile)
 
-			dist := i.weightRepository.CalculateDistances(prevNode, w, cPathNodes, n)
+			dist := i.weightRepository.CalculateDistances(prevNode, w, cPathNodes, n, vehicle.Profile)
 			if dist < shortestLength {
 				shortestLength = dist
 				way = w
@@ -166,6 +428,10 @@ func (i *impl) CalculatePathInformation(path []int64) (outPath []geojson.Point,
 			}
 		}
 
+		if way == nil {
+			return nil, nil, 0.0, fmt.Errorf("no usable way found between node %d and %d", prevNode.OsmID, n.OsmID)
+		}
+
 		lengthInMeters += shortestLength
 
 		startIndex := -1
@@ -181,7 +447,7 @@ func (i *impl) CalculatePathInformation(path []int64) (outPath []geojson.Point,
 		}
 
 		if startIndex == -1 || endIndex == -1 {
-			return nil, 0.0, fmt.Errorf("node %d or %d not found in way %d", prevNode.OsmID, n.OsmID, way.OsmID)
+			return nil, nil, 0.0, fmt.Errorf("node %d or %d not found in way %d", prevNode.OsmID, n.OsmID, way.OsmID)
 		}
 
 		if startIndex > endIndex {
@@ -192,52 +458,90 @@ func (i *impl) CalculatePathInformation(path []int64) (outPath []geojson.Point,
 
 		for _, node := range pathNodes {
 			points = append(points, geojson.NewPoint(node.Lon, node.Lat))
+			elevations = append(elevations, node.Elevation())
 		}
 
 		prevNode = n
 	}
 
-	return points, lengthInMeters, nil
+	return points, elevations, lengthInMeters, nil
+}
+
+func (i *impl) GetNearestNode(lat float64, lon float64, vehicle weightRepository.Vehicle) (*node.Node, error) {
+	nodes, err := i.GetNearNodes(lat, lon, vehicle, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes[0], nil
+}
+
+// GetNearNodes returns the nodes with edges for the vehicle around the position, sorted by distance
+func (i *impl) GetNearNodes(lat float64, lon float64, vehicle weightRepository.Vehicle, stats *QueryStats) ([]*node.Node, error) {
+	nodes, err := i.GetUsableNodes(lat, lon, NearNodesRadius, vehicle, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return SortNearNodes(nodes, lat, lon)
 }
 
-func (i *impl) GetNearestNode(lat float64, lon float64) (*node.Node, error) {
-	nodes, err := i.nodeRepository.SelectNearNodesApprox(lat, lon, nearNodesApproxDistance)
+// GetUsableNodes returns the nodes with edges for the vehicle within radius degrees around the position
+func (i *impl) GetUsableNodes(lat float64, lon float64, radius float64, vehicle weightRepository.Vehicle, stats *QueryStats) ([]*node.Node, error) {
+	stats.Add(1)
+	nodes, err := i.nodeRepository.SelectNearNodesApprox(lat, lon, radius)
 	if err != nil {
 		return nil, fmt.Errorf("error while selecting near nodes: %s", err.Error())
 	}
 
 	i.logger.Debug().Msgf("found %d near nodes", len(nodes))
 
-	searchPoint := sphericmath.NewPoint(lat, lon)
-	var nearestNode *node.Node
-	var nearestNodeDistance float64
-
+	var out []*node.Node
 	var skippedNodes []int64
 
 	for _, node := range nodes {
-		if !i.hasEdges(node.OsmID) {
+		if !i.hasEdges(node.OsmID, vehicle, stats) {
 			skippedNodes = append(skippedNodes, node.OsmID)
 			continue
 		}
 
-		nodePoint := sphericmath.NewPoint(node.Lat, node.Lon)
-		distance := sphericmath.CalcDistanceInMeters(searchPoint, nodePoint)
-		if nearestNode == nil || distance < nearestNodeDistance {
-			nearestNode = node
-			nearestNodeDistance = distance
-		}
+		out = append(out, node)
 	}
 
 	i.logger.WithAttrs("skipped", skippedNodes).Debug().Msgf("skipped %d nodes without edges", len(skippedNodes))
 
-	if nearestNode == nil {
-		return nil, fmt.Errorf("no near node found (in %f meters)", math.Tan(nearNodesApproxDistance*math.Pi/180)*sphericmath.EarthRadius)
+	return out, nil
+}
+
+// SortNearNodes keeps the usable nodes within NearNodesRadius around the position and sorts them by distance,
+// so usable nodes of a larger area give the same result as GetNearNodes
+func SortNearNodes(nodes []*node.Node, lat float64, lon float64) ([]*node.Node, error) {
+	searchPoint := sphericmath.NewPoint(lat, lon)
+	distances := make(map[int64]float64, len(nodes))
+
+	var out []*node.Node
+	for _, n := range nodes {
+		if math.Abs(n.Lat-lat) > NearNodesRadius || math.Abs(n.Lon-lon) > NearNodesRadius {
+			continue
+		}
+
+		distances[n.OsmID] = sphericmath.CalcDistanceInMeters(searchPoint, sphericmath.NewPoint(n.Lat, n.Lon))
+		out = append(out, n)
 	}
 
-	return nearestNode, nil
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w (in %f meters)", ErrNoNearNode, math.Tan(NearNodesRadius*math.Pi/180)*sphericmath.EarthRadius)
+	}
+
+	sort.SliceStable(out, func(a, b int) bool {
+		return distances[out[a].OsmID] < distances[out[b].OsmID]
+	})
+
+	return out, nil
 }
 
-func (i *impl) hasEdges(id int64) bool {
+func (i *impl) hasEdges(id int64, vehicle weightRepository.Vehicle, stats *QueryStats) bool {
+	stats.Add(1)
 	ways, err := i.wayRepository.SelectWaysFromNode(id)
 	if err != nil {
 		i.logger.Error().Msgf("error while selecting ways from node: %s", err.Error())
@@ -245,10 +549,11 @@ func (i *impl) hasEdges(id int64) bool {
 	}
 
 	for _, w := range ways {
-		if !i.weightRepository.IsWayAllowed(*w, defaultVehicleType) {
+		if i.weightRepository.GetWayAccess(*w, vehicle) == weightRepository.AccessDenied {
 			continue
 		}
 
+		stats.Add(1)
 		crossings, err := i.crossingRepository.SelectCrossingsFromWayID(w.OsmID)
 		if err != nil {
 			i.logger.Error().Msgf("error while selecting nodes from way: %s", err.Error())
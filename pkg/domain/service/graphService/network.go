@@ -0,0 +1,44 @@
+package graphService
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/crossing"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+)
+
+// NetworkWay is a way of the road graph together with the attributes, that are used for routing on it
+type NetworkWay struct {
+	Way        *way.Way
+	Nodes      []*crossing.Crossing
+	Attributes weightRepository.WayAttributes
+}
+
+// GetNetwork returns all ways with at least one node in the bounding box
+func (i *impl) GetNetwork(minLat, minLon, maxLat, maxLon float64) ([]NetworkWay, error) {
+	wayIDs, err := i.crossingRepository.SelectWayIDsInBoundingBox(minLat, minLon, maxLat, maxLon)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting ways: %s", err.Error())
+	}
+
+	out := make([]NetworkWay, 0, len(wayIDs))
+	for _, wayID := range wayIDs {
+		w, err := i.wayRepository.SelectWayFromID(wayID)
+		if err != nil {
+			return nil, fmt.Errorf("error while selecting way %d: %s", wayID, err.Error())
+		}
+
+		nodes, err := i.crossingRepository.SelectCrossingsFromWayID(wayID)
+		if err != nil {
+			return nil, fmt.Errorf("error while selecting nodes of way %d: %s", wayID, err.Error())
+		}
+
+		out = append(out, NetworkWay{
+			Way:        w,
+			Nodes:      nodes,
+			Attributes: i.weightRepository.GetWayAttributes(*w),
+		})
+	}
+
+	return out, nil
+}
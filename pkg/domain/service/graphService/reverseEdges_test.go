@@ -0,0 +1,132 @@
+package graphService_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/closureRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/crossingRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/nodeRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/wayRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/arrayutil"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/astar"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newGraph imports a small network: the stop 1 is connected to the destination 3 by the oneway 100 and a
+// detour over the crossing 4
+//
+//	1 --100--> 2 --101-- 3 --101-- 4
+//	 \                             /
+//	  ------------102-- 5 ---------
+func newGraph(t *testing.T, onewayNodes []int64) graphService.GraphService {
+	t.Helper()
+
+	db, err := database.New(filepath.Join(t.TempDir(), "graph.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	wayRepo := wayRepository.New(db)
+	nodeRepo := nodeRepository.New(db)
+	crossingRepo := crossingRepository.New(db)
+	closureRepo := closureRepository.New(db)
+	for _, init := range []func() error{func() error { return wayRepo.Init(false) }, func() error { return nodeRepo.Init(false) }, crossingRepo.Init, closureRepo.Init} {
+		if err := init(); err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+	}
+
+	nodes := []node.Node{
+		{OsmID: 1, Lat: 48.0, Lon: 11.0},
+		{OsmID: 2, Lat: 48.001, Lon: 11.0},
+		{OsmID: 3, Lat: 48.0015, Lon: 11.0},
+		{OsmID: 4, Lat: 48.002, Lon: 11.0},
+		{OsmID: 5, Lat: 48.001, Lon: 11.001},
+	}
+
+	ways := []way.Way{
+		{OsmID: 100, Tags: map[string]string{"highway": "residential", "oneway": "yes"}, Nodes: onewayNodes},
+		{OsmID: 101, Tags: map[string]string{"highway": "residential"}, Nodes: []int64{2, 3, 4}},
+		{OsmID: 102, Tags: map[string]string{"highway": "residential"}, Nodes: []int64{4, 5, 1}},
+	}
+
+	if err := nodeRepo.InsertNodes(nodes); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if err := wayRepo.InsertWays(ways); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if err := wayRepo.UpdateCrossings(); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	profiles, err := weightRepository.NewProfiles(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	logger := logging.New(logging.LevelError, io.Discard)
+	weightRepo := weightRepository.New(profiles, logger)
+
+	graph := graphService.New(nodeRepo, crossingRepo, wayRepo, weightRepo, closureRepo, logger)
+	if err := graph.LoadClosures(); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	return graph
+}
+
+// pathToDestination searches the way from the stop 1 to the destination 3 backwards, like the egress of a transit route
+func pathToDestination(t *testing.T, graph graphService.GraphService) []int64 {
+	t.Helper()
+
+	profile, err := graph.GetProfile("car")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	vehicle := weightRepository.Vehicle{Profile: profile}
+	query := graphService.Query{
+		Vehicle: vehicle,
+		Start:   node.Node{OsmID: 1, Lat: 48.0, Lon: 11.0},
+		End:     node.Node{OsmID: 3, Lat: 48.0015, Lon: 11.0},
+	}
+
+	tree, err := astar.Dijkstra[int64, float64](3, graph.GetReverseEdges(query), math.Inf(1), 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	path := arrayutil.Reverse(tree.Path(1))
+
+	// the path has to be usable forwards, otherwise the geometry cannot be built
+	_, _, _, err = graph.CalculatePathInformation(path, vehicle, nil)
+	if err != nil {
+		t.Fatalf("path %v is not usable forwards: %s", path, err.Error())
+	}
+
+	return path
+}
+
+func TestReverseEdgesWithOneway(t *testing.T) {
+	path := pathToDestination(t, newGraph(t, []int64{1, 2}))
+	if !slices.Equal(path, []int64{1, 2, 3}) {
+		t.Errorf("path along the oneway = %v, expected [1 2 3]", path)
+	}
+}
+
+func TestReverseEdgesAgainstOneway(t *testing.T) {
+	path := pathToDestination(t, newGraph(t, []int64{2, 1}))
+	if !slices.Equal(path, []int64{1, 4, 3}) {
+		t.Errorf("path avoiding the oneway = %v, expected [1 4 3]", path)
+	}
+}
@@ -0,0 +1,23 @@
+package graphService
+
+import "sync/atomic"
+
+// QueryStats counts the database queries of the graph service for a request. A nil QueryStats counts nothing.
+type QueryStats struct {
+	queries atomic.Int64
+}
+
+// Add counts queries, that are made outside of the graph service
+func (s *QueryStats) Add(count int64) {
+	if s == nil {
+		return
+	}
+	s.queries.Add(count)
+}
+
+func (s *QueryStats) Queries() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.queries.Load()
+}
@@ -0,0 +1,86 @@
+package graphService_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/service/graphService"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/arrayutil"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/astar"
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestTargets searches the nodes 3 and 5, that are no crossings, from the stop 1 with a single search
+func TestTargets(t *testing.T) {
+	graph := newGraph(t, []int64{1, 2})
+
+	profile, err := graph.GetProfile("car")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	start := node.Node{OsmID: 1, Lat: 48.0, Lon: 11.0}
+	end := node.Node{OsmID: 3, Lat: 48.0015, Lon: 11.0}
+	target := node.Node{OsmID: 5, Lat: 48.001, Lon: 11.001}
+
+	search := func(targets []node.Node) map[int64]float64 {
+		query := graphService.Query{Vehicle: weightRepository.Vehicle{Profile: profile}, Start: start, End: end, Targets: targets}
+
+		tree, err := astar.DijkstraToTargets[int64, float64](1, []int64{3, 5}, graph.GetEdges(query), math.Inf(1), 1000)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		return tree.Costs
+	}
+
+	costs := search(nil)
+	if _, ok := costs[5]; ok {
+		t.Errorf("expected the node 5 to be unreachable without targets")
+	}
+
+	costs = search([]node.Node{target})
+	for _, id := range []int64{3, 5} {
+		if _, ok := costs[id]; !ok {
+			t.Errorf("expected the node %d to be reachable", id)
+		}
+	}
+}
+
+// TestReverseTargets searches the node 5 in the middle of the way 102 backwards from the destination 3, like a stop
+// for the egress of a transit route
+func TestReverseTargets(t *testing.T) {
+	graph := newGraph(t, []int64{1, 2})
+
+	profile, err := graph.GetProfile("car")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	vehicle := weightRepository.Vehicle{Profile: profile}
+	query := graphService.Query{
+		Vehicle: vehicle,
+		Start:   node.Node{OsmID: 1, Lat: 48.0, Lon: 11.0},
+		End:     node.Node{OsmID: 3, Lat: 48.0015, Lon: 11.0},
+		Targets: []node.Node{{OsmID: 5, Lat: 48.001, Lon: 11.001}},
+	}
+
+	tree, err := astar.Dijkstra[int64, float64](3, graph.GetReverseEdges(query), math.Inf(1), 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if _, ok := tree.Costs[5]; !ok {
+		t.Fatalf("expected the node 5 to be reachable")
+	}
+
+	path := arrayutil.Reverse(tree.Path(5))
+	if !slices.Equal(path, []int64{5, 4, 3}) {
+		t.Errorf("path from the node 5 = %v, expected [5 4 3]", path)
+	}
+
+	_, _, _, err = graph.CalculatePathInformation(path, vehicle, nil)
+	if err != nil {
+		t.Errorf("path %v is not usable forwards: %s", path, err.Error())
+	}
+}
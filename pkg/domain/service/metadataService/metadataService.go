@@ -0,0 +1,140 @@
+package metadataService
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/metadataRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"strconv"
+	"time"
+)
+
+const (
+	replicationSequenceKey  = "replication_sequence_number"
+	replicationTimestampKey = "replication_timestamp"
+	importCheckpointKey     = "import_checkpoint"
+)
+
+// ImportStep is the part of an import, a checkpoint continues with
+type ImportStep string
+
+const (
+	ImportStepWays        ImportStep = "ways"
+	ImportStepWayIndices  ImportStep = "wayIndices"
+	ImportStepNodes       ImportStep = "nodes"
+	ImportStepNodeIndices ImportStep = "nodeIndices"
+	ImportStepComplete    ImportStep = "complete"
+)
+
+// ImportCheckpoint is the progress of an import, that is saved after each flushed commit
+type ImportCheckpoint struct {
+	Files   string     `json:"files"` // the comma separated import files, an import is only continued with the same files
+	Step    ImportStep `json:"step"`
+	Offset  int64      `json:"offset"`  // the offset of the last completed blob of the step
+	Commits int64      `json:"commits"` // the commits flushed by the import so far
+
+	// the rows inserted after the checkpoint are removed before continuing, as relations and addresses are not unique
+	RelationRowID int64 `json:"relationRowID"`
+	AddressRowID  int64 `json:"addressRowID"`
+}
+
+func (c *ImportCheckpoint) Complete() bool {
+	return c.Step == ImportStepComplete
+}
+
+type MetadataService interface {
+	// GetReplicationState returns false, if no change file has been applied yet
+	GetReplicationState() (sequenceNumber int64, timestamp time.Time, ok bool, err error)
+	SetReplicationState(sequenceNumber int64, timestamp time.Time) error
+
+	// GetImportCheckpoint returns nil, if the database has been imported before checkpoints were recorded
+	GetImportCheckpoint() (*ImportCheckpoint, error)
+	SetImportCheckpoint(checkpoint ImportCheckpoint) error
+}
+
+type impl struct {
+	logger             logging.Logger
+	metadataRepository metadataRepository.MetadataRepository
+}
+
+func New(metadataRepository metadataRepository.MetadataRepository, logger logging.Logger) MetadataService {
+	return &impl{
+		logger:             logger,
+		metadataRepository: metadataRepository,
+	}
+}
+
+func (i *impl) GetReplicationState() (int64, time.Time, bool, error) {
+	value, ok, err := i.metadataRepository.SelectValue(replicationSequenceKey)
+	if err != nil || !ok {
+		return 0, time.Time{}, false, err
+	}
+
+	sequenceNumber, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false, fmt.Errorf("error while parsing replication sequence number %q: %s", value, err.Error())
+	}
+
+	var timestamp time.Time
+	value, ok, err = i.metadataRepository.SelectValue(replicationTimestampKey)
+	if err != nil {
+		return 0, time.Time{}, false, err
+	}
+
+	if ok && value != "" {
+		timestamp, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return 0, time.Time{}, false, fmt.Errorf("error while parsing replication timestamp %q: %s", value, err.Error())
+		}
+	}
+
+	return sequenceNumber, timestamp, true, nil
+}
+
+func (i *impl) SetReplicationState(sequenceNumber int64, timestamp time.Time) error {
+	err := i.metadataRepository.UpdateValue(replicationSequenceKey, strconv.FormatInt(sequenceNumber, 10))
+	if err != nil {
+		return fmt.Errorf("error while updating replication sequence number: %s", err.Error())
+	}
+
+	value := ""
+	if !timestamp.IsZero() {
+		value = timestamp.UTC().Format(time.RFC3339)
+	}
+
+	err = i.metadataRepository.UpdateValue(replicationTimestampKey, value)
+	if err != nil {
+		return fmt.Errorf("error while updating replication timestamp: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) GetImportCheckpoint() (*ImportCheckpoint, error) {
+	value, ok, err := i.metadataRepository.SelectValue(importCheckpointKey)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var checkpoint ImportCheckpoint
+	err = json.Unmarshal([]byte(value), &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error while unmarshalling import checkpoint: %s", err.Error())
+	}
+
+	return &checkpoint, nil
+}
+
+func (i *impl) SetImportCheckpoint(checkpoint ImportCheckpoint) error {
+	value, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("error while marshalling import checkpoint: %s", err.Error())
+	}
+
+	err = i.metadataRepository.UpdateValue(importCheckpointKey, string(value))
+	if err != nil {
+		return fmt.Errorf("error while updating import checkpoint: %s", err.Error())
+	}
+
+	return nil
+}
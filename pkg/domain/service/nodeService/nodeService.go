@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/node"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/nodeRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
 )
 
@@ -17,8 +18,12 @@ type NodeService interface {
 
 	CreateIndices() error
 
+	DeleteNode(id int64) error
+
 	SelectNodeFromID(id int64) (*node.Node, error)
 	SelectNodesFromIDs(ids []int64) ([]*node.Node, error)
+	SelectNodeIDsFromWayID(wayID int64) ([]int64, error)
+	SelectSampleNode() (*node.Node, error)
 
 	LocateOsmID(osmID int64) (lat, lon float64, err error)
 }
@@ -26,12 +31,14 @@ type NodeService interface {
 type impl struct {
 	logger           logging.Logger
 	nodeRepository   nodeRepository.NodeRepository
+	writer           database.Writer
 	bulkInsertBuffer []node.Node
 }
 
-func New(nodeRepository nodeRepository.NodeRepository, logger logging.Logger) NodeService {
+func New(nodeRepository nodeRepository.NodeRepository, writer database.Writer, logger logging.Logger) NodeService {
 	return &impl{
 		nodeRepository: nodeRepository,
+		writer:         writer,
 		logger:         logger,
 	}
 }
@@ -42,22 +49,39 @@ func (i *impl) InsertNode(node node.Node) error {
 
 func (i *impl) InsertNodeBulk(n node.Node) error {
 	if len(i.bulkInsertBuffer) == bulkInsertBufferSize {
-		err := i.CommitBulkInsert()
-		if err != nil {
-			return fmt.Errorf("error while committing bulk insert: %s", err.Error())
-		}
+		i.flushBulkInsert()
 	}
 
 	i.bulkInsertBuffer = append(i.bulkInsertBuffer, n)
 	return nil
 }
 
+// flushBulkInsert hands the buffer to the writer, the nodes are inserted while the next buffer is filled
+func (i *impl) flushBulkInsert() {
+	if len(i.bulkInsertBuffer) == 0 {
+		return
+	}
+
+	nodes := i.bulkInsertBuffer
+	i.bulkInsertBuffer = make([]node.Node, 0, bulkInsertBufferSize)
+
+	i.writer.Write(func() error {
+		err := i.nodeRepository.InsertNodes(nodes)
+		if err != nil {
+			return fmt.Errorf("error while inserting nodes: %s", err.Error())
+		}
+		return nil
+	})
+}
+
+// CommitBulkInsert inserts the buffer and waits until all buffers handed to the writer are inserted
 func (i *impl) CommitBulkInsert() error {
-	err := i.nodeRepository.InsertNodes(i.bulkInsertBuffer)
+	i.flushBulkInsert()
+
+	err := i.writer.Wait()
 	if err != nil {
-		return fmt.Errorf("error while inserting nodes: %s", err.Error())
+		return fmt.Errorf("error while committing bulk insert: %s", err.Error())
 	}
-	i.bulkInsertBuffer = make([]node.Node, 0, bulkInsertBufferSize)
 	return nil
 }
 
@@ -65,10 +89,22 @@ func (i *impl) CreateIndices() error {
 	return i.nodeRepository.InitIndices()
 }
 
+func (i *impl) DeleteNode(id int64) error {
+	return i.nodeRepository.DeleteNode(id)
+}
+
+func (i *impl) SelectNodeIDsFromWayID(wayID int64) ([]int64, error) {
+	return i.nodeRepository.SelectNodeIDsFromWayID(wayID)
+}
+
 func (i *impl) SelectNodeFromID(id int64) (*node.Node, error) {
 	return i.nodeRepository.SelectNodeFromID(id)
 }
 
+func (i *impl) SelectSampleNode() (*node.Node, error) {
+	return i.nodeRepository.SelectSampleNode()
+}
+
 func (i *impl) SelectNodesFromIDs(ids []int64) ([]*node.Node, error) {
 	var out []*node.Node
 	for _, id := range ids {
@@ -6,10 +6,16 @@ import (
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/osmdatarepository"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
 	"io"
+	"os"
 )
 
 type OsmDataService interface {
 	Process(processor osmdatarepository.OsmDataProcessor, filter osmdatarepository.OsmDataFilter) error
+	// ProcessFrom continues processing at the offset of a checkpoint, this is only possible for a single file
+	ProcessFrom(offset int64, processor osmdatarepository.OsmDataProcessor, filter osmdatarepository.OsmDataFilter) error
+	FilePaths() []string
+	// Size is the size of all files in bytes, the progress of processing them is reported in bytes read
+	Size() (int64, error)
 }
 
 type impl struct {
@@ -26,7 +32,51 @@ func New(osmDataRepository osmdatarepository.OsmDataRepository, filePaths []stri
 	}
 }
 
+func (i *impl) FilePaths() []string {
+	return i.filePaths
+}
+
+func (i *impl) Size() (int64, error) {
+	var size int64
+	for _, filePath := range i.filePaths {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return 0, fmt.Errorf("error while reading size of file: %s", err.Error())
+		}
+		size += info.Size()
+	}
+
+	return size, nil
+}
+
+func (i *impl) ProcessFrom(offset int64, processor osmdatarepository.OsmDataProcessor, filter osmdatarepository.OsmDataFilter) error {
+	if offset == 0 {
+		return i.Process(processor, filter)
+	}
+
+	if len(i.filePaths) != 1 {
+		return fmt.Errorf("cannot continue at offset %d: merged files are processed from the start", offset)
+	}
+
+	err := i.osmDataRepository.ProcessFrom(i.filePaths[0], offset, processor, filter)
+	if err != nil {
+		return fmt.Errorf("error while processing file: %s", err.Error())
+	}
+
+	return nil
+}
+
 func (i *impl) Process(processor osmdatarepository.OsmDataProcessor, filter osmdatarepository.OsmDataFilter) error {
+	if len(i.filePaths) > 1 {
+		// overlapping extracts contain the same elements, which must not be inserted twice
+		err := i.osmDataRepository.ProcessMerged(i.filePaths, processor, filter)
+		if err != nil {
+			return fmt.Errorf("error while processing files: %s", err.Error())
+		}
+
+		return nil
+	}
+
 	for _, filePath := range i.filePaths {
 		err := i.osmDataRepository.Process(filePath, processor, filter)
 		if errors.Is(err, io.EOF) {
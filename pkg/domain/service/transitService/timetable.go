@@ -0,0 +1,346 @@
+package transitService
+
+import (
+	"errors"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/transit"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	transferTime       = 120          // seconds needed to change between two trips
+	maxJourneyDuration = 12 * 60 * 60 // seconds after the departure, in which connections are scanned
+	dateFormat         = "20060102"   // format of the service dates
+	noStop             = -1
+	unreached          = math.MaxInt64
+)
+
+// ErrNoJourney is returned if no stop near the destination can be reached
+var ErrNoJourney = errors.New("no journey found")
+
+type connection struct {
+	trip      int
+	from      int
+	to        int
+	departure int
+	arrival   int
+}
+
+type timetable struct {
+	location *time.Location
+
+	stops     []*transit.Stop
+	stopIndex map[string]int
+	trips     []*transit.Trip
+	services  map[string]*transit.Service
+
+	connections     []connection // sorted by departure
+	tripConnections [][]int      // indices into connections, sorted by departure
+	stationStops    [][]int      // other stops of the same station
+}
+
+func newTimetable(location *time.Location, stops []*transit.Stop, trips []*transit.Trip, connections []*transit.Connection, services []*transit.Service) *timetable {
+	t := &timetable{
+		location:  location,
+		stops:     stops,
+		stopIndex: make(map[string]int, len(stops)),
+		trips:     trips,
+		services:  make(map[string]*transit.Service, len(services)),
+	}
+
+	for index, stop := range stops {
+		t.stopIndex[stop.ID] = index
+	}
+
+	for _, service := range services {
+		t.services[service.ID] = service
+	}
+
+	tripIndex := make(map[string]int, len(trips))
+	for index, trip := range trips {
+		tripIndex[trip.ID] = index
+	}
+
+	t.tripConnections = make([][]int, len(trips))
+	for _, c := range connections {
+		trip, ok := tripIndex[c.TripID]
+		if !ok {
+			continue
+		}
+
+		from, ok := t.stopIndex[c.DepartureStop]
+		if !ok {
+			continue
+		}
+
+		to, ok := t.stopIndex[c.ArrivalStop]
+		if !ok {
+			continue
+		}
+
+		t.tripConnections[trip] = append(t.tripConnections[trip], len(t.connections))
+		t.connections = append(t.connections, connection{
+			trip:      trip,
+			from:      from,
+			to:        to,
+			departure: c.DepartureTime,
+			arrival:   c.ArrivalTime,
+		})
+	}
+
+	stations := make(map[string][]int)
+	for index, stop := range stops {
+		station := stop.Parent
+		if station == "" {
+			station = stop.ID
+		}
+		stations[station] = append(stations[station], index)
+	}
+
+	t.stationStops = make([][]int, len(stops))
+	for _, members := range stations {
+		for _, stop := range members {
+			for _, other := range members {
+				if other != stop {
+					t.stationStops[stop] = append(t.stationStops[stop], other)
+				}
+			}
+		}
+	}
+
+	return t
+}
+
+// serviceDay is a day, on which trips of the timetable run. GTFS times are relative to noon minus 12 hours,
+// so trips of the previous day run after midnight.
+type serviceDay struct {
+	base   int64 // unix time of the start of the service day
+	date   string
+	active map[string]bool
+	next   int // index of the next connection to scan
+}
+
+func (t *timetable) newServiceDay(date time.Time) *serviceDay {
+	noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, t.location)
+
+	return &serviceDay{
+		base:   noon.Add(-12 * time.Hour).Unix(),
+		date:   noon.Format(dateFormat),
+		active: make(map[string]bool),
+	}
+}
+
+func (t *timetable) isActive(day *serviceDay, serviceID string) bool {
+	active, ok := day.active[serviceID]
+	if ok {
+		return active
+	}
+
+	active = false
+	if service, ok := t.services[serviceID]; ok {
+		weekday, _ := time.Parse(dateFormat, day.date)
+		active = service.Weekdays[weekday.Weekday()] && day.date >= service.StartDate && day.date <= service.EndDate
+		active = (active || service.Added[day.date]) && !service.Removed[day.date]
+	}
+
+	day.active[serviceID] = active
+	return active
+}
+
+type tripOnDay struct {
+	trip int
+	day  int
+}
+
+// reach describes how a stop was reached: by a ride from enter to exit, or by a transfer from another stop
+type reach struct {
+	day          int
+	enter        int
+	exit         int
+	transferFrom int
+}
+
+// scan runs the earliest arrival connection scan algorithm (CSA) over the service days around the departure
+func (t *timetable) scan(access map[string]float64, egress map[string]float64, departure time.Time) (*transit.Journey, error) {
+	start := departure.Unix()
+	local := departure.In(t.location)
+
+	days := []*serviceDay{
+		t.newServiceDay(local.AddDate(0, 0, -1)),
+		t.newServiceDay(local),
+		t.newServiceDay(local.AddDate(0, 0, 1)),
+	}
+
+	for _, day := range days {
+		day.next = sort.Search(len(t.connections), func(index int) bool {
+			return day.base+int64(t.connections[index].departure) >= start
+		})
+	}
+
+	earliest := make([]int64, len(t.stops))
+	boardable := make([]int64, len(t.stops))
+	reachedBy := make([]*reach, len(t.stops))
+	for index := range earliest {
+		earliest[index] = unreached
+		boardable[index] = unreached
+	}
+
+	for stopID, seconds := range access {
+		stop, ok := t.stopIndex[stopID]
+		if !ok {
+			continue
+		}
+
+		arrival := start + int64(math.Ceil(seconds))
+		if arrival < earliest[stop] {
+			earliest[stop] = arrival
+			boardable[stop] = arrival
+		}
+	}
+
+	egressTime := make(map[int]int64, len(egress))
+	for stopID, seconds := range egress {
+		if stop, ok := t.stopIndex[stopID]; ok {
+			egressTime[stop] = int64(math.Ceil(seconds))
+		}
+	}
+
+	bestArrival := int64(unreached)
+	bestStop := noStop
+	enteredTrips := make(map[tripOnDay]int)
+
+	updateArrival := func(stop int, arrival int64, by *reach) {
+		earliest[stop] = arrival
+		reachedBy[stop] = by
+
+		if walk, ok := egressTime[stop]; ok && arrival+walk < bestArrival {
+			bestArrival = arrival + walk
+			bestStop = stop
+		}
+	}
+
+	limit := start + maxJourneyDuration
+	for {
+		dayIndex := t.nextDay(days)
+		if dayIndex == -1 {
+			break
+		}
+
+		day := days[dayIndex]
+		connectionIndex := day.next
+		day.next++
+
+		c := t.connections[connectionIndex]
+		connectionDeparture := day.base + int64(c.departure)
+		if connectionDeparture > bestArrival || connectionDeparture > limit {
+			break
+		}
+
+		if !t.isActive(day, t.trips[c.trip].ServiceID) {
+			continue
+		}
+
+		key := tripOnDay{trip: c.trip, day: dayIndex}
+		enter, onBoard := enteredTrips[key]
+		if !onBoard {
+			if boardable[c.from] > connectionDeparture {
+				continue
+			}
+
+			enter = connectionIndex
+			enteredTrips[key] = enter
+		}
+
+		arrival := day.base + int64(c.arrival)
+		if arrival >= earliest[c.to] {
+			continue
+		}
+
+		updateArrival(c.to, arrival, &reach{day: dayIndex, enter: enter, exit: connectionIndex, transferFrom: noStop})
+		boardable[c.to] = arrival + transferTime
+
+		for _, other := range t.stationStops[c.to] {
+			if arrival+transferTime < earliest[other] {
+				updateArrival(other, arrival+transferTime, &reach{transferFrom: c.to})
+				boardable[other] = arrival + transferTime
+			}
+		}
+	}
+
+	if bestStop == noStop {
+		return nil, ErrNoJourney
+	}
+
+	return t.buildJourney(bestStop, reachedBy, earliest, days), nil
+}
+
+// nextDay returns the service day with the next departing connection
+func (t *timetable) nextDay(days []*serviceDay) int {
+	out := -1
+	var outDeparture int64
+	for index, day := range days {
+		if day.next >= len(t.connections) {
+			continue
+		}
+
+		departure := day.base + int64(t.connections[day.next].departure)
+		if out == -1 || departure < outDeparture {
+			out = index
+			outDeparture = departure
+		}
+	}
+	return out
+}
+
+func (t *timetable) buildJourney(egressStop int, reachedBy []*reach, earliest []int64, days []*serviceDay) *transit.Journey {
+	var legs []transit.Leg
+
+	stop := egressStop
+	for reachedBy[stop] != nil {
+		by := reachedBy[stop]
+
+		if by.transferFrom != noStop {
+			legs = append(legs, transit.Leg{
+				Stops:     []*transit.Stop{t.stops[by.transferFrom], t.stops[stop]},
+				Departure: time.Unix(earliest[by.transferFrom], 0).In(t.location),
+				Arrival:   time.Unix(earliest[stop], 0).In(t.location),
+			})
+
+			stop = by.transferFrom
+			continue
+		}
+
+		enter := t.connections[by.enter]
+		exit := t.connections[by.exit]
+		base := days[by.day].base
+
+		stops := []*transit.Stop{t.stops[enter.from]}
+		for _, index := range t.tripConnections[enter.trip] {
+			c := t.connections[index]
+			if c.departure < enter.departure || c.departure > exit.departure {
+				continue
+			}
+			stops = append(stops, t.stops[c.to])
+		}
+
+		legs = append(legs, transit.Leg{
+			Trip:      t.trips[enter.trip],
+			Stops:     stops,
+			Departure: time.Unix(base+int64(enter.departure), 0).In(t.location),
+			Arrival:   time.Unix(base+int64(exit.arrival), 0).In(t.location),
+		})
+
+		stop = enter.from
+	}
+
+	for left, right := 0, len(legs)-1; left < right; left, right = left+1, right-1 {
+		legs[left], legs[right] = legs[right], legs[left]
+	}
+
+	return &transit.Journey{
+		AccessStop: t.stops[stop],
+		EgressStop: t.stops[egressStop],
+		Legs:       legs,
+	}
+}
@@ -0,0 +1,132 @@
+package transitService
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/transit"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/transitRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"sort"
+	"time"
+)
+
+type TransitService interface {
+	InsertFeed(feed transit.Feed) error
+
+	LoadTimetable() error
+	HasTimetable() bool
+	GetStops() []*transit.Stop
+
+	// FindJourney searches the journey with the earliest arrival at the destination.
+	// access and egress contain the walking time in seconds to and from the reachable stops.
+	FindJourney(access map[string]float64, egress map[string]float64, departure time.Time) (*transit.Journey, error)
+}
+
+type impl struct {
+	logger            logging.Logger
+	transitRepository transitRepository.TransitRepository
+
+	timetable *timetable
+}
+
+func New(transitRepository transitRepository.TransitRepository, logger logging.Logger) TransitService {
+	return &impl{
+		logger:            logger,
+		transitRepository: transitRepository,
+	}
+}
+
+func (i *impl) InsertFeed(feed transit.Feed) error {
+	err := i.transitRepository.InsertFeed(feed)
+	if err != nil {
+		return fmt.Errorf("error while inserting feed: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *impl) HasTimetable() bool {
+	return i.timetable != nil && len(i.timetable.connections) > 0
+}
+
+func (i *impl) GetStops() []*transit.Stop {
+	if i.timetable == nil {
+		return nil
+	}
+
+	return i.timetable.stops
+}
+
+// LoadTimetable reads the imported feeds into memory, as the connection scan needs all connections sorted by departure
+func (i *impl) LoadTimetable() error {
+	timezones, err := i.transitRepository.SelectFeedTimezones()
+	if err != nil {
+		return fmt.Errorf("error while selecting feeds: %s", err.Error())
+	}
+
+	if len(timezones) == 0 {
+		i.timetable = nil
+		return nil
+	}
+
+	location, err := selectLocation(timezones)
+	if err != nil {
+		return err
+	}
+
+	stops, err := i.transitRepository.SelectStops()
+	if err != nil {
+		return fmt.Errorf("error while selecting stops: %s", err.Error())
+	}
+
+	trips, err := i.transitRepository.SelectTrips()
+	if err != nil {
+		return fmt.Errorf("error while selecting trips: %s", err.Error())
+	}
+
+	connections, err := i.transitRepository.SelectConnections()
+	if err != nil {
+		return fmt.Errorf("error while selecting connections: %s", err.Error())
+	}
+
+	services, err := i.transitRepository.SelectServices()
+	if err != nil {
+		return fmt.Errorf("error while selecting services: %s", err.Error())
+	}
+
+	i.timetable = newTimetable(location, stops, trips, connections, services)
+
+	i.logger.Info().Msgf("loaded timetable with %d stops, %d trips and %d connections", len(i.timetable.stops), len(i.timetable.trips), len(i.timetable.connections))
+
+	return nil
+}
+
+// selectLocation returns the timezone of the feeds, all feeds have to share the same timezone
+func selectLocation(timezones map[string]string) (*time.Location, error) {
+	names := make([]string, 0, len(timezones))
+	for name := range timezones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	timezone := timezones[names[0]]
+	for _, name := range names[1:] {
+		if timezones[name] != timezone {
+			return nil, fmt.Errorf("feeds %s and %s use different timezones (%s, %s)", names[0], name, timezone, timezones[name])
+		}
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading timezone %s: %s", timezone, err.Error())
+	}
+
+	return location, nil
+}
+
+func (i *impl) FindJourney(access map[string]float64, egress map[string]float64, departure time.Time) (*transit.Journey, error) {
+	if !i.HasTimetable() {
+		return nil, fmt.Errorf("no timetable loaded")
+	}
+
+	return i.timetable.scan(access, egress, departure)
+}
@@ -1,14 +1,21 @@
 package wayService
 
 import (
+	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/way"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/wayRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
-	"sync"
 )
 
 type WayService interface {
 	InsertWay(way way.Way) error
+	// ReplaceWay replaces the tags and the nodes of an existing way or inserts it
+	ReplaceWay(way way.Way) error
+	DeleteWay(wayID int64) error
+
+	SelectLastRelationRowID() (int64, error)
+	DeleteRelationsAfterRowID(rowID int64) error
 
 	InsertWayBulk(way way.Way) error
 	CommitBulkInsert() error
@@ -18,22 +25,24 @@ type WayService interface {
 	SelectWayIDsFromNode(nodeID int64) ([]int64, error)
 
 	UpdateCrossings() error
+	UpdateCrossingsInRange(fromRowID int64, toRowID int64) error
+	UpdateCrossingsOfNodes(nodeIDs []int64) error
 }
 
 const bulkInsertBufferSize = 1<<16 - 1
 
 type impl struct {
-	wayRepository       wayRepository.WayRepository
-	bulkInsertBuffer    []way.Way
-	bulkInsertWaitGroup *sync.WaitGroup
-	logger              logging.Logger
+	wayRepository    wayRepository.WayRepository
+	writer           database.Writer
+	bulkInsertBuffer []way.Way
+	logger           logging.Logger
 }
 
-func New(wayRepository wayRepository.WayRepository, logger logging.Logger) WayService {
+func New(wayRepository wayRepository.WayRepository, writer database.Writer, logger logging.Logger) WayService {
 	return &impl{
-		wayRepository:       wayRepository,
-		bulkInsertWaitGroup: &sync.WaitGroup{},
-		logger:              logger,
+		wayRepository: wayRepository,
+		writer:        writer,
+		logger:        logger,
 	}
 }
 
@@ -41,24 +50,63 @@ func (i *impl) InsertWay(way way.Way) error {
 	return i.wayRepository.InsertWay(way)
 }
 
+func (i *impl) ReplaceWay(w way.Way) error {
+	// the relations to nodes are appended by InsertWay, so the old ones have to be removed first
+	err := i.wayRepository.DeleteWay(w.OsmID)
+	if err != nil {
+		return fmt.Errorf("error while deleting way: %s", err.Error())
+	}
+
+	return i.wayRepository.InsertWay(w)
+}
+
+func (i *impl) DeleteWay(wayID int64) error {
+	return i.wayRepository.DeleteWay(wayID)
+}
+
+func (i *impl) SelectLastRelationRowID() (int64, error) {
+	return i.wayRepository.SelectLastRelationRowID()
+}
+
+func (i *impl) DeleteRelationsAfterRowID(rowID int64) error {
+	return i.wayRepository.DeleteRelationsAfterRowID(rowID)
+}
+
 func (i *impl) InsertWayBulk(w way.Way) error {
 	if len(i.bulkInsertBuffer) == bulkInsertBufferSize {
-		err := i.CommitBulkInsert()
-		if err != nil {
-			return err
-		}
+		i.flushBulkInsert()
 	}
 
 	i.bulkInsertBuffer = append(i.bulkInsertBuffer, w)
 	return nil
 }
 
+// flushBulkInsert hands the buffer to the writer, the ways are inserted while the next buffer is filled
+func (i *impl) flushBulkInsert() {
+	if len(i.bulkInsertBuffer) == 0 {
+		return
+	}
+
+	ways := i.bulkInsertBuffer
+	i.bulkInsertBuffer = make([]way.Way, 0, bulkInsertBufferSize)
+
+	i.writer.Write(func() error {
+		err := i.wayRepository.InsertWays(ways)
+		if err != nil {
+			return fmt.Errorf("error while inserting ways: %s", err.Error())
+		}
+		return nil
+	})
+}
+
+// CommitBulkInsert inserts the buffer and waits until all buffers handed to the writer are inserted
 func (i *impl) CommitBulkInsert() error {
-	err := i.wayRepository.InsertWays(i.bulkInsertBuffer)
+	i.flushBulkInsert()
+
+	err := i.writer.Wait()
 	if err != nil {
-		return err
+		return fmt.Errorf("error while committing bulk insert: %s", err.Error())
 	}
-	i.bulkInsertBuffer = make([]way.Way, 0, bulkInsertBufferSize)
 	return nil
 }
 
@@ -70,6 +118,14 @@ func (i *impl) UpdateCrossings() error {
 	return i.wayRepository.UpdateCrossings()
 }
 
+func (i *impl) UpdateCrossingsInRange(fromRowID int64, toRowID int64) error {
+	return i.wayRepository.UpdateCrossingsInRange(fromRowID, toRowID)
+}
+
+func (i *impl) UpdateCrossingsOfNodes(nodeIDs []int64) error {
+	return i.wayRepository.UpdateCrossingsOfNodes(nodeIDs)
+}
+
 func (i *impl) SelectWayIDsFromNode(nodeID int64) ([]int64, error) {
 	return i.wayRepository.SelectWayIDsFromNode(nodeID)
 }
@@ -0,0 +1,148 @@
+// Package access checks the api keys and the rate limits of the http and the gRPC server.
+// Both servers share one Access, so a client has the same budget on both of them.
+package access
+
+import (
+	"crypto/subtle"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/ratelimit"
+	"math"
+	"net"
+	"strings"
+	"time"
+)
+
+// Class decides which rate limit budget a request uses
+type Class int
+
+const (
+	Public    Class = iota // no api key and no rate limit, e.g. for errors
+	Cheap                  // search, locate, nearest, tiles
+	Expensive              // route, round trip, table, match, matrix
+	Batch                  // batch routes, every item uses a token of the expensive budget
+)
+
+type Access interface {
+	// Authenticate returns the configured api key matching key, it fails if keys are configured and key is missing or unknown
+	Authenticate(key string) (string, bool)
+	// Allow takes a token of the class from the bucket of the api key, if not empty, and from the bucket of the ip.
+	// If a bucket is empty, it returns the time until a token is available and takes no token from the other bucket.
+	Allow(class Class, key string, ip string) (bool, time.Duration)
+	// ClientIP returns the ip of the client, remoteAddr is the address of the peer and forwardedFor are the X-Forwarded-For headers
+	ClientIP(remoteAddr string, forwardedFor []string) string
+}
+
+// limiters holds one limiter per class, a missing limiter does not limit
+type limiters map[Class]*ratelimit.Limiter
+
+type impl struct {
+	apiKeys           []string
+	trustForwardedFor bool
+	keyLimits         limiters
+	ipLimits          limiters
+}
+
+func New(serverConfig *config.ServerConfig) Access {
+	out := &impl{
+		apiKeys:           serverConfig.ApiKeys,
+		trustForwardedFor: serverConfig.TrustForwardedFor,
+		keyLimits:         make(limiters),
+		ipLimits:          make(limiters),
+	}
+
+	if serverConfig.RateLimits != nil {
+		out.keyLimits = newLimiters(serverConfig.RateLimits.PerKey)
+		out.ipLimits = newLimiters(serverConfig.RateLimits.PerIP)
+	}
+
+	return out
+}
+
+func newLimiters(classConfig *config.RateLimitClassConfig) limiters {
+	out := make(limiters)
+	if classConfig == nil {
+		return out
+	}
+
+	for class, bucket := range map[Class]*config.TokenBucketConfig{Cheap: classConfig.Cheap, Expensive: classConfig.Expensive} {
+		if bucket != nil {
+			out[class] = ratelimit.New(bucket.Rate, bucket.Burst)
+		}
+	}
+	return out
+}
+
+func (i *impl) Authenticate(key string) (string, bool) {
+	if len(i.apiKeys) == 0 {
+		// without configured keys, every request is limited by its ip only
+		return "", true
+	}
+
+	for _, apiKey := range i.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			return apiKey, true
+		}
+	}
+
+	return "", false
+}
+
+func (i *impl) Allow(class Class, key string, ip string) (bool, time.Duration) {
+	if key != "" {
+		if allowed, retryAfter := peek(i.keyLimits, class, "key:"+key); !allowed {
+			return false, retryAfter
+		}
+	}
+
+	if allowed, retryAfter := peek(i.ipLimits, class, "ip:"+ip); !allowed {
+		return false, retryAfter
+	}
+
+	if key != "" {
+		if allowed, retryAfter := take(i.keyLimits, class, "key:"+key); !allowed {
+			return false, retryAfter
+		}
+	}
+
+	return take(i.ipLimits, class, "ip:"+ip)
+}
+
+func peek(limits limiters, class Class, bucket string) (bool, time.Duration) {
+	limiter, ok := limits[class]
+	if !ok {
+		return true, 0
+	}
+
+	return limiter.Peek(bucket)
+}
+
+func take(limits limiters, class Class, bucket string) (bool, time.Duration) {
+	limiter, ok := limits[class]
+	if !ok {
+		return true, 0
+	}
+
+	return limiter.Allow(bucket)
+}
+
+// ClientIP uses the right-most X-Forwarded-For entry behind a proxy, as the proxy appends it.
+// The entries left of it are sent by the client and can be spoofed.
+func (i *impl) ClientIP(remoteAddr string, forwardedFor []string) string {
+	if i.trustForwardedFor && len(forwardedFor) > 0 {
+		entries := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+		if client := strings.TrimSpace(entries[len(entries)-1]); client != "" {
+			return client
+		}
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// RetryAfterSeconds rounds the wait up to whole seconds for the Retry-After header, it is at least one second
+func RetryAfterSeconds(retryAfter time.Duration) int64 {
+	return int64(math.Max(1, math.Ceil(retryAfter.Seconds())))
+}
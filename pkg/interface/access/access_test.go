@@ -0,0 +1,87 @@
+package access_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	checker := access.New(&config.ServerConfig{
+		ApiKeys: []string{"key"},
+		RateLimits: &config.RateLimitConfig{
+			PerKey: &config.RateLimitClassConfig{Expensive: &config.TokenBucketConfig{Rate: 0.001, Burst: 3}},
+			PerIP:  &config.RateLimitClassConfig{Expensive: &config.TokenBucketConfig{Rate: 0.001, Burst: 1}},
+		},
+	})
+
+	if _, ok := checker.Authenticate("other"); ok {
+		t.Errorf("expected an unknown key to fail")
+	}
+
+	key, ok := checker.Authenticate("key")
+	if !ok || key != "key" {
+		t.Fatalf("expected the configured key, got %q", key)
+	}
+
+	if allowed, _ := checker.Allow(access.Expensive, key, "203.0.113.7"); !allowed {
+		t.Fatalf("expected the first request to be allowed")
+	}
+
+	allowed, retryAfter := checker.Allow(access.Expensive, key, "203.0.113.7")
+	if allowed {
+		t.Fatalf("expected the ip bucket to be empty")
+	}
+	if access.RetryAfterSeconds(retryAfter) < 1 {
+		t.Errorf("expected to retry after at least a second, got %s", retryAfter)
+	}
+
+	// the rejected request did not use a token of the key bucket
+	for _, ip := range []string{"203.0.113.8", "203.0.113.9"} {
+		if allowed, _ := checker.Allow(access.Expensive, key, ip); !allowed {
+			t.Fatalf("expected the request of %s to be allowed", ip)
+		}
+	}
+
+	if allowed, _ := checker.Allow(access.Expensive, key, "203.0.113.10"); allowed {
+		t.Errorf("expected the key bucket to be empty")
+	}
+
+	// a request rejected by the key bucket does not use a token of the ip bucket
+	if allowed, _ := checker.Allow(access.Expensive, "", "203.0.113.10"); !allowed {
+		t.Errorf("expected the ip bucket to be full")
+	}
+
+	if allowed, _ := checker.Allow(access.Cheap, key, "203.0.113.7"); !allowed {
+		t.Errorf("expected classes without limit to be allowed")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	direct := access.New(&config.ServerConfig{})
+	proxied := access.New(&config.ServerConfig{TrustForwardedFor: true})
+
+	tests := []struct {
+		name         string
+		checker      access.Access
+		forwardedFor []string
+		expected     string
+	}{
+		{"peer", direct, []string{"198.51.100.1"}, "10.0.0.1"},
+		{"proxy", proxied, []string{"203.0.113.7"}, "203.0.113.7"},
+		{"spoofed entry", proxied, []string{"198.51.100.1, 203.0.113.7"}, "203.0.113.7"},
+		{"spoofed header", proxied, []string{"198.51.100.1", "203.0.113.7"}, "203.0.113.7"},
+		{"missing header", proxied, nil, "10.0.0.1"},
+	}
+
+	for _, test := range tests {
+		if ip := test.checker.ClientIP("10.0.0.1:4711", test.forwardedFor); ip != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, ip)
+		}
+	}
+
+	if access.RetryAfterSeconds(1500*time.Millisecond) != 2 {
+		t.Errorf("expected retry after to be rounded up")
+	}
+}
@@ -0,0 +1,139 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+const (
+	apiKeyMetadata     = "x-api-key"
+	retryAfterMetadata = "retry-after"
+)
+
+var (
+	requestsTotal = metrics.Default.NewCounterVec(
+		"gosmroutify_grpc_requests_total",
+		"Handled gRPC calls per method and status code.",
+		"method", "code",
+	)
+	requestDuration = metrics.Default.NewHistogramVec(
+		"gosmroutify_grpc_request_duration_seconds",
+		"Latency of the gRPC calls per method and status code.",
+		metrics.DefaultBuckets,
+		"method", "code",
+	)
+)
+
+// methodClasses maps the full method names to their class, batch calls are limited per item instead
+var methodClasses = map[string]access.Class{
+	"/gosmroutify.v1.Routing/Route":  access.Expensive,
+	"/gosmroutify.v1.Routing/Matrix": access.Expensive,
+	"/gosmroutify.v1.Routing/Search": access.Cheap,
+	"/gosmroutify.v1.Routing/Locate": access.Cheap,
+}
+
+// accessInterceptor applies the api keys and rate limits, that are shared with the http server, to gRPC calls
+type accessInterceptor struct {
+	access access.Access
+}
+
+func (a *accessInterceptor) unaryInterceptor(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	var response any
+	err := a.check(ctx, info.FullMethod)
+	if err == nil {
+		response, err = handler(ctx, request)
+	}
+
+	observe(info.FullMethod, err, start)
+	return response, err
+}
+
+// check authenticates a unary call and takes a token of its class
+func (a *accessInterceptor) check(ctx context.Context, method string) error {
+	class, ok := methodClasses[method]
+	if !ok {
+		_, err := a.authenticate(ctx)
+		return err
+	}
+
+	return a.allow(ctx, class, func(trailer metadata.MD) error {
+		return grpc.SetTrailer(ctx, trailer)
+	})
+}
+
+func (a *accessInterceptor) streamInterceptor(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	_, err := a.authenticate(stream.Context())
+	if err == nil {
+		err = handler(server, stream)
+	}
+
+	observe(info.FullMethod, err, start)
+	return err
+}
+
+// authenticate returns the api key of the call, it fails if keys are configured and the key is missing or unknown
+func (a *accessInterceptor) authenticate(ctx context.Context) (string, error) {
+	key, ok := a.access.Authenticate(firstMetadata(ctx, apiKeyMetadata))
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing or invalid api key")
+	}
+	return key, nil
+}
+
+// allow takes a token of the key and the ip bucket, it returns ResourceExhausted with a retry-after trailer if one is empty.
+// The trailer is set with setTrailer, as streams set their trailer on the stream instead of the context.
+func (a *accessInterceptor) allow(ctx context.Context, class access.Class, setTrailer func(trailer metadata.MD) error) error {
+	key, err := a.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+
+	allowed, retryAfter := a.access.Allow(class, key, a.clientIP(ctx))
+	if allowed {
+		return nil
+	}
+
+	seconds := fmt.Sprintf("%d", access.RetryAfterSeconds(retryAfter))
+	err = setTrailer(metadata.Pairs(retryAfterMetadata, seconds))
+	if err != nil {
+		return status.Errorf(codes.Internal, "error while setting retry-after trailer: %s", err.Error())
+	}
+
+	return status.Error(codes.ResourceExhausted, "too many requests")
+}
+
+func (a *accessInterceptor) clientIP(ctx context.Context) string {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+
+	return a.access.ClientIP(remoteAddr, metadata.ValueFromIncomingContext(ctx, "x-forwarded-for"))
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// observe records a call, the method is the full method name of the service definition to keep the label set small
+func observe(method string, err error, start time.Time) {
+	code := status.Code(err).String()
+	requestsTotal.With(method, code).Inc()
+	requestDuration.With(method, code).ObserveSince(start)
+}
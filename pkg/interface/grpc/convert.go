@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/grpc/routingpb"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"time"
+)
+
+func fromCoordinate(coordinate *routingpb.Coordinate) geojson.Point {
+	return geojson.NewPoint(coordinate.GetLon(), coordinate.GetLat())
+}
+
+func toCoordinate(point geojson.Point) *routingpb.Coordinate {
+	// geojson points are stored as [lon, lat]
+	return &routingpb.Coordinate{Lon: point[0], Lat: point[1]}
+}
+
+func toCoordinates(points []geojson.Point) []*routingpb.Coordinate {
+	out := make([]*routingpb.Coordinate, len(points))
+	for index, point := range points {
+		out[index] = toCoordinate(point)
+	}
+	return out
+}
+
+func fromWaypoint(waypoint *routingpb.Waypoint) router.Waypoint {
+	out := router.Waypoint{
+		HeadingTolerance: waypoint.GetHeadingTolerance(),
+		Radius:           waypoint.GetRadius(),
+		NodeID:           waypoint.GetNodeId(),
+	}
+
+	if waypoint.Location != nil {
+		out.Location = fromCoordinate(waypoint.Location)
+	}
+
+	if waypoint.Heading != nil {
+		heading := waypoint.GetHeading()
+		out.Heading = &heading
+	}
+
+	return out
+}
+
+func fromRouteOptions(options *routingpb.RouteOptions) router.RouteOptions {
+	out := router.RouteOptions{
+		Profile: options.GetProfile(),
+		Dimensions: weightRepository.VehicleDimensions{
+			Height:   options.GetDimensions().GetHeight(),
+			Width:    options.GetDimensions().GetWidth(),
+			Length:   options.GetDimensions().GetLength(),
+			Weight:   options.GetDimensions().GetWeight(),
+			AxleLoad: options.GetDimensions().GetAxleLoad(),
+		},
+	}
+
+	if options.GetDeparture() != 0 {
+		out.Departure = time.Unix(options.GetDeparture(), 0)
+	}
+
+	for _, area := range options.GetAvoidAreas() {
+		polygon := make(geojson.Polygon, len(area.GetRings()))
+		for index, ring := range area.GetRings() {
+			polygon[index] = make([]geojson.Point, len(ring.GetPoints()))
+			for pointIndex, point := range ring.GetPoints() {
+				polygon[index][pointIndex] = fromCoordinate(point)
+			}
+		}
+		out.AvoidAreas = append(out.AvoidAreas, polygon)
+	}
+
+	return out
+}
+
+func toRoute(in route.Route) *routingpb.Route {
+	out := &routingpb.Route{
+		Waypoints: toCoordinates(in.Waypoints),
+		Distance:  in.LengthInMeters,
+		Time:      in.LengthInTime,
+		Segments:  make([]*routingpb.RouteSegment, len(in.Segments)),
+	}
+
+	for index, segment := range in.Segments {
+		out.Segments[index] = toRouteSegment(segment)
+	}
+
+	return out
+}
+
+func toRouteSegment(segment route.SegmentInfo) *routingpb.RouteSegment {
+	out := &routingpb.RouteSegment{
+		Distance: segment.LengthInMeters,
+		Time:     segment.LengthInTime,
+		Ascent:   segment.Ascent,
+		Descent:  segment.Descent,
+	}
+
+	for _, point := range segment.ElevationProfile {
+		out.ElevationProfile = append(out.ElevationProfile, &routingpb.ElevationPoint{Distance: point[0], Elevation: point[1]})
+	}
+
+	for _, line := range segment.Lines() {
+		out.Lines = append(out.Lines, &routingpb.Line{Points: toCoordinates(line)})
+	}
+
+	for _, leg := range segment.Legs {
+		out.Legs = append(out.Legs, &routingpb.RouteLeg{
+			Mode:      leg.Mode,
+			Route:     leg.Route,
+			Headsign:  leg.Headsign,
+			From:      leg.From,
+			To:        leg.To,
+			Departure: leg.Departure.Unix(),
+			Arrival:   leg.Arrival.Unix(),
+			Distance:  leg.LengthInMeters,
+		})
+	}
+
+	return out
+}
+
+func toAddress(a *address.Address) *routingpb.Address {
+	return &routingpb.Address{
+		OsmId:       a.OsmID,
+		Housenumber: a.Housenumber,
+		Street:      a.Street,
+		City:        a.City,
+		Postcode:    a.Postcode,
+		Country:     a.Country,
+		Suburb:      a.Suburb,
+		State:       a.State,
+		Province:    a.Province,
+		Floor:       a.Floor,
+		Name:        a.Name,
+	}
+}
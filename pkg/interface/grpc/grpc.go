@@ -0,0 +1,213 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/grpc/routingpb"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchItems limits the route requests of a single batch
+const maxBatchItems = 10000
+
+type impl struct {
+	routingpb.UnimplementedRoutingServer
+
+	logger      logging.Logger
+	application router.Application
+	access      *accessInterceptor
+}
+
+// NewGrpcServer creates the gRPC server of the routing service, serverAccess should be the one of the http server,
+// so that both servers share the rate limit budgets
+func NewGrpcServer(
+	logger logging.Logger,
+	application router.Application,
+	serverAccess access.Access,
+) (*grpc.Server, error) {
+	server := &impl{
+		logger:      logger,
+		application: application,
+		access:      &accessInterceptor{access: serverAccess},
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.access.unaryInterceptor),
+		grpc.ChainStreamInterceptor(server.access.streamInterceptor),
+	)
+	routingpb.RegisterRoutingServer(grpcServer, server)
+	// reflection lets tools like grpcurl call the service without the .proto file
+	reflection.Register(grpcServer)
+
+	return grpcServer, nil
+}
+
+// toStatus converts an error of the application to the status code, the http api would answer with
+func (i *impl) toStatus(err error) error {
+	var fieldError *router.FieldError
+
+	switch {
+	case errors.As(err, &fieldError):
+		return status.Errorf(codes.InvalidArgument, "%s: %s", fieldError.Field, fieldError.Message)
+	case errors.Is(err, router.ErrInvalidRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, router.ErrNoNearNode), errors.Is(err, router.ErrNoRoute):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, router.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		i.logger.Error().Msgf("error while answering grpc request: %s", err.Error())
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func (i *impl) findRoutes(request *routingpb.RouteRequest) (*routingpb.RouteResponse, error) {
+	waypoints := make([]router.Waypoint, len(request.GetWaypoints()))
+	for index, waypoint := range request.GetWaypoints() {
+		waypoints[index] = fromWaypoint(waypoint)
+	}
+
+	options := fromRouteOptions(request.GetOptions())
+	options.Alternatives = int(request.GetAlternatives())
+
+	routes, err := i.application.FindRoutes(waypoints, options)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &routingpb.RouteResponse{Routes: make([]*routingpb.Route, len(routes))}
+	for index, route := range routes {
+		response.Routes[index] = toRoute(route)
+	}
+
+	return response, nil
+}
+
+func (i *impl) Route(ctx context.Context, request *routingpb.RouteRequest) (*routingpb.RouteResponse, error) {
+	response, err := i.findRoutes(request)
+	if err != nil {
+		return nil, i.toStatus(err)
+	}
+
+	return response, nil
+}
+
+func (i *impl) BatchRoute(request *routingpb.BatchRouteRequest, stream routingpb.Routing_BatchRouteServer) error {
+	if len(request.GetItems()) > maxBatchItems {
+		return status.Errorf(codes.InvalidArgument, "items: at most %d items are supported", maxBatchItems)
+	}
+
+	for _, item := range request.GetItems() {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		// every item uses a token, like a single request
+		err := i.access.allow(stream.Context(), access.Expensive, func(trailer metadata.MD) error {
+			stream.SetTrailer(trailer)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		result := &routingpb.BatchRouteResult{Id: item.GetId()}
+
+		response, err := i.findRoutes(item.GetRequest())
+		if err != nil {
+			result.Result = &routingpb.BatchRouteResult_Error{Error: i.toError(err)}
+		} else {
+			result.Result = &routingpb.BatchRouteResult_Response{Response: response}
+		}
+
+		err = stream.Send(result)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toError converts an error of a batch item, that does not fail the whole batch
+func (i *impl) toError(err error) *routingpb.Error {
+	out := &routingpb.Error{}
+
+	var fieldError *router.FieldError
+	if errors.As(err, &fieldError) {
+		out.Field = fieldError.Field
+	}
+
+	grpcStatus := status.Convert(i.toStatus(err))
+	out.Code = int32(grpcStatus.Code())
+	out.Message = grpcStatus.Message()
+	return out
+}
+
+func (i *impl) Search(ctx context.Context, request *routingpb.SearchRequest) (*routingpb.SearchResponse, error) {
+	addresses, err := i.application.FindAddresses(request.GetQuery())
+	if err != nil {
+		return nil, i.toStatus(err)
+	}
+
+	response := &routingpb.SearchResponse{Addresses: make([]*routingpb.Address, len(addresses))}
+	for index, a := range addresses {
+		response.Addresses[index] = toAddress(a)
+	}
+
+	return response, nil
+}
+
+func (i *impl) Locate(ctx context.Context, request *routingpb.LocateRequest) (*routingpb.LocateResponse, error) {
+	location, err := i.application.LocateAddressByID(request.GetId())
+	if err != nil {
+		return nil, i.toStatus(err)
+	}
+
+	return &routingpb.LocateResponse{Location: toCoordinate(location)}, nil
+}
+
+func (i *impl) Matrix(ctx context.Context, request *routingpb.MatrixRequest) (*routingpb.MatrixResponse, error) {
+	sources := make([]geojson.Point, len(request.GetSources()))
+	for index, source := range request.GetSources() {
+		sources[index] = fromCoordinate(source)
+	}
+
+	destinations := make([]geojson.Point, len(request.GetDestinations()))
+	for index, destination := range request.GetDestinations() {
+		destinations[index] = fromCoordinate(destination)
+	}
+
+	matrix, err := i.application.FindMatrix(sources, destinations, fromRouteOptions(request.GetOptions()))
+	if err != nil {
+		return nil, i.toStatus(err)
+	}
+
+	response := &routingpb.MatrixResponse{Rows: make([]*routingpb.MatrixRow, len(matrix))}
+	for sourceIndex, row := range matrix {
+		cells := make([]*routingpb.MatrixCell, len(row))
+		for destinationIndex, cell := range row {
+			if cell == nil {
+				cells[destinationIndex] = &routingpb.MatrixCell{}
+				continue
+			}
+
+			cells[destinationIndex] = &routingpb.MatrixCell{
+				Reachable: true,
+				Distance:  cell.LengthInMeters,
+				Time:      cell.LengthInTime,
+			}
+		}
+		response.Rows[sourceIndex] = &routingpb.MatrixRow{Cells: cells}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,297 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.0
+// source: routing.proto
+
+package routingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Routing_Route_FullMethodName      = "/gosmroutify.v1.Routing/Route"
+	Routing_BatchRoute_FullMethodName = "/gosmroutify.v1.Routing/BatchRoute"
+	Routing_Search_FullMethodName     = "/gosmroutify.v1.Routing/Search"
+	Routing_Locate_FullMethodName     = "/gosmroutify.v1.Routing/Locate"
+	Routing_Matrix_FullMethodName     = "/gosmroutify.v1.Routing/Matrix"
+)
+
+// RoutingClient is the client API for Routing service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RoutingClient interface {
+	// Route finds the best route through the waypoints and optionally alternative routes
+	Route(ctx context.Context, in *RouteRequest, opts ...grpc.CallOption) (*RouteResponse, error)
+	// BatchRoute answers many route requests, the results are streamed in the order of the requests.
+	// A failing request does not stop the batch, its result carries the error instead.
+	BatchRoute(ctx context.Context, in *BatchRouteRequest, opts ...grpc.CallOption) (Routing_BatchRouteClient, error)
+	// Search finds addresses matching a free text query
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Locate returns the position of an address found by Search
+	Locate(ctx context.Context, in *LocateRequest, opts ...grpc.CallOption) (*LocateResponse, error)
+	// Matrix finds the best route from every source to every destination
+	Matrix(ctx context.Context, in *MatrixRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+}
+
+type routingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRoutingClient(cc grpc.ClientConnInterface) RoutingClient {
+	return &routingClient{cc}
+}
+
+func (c *routingClient) Route(ctx context.Context, in *RouteRequest, opts ...grpc.CallOption) (*RouteResponse, error) {
+	out := new(RouteResponse)
+	err := c.cc.Invoke(ctx, Routing_Route_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routingClient) BatchRoute(ctx context.Context, in *BatchRouteRequest, opts ...grpc.CallOption) (Routing_BatchRouteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Routing_ServiceDesc.Streams[0], Routing_BatchRoute_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routingBatchRouteClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Routing_BatchRouteClient interface {
+	Recv() (*BatchRouteResult, error)
+	grpc.ClientStream
+}
+
+type routingBatchRouteClient struct {
+	grpc.ClientStream
+}
+
+func (x *routingBatchRouteClient) Recv() (*BatchRouteResult, error) {
+	m := new(BatchRouteResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *routingClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, Routing_Search_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routingClient) Locate(ctx context.Context, in *LocateRequest, opts ...grpc.CallOption) (*LocateResponse, error) {
+	out := new(LocateResponse)
+	err := c.cc.Invoke(ctx, Routing_Locate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routingClient) Matrix(ctx context.Context, in *MatrixRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, Routing_Matrix_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RoutingServer is the server API for Routing service.
+// All implementations must embed UnimplementedRoutingServer
+// for forward compatibility
+type RoutingServer interface {
+	// Route finds the best route through the waypoints and optionally alternative routes
+	Route(context.Context, *RouteRequest) (*RouteResponse, error)
+	// BatchRoute answers many route requests, the results are streamed in the order of the requests.
+	// A failing request does not stop the batch, its result carries the error instead.
+	BatchRoute(*BatchRouteRequest, Routing_BatchRouteServer) error
+	// Search finds addresses matching a free text query
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Locate returns the position of an address found by Search
+	Locate(context.Context, *LocateRequest) (*LocateResponse, error)
+	// Matrix finds the best route from every source to every destination
+	Matrix(context.Context, *MatrixRequest) (*MatrixResponse, error)
+	mustEmbedUnimplementedRoutingServer()
+}
+
+// UnimplementedRoutingServer must be embedded to have forward compatible implementations.
+type UnimplementedRoutingServer struct {
+}
+
+func (UnimplementedRoutingServer) Route(context.Context, *RouteRequest) (*RouteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Route not implemented")
+}
+func (UnimplementedRoutingServer) BatchRoute(*BatchRouteRequest, Routing_BatchRouteServer) error {
+	return status.Errorf(codes.Unimplemented, "method BatchRoute not implemented")
+}
+func (UnimplementedRoutingServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedRoutingServer) Locate(context.Context, *LocateRequest) (*LocateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Locate not implemented")
+}
+func (UnimplementedRoutingServer) Matrix(context.Context, *MatrixRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Matrix not implemented")
+}
+func (UnimplementedRoutingServer) mustEmbedUnimplementedRoutingServer() {}
+
+// UnsafeRoutingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RoutingServer will
+// result in compilation errors.
+type UnsafeRoutingServer interface {
+	mustEmbedUnimplementedRoutingServer()
+}
+
+func RegisterRoutingServer(s grpc.ServiceRegistrar, srv RoutingServer) {
+	s.RegisterService(&Routing_ServiceDesc, srv)
+}
+
+func _Routing_Route_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoutingServer).Route(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Routing_Route_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoutingServer).Route(ctx, req.(*RouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Routing_BatchRoute_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchRouteRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RoutingServer).BatchRoute(m, &routingBatchRouteServer{stream})
+}
+
+type Routing_BatchRouteServer interface {
+	Send(*BatchRouteResult) error
+	grpc.ServerStream
+}
+
+type routingBatchRouteServer struct {
+	grpc.ServerStream
+}
+
+func (x *routingBatchRouteServer) Send(m *BatchRouteResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Routing_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoutingServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Routing_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoutingServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Routing_Locate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LocateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoutingServer).Locate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Routing_Locate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoutingServer).Locate(ctx, req.(*LocateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Routing_Matrix_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatrixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoutingServer).Matrix(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Routing_Matrix_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoutingServer).Matrix(ctx, req.(*MatrixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Routing_ServiceDesc is the grpc.ServiceDesc for Routing service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Routing_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gosmroutify.v1.Routing",
+	HandlerType: (*RoutingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Route",
+			Handler:    _Routing_Route_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _Routing_Search_Handler,
+		},
+		{
+			MethodName: "Locate",
+			Handler:    _Routing_Locate_Handler,
+		},
+		{
+			MethodName: "Matrix",
+			Handler:    _Routing_Matrix_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchRoute",
+			Handler:       _Routing_BatchRoute_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "routing.proto",
+}
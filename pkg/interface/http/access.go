@@ -0,0 +1,91 @@
+package http
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyHeader     = "X-Api-Key"
+	apiKeyQueryParam = "apiKey" // for clients, that can not set headers, e.g. map libraries loading tiles
+)
+
+// protect applies cors, the api key check and the rate limits of the class before calling the handler
+func (i *impl) protect(class access.Class, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		i.cors(w, r)
+
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+apiKeyHeader)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if class == access.Public {
+			handler(w, r)
+			return
+		}
+
+		key, ok := i.authenticate(r)
+		if !ok {
+			http.Error(w, "missing or invalid api key", http.StatusUnauthorized)
+			return
+		}
+
+		allowed, retryAfter := i.access.Allow(class, key, i.clientIP(r))
+		if !allowed {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", access.RetryAfterSeconds(retryAfter)))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// cors allows the origin of the request, if it is configured
+func (i *impl) cors(w http.ResponseWriter, r *http.Request) {
+	if len(i.corsOrigins) == 0 {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	w.Header().Add("Vary", "Origin")
+
+	origin := r.Header.Get("Origin")
+	for _, allowed := range i.corsOrigins {
+		if allowed == "*" {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			return
+		}
+
+		if origin != "" && strings.EqualFold(allowed, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			return
+		}
+	}
+}
+
+// authenticate returns the api key of the request, it fails if keys are configured and the key is missing or unknown
+func (i *impl) authenticate(r *http.Request) (string, bool) {
+	key := r.Header.Get(apiKeyHeader)
+	if key == "" {
+		key = r.URL.Query().Get(apiKeyQueryParam)
+	}
+
+	return i.access.Authenticate(key)
+}
+
+// allowItem takes a token of the key and the ip bucket for a part of an authenticated request, e.g. a batch item
+func (i *impl) allowItem(r *http.Request, class access.Class) (bool, time.Duration) {
+	key, _ := i.authenticate(r)
+	return i.access.Allow(class, key, i.clientIP(r))
+}
+
+func (i *impl) clientIP(r *http.Request) string {
+	return i.access.ClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"))
+}
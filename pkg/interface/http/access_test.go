@@ -0,0 +1,66 @@
+package http_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/address"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	httpInterface "github.com/paulkoehlerdev/gosmRoutify/pkg/interface/http"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// searchApplication answers every search without results
+type searchApplication struct {
+	router.Application
+}
+
+func (searchApplication) FindAddresses(string) ([]*address.Address, error) {
+	return nil, nil
+}
+
+func TestSpoofedForwardedFor(t *testing.T) {
+	serverConfig := &config.ServerConfig{
+		TrustForwardedFor: true,
+		RateLimits: &config.RateLimitConfig{
+			PerIP: &config.RateLimitClassConfig{Cheap: &config.TokenBucketConfig{Rate: 0.001, Burst: 1}},
+		},
+	}
+
+	server, err := httpInterface.NewHttpServer(logging.New(logging.LevelError, io.Discard), searchApplication{}, serverConfig, access.New(serverConfig), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	search := func(forwardedFor ...string) int {
+		request := httptest.NewRequest(http.MethodGet, "/api/search?q=a", nil)
+		request.RemoteAddr = "10.0.0.1:4711"
+		for _, value := range forwardedFor {
+			request.Header.Add("X-Forwarded-For", value)
+		}
+
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	if code := search("203.0.113.7"); code != http.StatusOK {
+		t.Fatalf("expected the first request to be allowed, got %d", code)
+	}
+
+	// the proxy appends the address of the client to the header sent by the client
+	if code := search("198.51.100.1, 203.0.113.7"); code != http.StatusTooManyRequests {
+		t.Errorf("expected a spoofed entry to use the bucket of the client, got %d", code)
+	}
+
+	if code := search("198.51.100.2", "203.0.113.7"); code != http.StatusTooManyRequests {
+		t.Errorf("expected a spoofed header to use the bucket of the client, got %d", code)
+	}
+
+	if code := search("203.0.113.7, 203.0.113.8"); code != http.StatusOK {
+		t.Errorf("expected another client to be allowed, got %d", code)
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/closure"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const maxAdminBodySize = 1 << 20
+
+// authorize checks the bearer token of admin requests and writes the error response, if it is missing or wrong
+func (i *impl) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if i.adminToken == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(i.adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+func (i *impl) closures(w http.ResponseWriter, r *http.Request) {
+	if !i.authorize(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		closures := i.application.GetClosures()
+		if closures == nil {
+			closures = []*closure.Closure{}
+		}
+		i.writeJSON(w, http.StatusOK, closures)
+
+	case http.MethodPost:
+		var c closure.Closure
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBodySize))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&c)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid closure: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+
+		created, err := i.application.AddClosure(c)
+		if errors.Is(err, router.ErrInvalidRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err != nil {
+			i.logger.Error().Msgf("error while adding closure: %s", err.Error())
+			http.Error(w, "error while adding closure", http.StatusInternalServerError)
+			return
+		}
+
+		i.logger.Info().Msgf("added closure %d", created.ID)
+		i.writeJSON(w, http.StatusCreated, created)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+
+		err = i.application.RemoveClosure(id)
+		if errors.Is(err, router.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		if err != nil {
+			i.logger.Error().Msgf("error while removing closure: %s", err.Error())
+			http.Error(w, "error while removing closure", http.StatusInternalServerError)
+			return
+		}
+
+		i.logger.Info().Msgf("removed closure %d", id)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (i *impl) writeJSON(w http.ResponseWriter, status int, value any) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		i.logger.Error().Msgf("error while marshalling response: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(valueBytes)
+	if err != nil {
+		i.logger.Error().Msgf("error while writing response: %s", err.Error())
+	}
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/workerpool"
+	"net/http"
+	"runtime"
+)
+
+// batchQueueSize limits the items, that are read ahead of the written results
+const batchQueueSize = 256
+
+// batchItem is a line of the body of POST /api/route/batch
+type batchItem struct {
+	ID      string          `json:"id"`
+	Request json.RawMessage `json:"request"` // the body of POST /api/route
+}
+
+// batchResult is a line of the response, it contains either the routes or the error of the item
+type batchResult struct {
+	ID     string         `json:"id"`
+	Line   int            `json:"line"`
+	Result *routeResponse `json:"result,omitempty"`
+	Error  *batchError    `json:"error,omitempty"`
+}
+
+type batchError struct {
+	Status int `json:"status"` // the status POST /api/route would answer with
+	route.ErrorMessage
+}
+
+func newBatchError(line int, id string, status int, err error) batchResult {
+	return batchResult{ID: id, Line: line, Error: &batchError{Status: status, ErrorMessage: newRouteError(err)}}
+}
+
+// batchRoute reads route requests as NDJSON and writes the results as NDJSON in the same order.
+// The items are routed concurrently, while the body is still read and the results are streamed.
+func (i *impl) batchRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	// HTTP/1.x closes the body with the first write, unless the request is full duplex
+	err := controller.EnableFullDuplex()
+	if err != nil {
+		i.logger.Debug().Msgf("error while enabling full duplex: %s", err.Error())
+	}
+
+	pool := workerpool.New[batchResult](workerpool.ProcsCount(runtime.GOMAXPROCS(0)))
+	pool.Start()
+	defer pool.Stop()
+
+	submitted := make(chan struct{}, batchQueueSize)
+	go i.submitBatch(r, pool, submitted)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	failed := false
+
+	// results are returned in the order of submission, the loop drains all of them, so the reader can finish
+	for range submitted {
+		result, err := pool.Result()
+		if err != nil {
+			return
+		}
+
+		if failed {
+			continue
+		}
+
+		err = encoder.Encode(result)
+		if err == nil {
+			err = controller.Flush()
+		}
+
+		if err != nil {
+			i.logger.Error().Msgf("error while writing batch result: %s", err.Error())
+			failed = true
+		}
+	}
+}
+
+// submitBatch submits every line of the body to the pool and signals the submission, it closes submitted at the end
+func (i *impl) submitBatch(r *http.Request, pool workerpool.Pool[batchResult], submitted chan<- struct{}) {
+	defer close(submitted)
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRouteBodySize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		// the scanner reuses its buffer
+		data = append([]byte(nil), data...)
+		lineNumber := line
+
+		var item batchItem
+		err := decodeStrict(data, &item, "")
+		if err != nil {
+			pool.Submit(func(int) batchResult {
+				return newBatchError(lineNumber, "", http.StatusBadRequest, err)
+			})
+			submitted <- struct{}{}
+			continue
+		}
+
+		// every item uses a token, like a single request
+		allowed, retryAfter := i.allowItem(r, access.Expensive)
+		if !allowed {
+			pool.Submit(func(int) batchResult {
+				return newBatchError(lineNumber, item.ID, http.StatusTooManyRequests, fmt.Errorf("too many requests, retry after %d seconds", access.RetryAfterSeconds(retryAfter)))
+			})
+			submitted <- struct{}{}
+			continue
+		}
+
+		pool.Submit(func(int) batchResult {
+			return i.batchRouteItem(r, lineNumber, item)
+		})
+		submitted <- struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		pool.Submit(func(int) batchResult {
+			return newBatchError(line+1, "", http.StatusBadRequest, &router.FieldError{Field: "body", Message: err.Error()})
+		})
+		submitted <- struct{}{}
+	}
+}
+
+func (i *impl) batchRouteItem(r *http.Request, line int, item batchItem) batchResult {
+	if err := r.Context().Err(); err != nil {
+		// the client is gone, the result is not written anyway
+		return newBatchError(line, item.ID, http.StatusServiceUnavailable, err)
+	}
+
+	request, waypoints, options, err := parseRouteRequest(item.Request)
+	if err != nil {
+		return newBatchError(line, item.ID, http.StatusBadRequest, err)
+	}
+
+	if request.Format == formatGPX || request.Format == formatKML {
+		return newBatchError(line, item.ID, http.StatusBadRequest, &router.FieldError{Field: "format", Message: "gpx and kml are not supported in batches"})
+	}
+
+	routes, status, err := i.findRoutes(waypoints, options)
+	if err != nil {
+		return newBatchError(line, item.ID, status, err)
+	}
+
+	response := encodeRoutes(routes, request.Format)
+	return batchResult{ID: item.ID, Line: line, Result: &response}
+}
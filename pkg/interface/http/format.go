@@ -0,0 +1,100 @@
+package http
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geoformat"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"net/http"
+)
+
+const (
+	formatGeoJson   = "geojson"
+	formatPolyline  = "polyline"
+	formatPolyline6 = "polyline6"
+	formatGPX       = "gpx"
+	formatKML       = "kml"
+
+	routeName = "gosmRoutify route"
+)
+
+// polylineSegment replaces the geojson of a segment with an encoded polyline
+type polylineSegment struct {
+	route.SegmentInfo
+	GeoJson  *struct{} `json:"geojson,omitempty"` // hides the embedded geojson
+	Polyline string    `json:"polyline"`
+}
+
+func isValidFormat(format string) bool {
+	switch format {
+	case "", formatGeoJson, formatPolyline, formatPolyline6, formatGPX, formatKML:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeRoute writes the route in the requested format, the format has to be checked with isValidFormat before
+func (i *impl) writeRoute(w http.ResponseWriter, points []geojson.Point, segments []route.SegmentInfo, format string) {
+	switch format {
+	case formatPolyline, formatPolyline6:
+		precision := 5
+		if format == formatPolyline6 {
+			precision = 6
+		}
+
+		out := make([]polylineSegment, len(segments))
+		for index, segment := range segments {
+			out[index] = polylineSegment{
+				SegmentInfo: segment,
+				Polyline:    geoformat.EncodePolyline(joinLines(segment.Lines()), precision),
+			}
+		}
+		i.writeJSON(w, http.StatusOK, out)
+
+	case formatGPX, formatKML:
+		document := geoformat.Route{
+			Name:      routeName,
+			Waypoints: points,
+		}
+		for _, segment := range segments {
+			document.Segments = append(document.Segments, joinLines(segment.Lines()))
+		}
+
+		encode, contentType := geoformat.EncodeGPX, "application/gpx+xml"
+		if format == formatKML {
+			encode, contentType = geoformat.EncodeKML, "application/vnd.google-earth.kml+xml"
+		}
+
+		out, err := encode(document)
+		if err != nil {
+			i.logger.Error().Msgf("error while encoding route: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"route.%s\"", format))
+		_, err = w.Write(out)
+		if err != nil {
+			i.logger.Error().Msgf("error while writing response: %s", err.Error())
+		}
+
+	default:
+		i.writeJSON(w, http.StatusOK, segments)
+	}
+}
+
+// joinLines concatenates the lines of a segment, dropping points shared by consecutive lines
+func joinLines(lines [][]geojson.Point) []geojson.Point {
+	var out []geojson.Point
+	for _, line := range lines {
+		for _, point := range line {
+			if len(out) > 0 && out[len(out)-1] == point {
+				continue
+			}
+			out = append(out, point)
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ReadinessCheck is a named check, that has to pass before the server is ready to answer requests
+type ReadinessCheck struct {
+	Name  string
+	Check func() error
+}
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// healthz reports, that the process is alive
+func (i *impl) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, err := w.Write([]byte("ok\n"))
+	if err != nil {
+		i.logger.Error().Msgf("error while writing response: %s", err.Error())
+	}
+}
+
+// readyz runs the readiness checks and reports 503, if one of them fails
+func (i *impl) readyz(w http.ResponseWriter, r *http.Request) {
+	response := readinessResponse{
+		Status: "ready",
+		Checks: make(map[string]string, len(i.readinessChecks)),
+	}
+	status := http.StatusOK
+
+	for _, check := range i.readinessChecks {
+		err := check.Check()
+		if err != nil {
+			i.logger.Warn().Msgf("readiness check %s failed: %s", check.Name, err.Error())
+			response.Checks[check.Name] = err.Error()
+			response.Status = "not ready"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		response.Checks[check.Name] = "ok"
+	}
+
+	out, err := json.Marshal(response)
+	if err != nil {
+		i.logger.Error().Msgf("error while marshalling readiness: %s", err.Error())
+		http.Error(w, "error while marshalling readiness", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(out)
+	if err != nil {
+		i.logger.Error().Msgf("error while writing response: %s", err.Error())
+	}
+}
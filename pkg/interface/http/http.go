@@ -3,37 +3,74 @@ package http
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 )
 
 type impl struct {
 	logger      logging.Logger
 	application router.Application
+	adminToken  string
+
+	readinessChecks []ReadinessCheck
+
+	corsOrigins []string
+	access      access.Access
 }
 
 func NewHttpServer(
 	logger logging.Logger,
 	application router.Application,
 	serverConfig *config.ServerConfig,
+	serverAccess access.Access,
+	readinessChecks []ReadinessCheck,
 ) (*http.Server, error) {
 	mux := &http.ServeMux{}
 
 	server := &impl{
 		logger:      logger,
 		application: application,
+		adminToken:  serverConfig.AdminToken,
+
+		readinessChecks: readinessChecks,
+
+		corsOrigins: serverConfig.CorsOrigins,
+		access:      serverAccess,
 	}
 
-	mux.HandleFunc("/api/route", server.route)
-	mux.HandleFunc("/api/locate", server.locate)
-	mux.HandleFunc("/api/search", server.search)
+	handle(mux, "/api/route", server.protect(access.Expensive, server.route))
+	handle(mux, "/api/route/batch", server.protect(access.Batch, server.batchRoute))
+	handle(mux, "/api/roundtrip", server.protect(access.Expensive, server.roundTrip))
+	handle(mux, "/api/locate", server.protect(access.Cheap, server.locate))
+	handle(mux, "/api/search", server.protect(access.Cheap, server.search))
+
+	handle(mux, "/api/admin/closures", server.closures)
 
-	mux.HandleFunc("/", server.root)
+	// Mapbox vector tiles of the road graph
+	handle(mux, "/tiles/", server.protect(access.Cheap, server.tiles))
+
+	// OSRM v5 compatible services
+	handle(mux, "/route/", server.protect(access.Expensive, server.osrmRoute))
+	handle(mux, "/nearest/", server.protect(access.Cheap, server.osrmNearest))
+	handle(mux, "/table/", server.protect(access.Expensive, server.osrmTable))
+	handle(mux, "/match/", server.protect(access.Expensive, server.osrmMatch))
+
+	mux.HandleFunc("/metrics", server.metrics)
+	mux.HandleFunc("/healthz", server.healthz)
+	mux.HandleFunc("/readyz", server.readyz)
+
+	handle(mux, "/", server.protect(access.Public, server.root))
 
 	return &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", serverConfig.Host, serverConfig.Port),
@@ -41,17 +78,26 @@ func NewHttpServer(
 	}, nil
 }
 
-func cors(w *http.ResponseWriter) {
-	(*w).Header().Set("Access-Control-Allow-Origin", "*")
+// handle registers an instrumented handler
+func handle(mux *http.ServeMux, pattern string, handler http.HandlerFunc) {
+	mux.HandleFunc(pattern, instrument(pattern, handler))
 }
 
 func (i *impl) root(w http.ResponseWriter, r *http.Request) {
-	cors(&w)
 	http.Error(w, "not found", http.StatusNotFound)
 }
 
 func (i *impl) route(w http.ResponseWriter, r *http.Request) {
-	cors(&w)
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost:
+		i.postRoute(w, r)
+		return
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
 	routeQueryB64 := r.URL.Query().Get("r")
 
@@ -73,22 +119,141 @@ func (i *impl) route(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	route, err := i.application.FindRoute(points)
+	format := r.URL.Query().Get("format")
+	if !isValidFormat(format) {
+		http.Error(w, fmt.Sprintf("invalid format: %s", format), http.StatusBadRequest)
+		return
+	}
+
+	dimensions, err := parseDimensions(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var departure time.Time
+	if value := r.URL.Query().Get("departure"); value != "" {
+		departure, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid departure: %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var avoidAreas []geojson.Polygon
+	if value := r.URL.Query().Get("avoid"); value != "" {
+		avoidAreas, err = parseAvoidAreas(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	route, err := i.application.FindRoute(points, router.RouteOptions{
+		Profile:    r.URL.Query().Get("profile"),
+		Dimensions: dimensions,
+		Departure:  departure,
+		AvoidAreas: avoidAreas,
+	})
+	if errors.Is(err, router.ErrInvalidRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err != nil {
 		i.logger.Error().Msgf("error while finding route: %s", err.Error())
 		http.Error(w, fmt.Sprintf("error while finding route: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
 
-	routeBytes, err := json.Marshal(route)
+	i.writeRoute(w, points, route, format)
+}
+
+func (i *impl) roundTrip(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	startQuery, err := base64.URLEncoding.DecodeString(query.Get("r"))
 	if err != nil {
-		i.logger.Error().Msgf("error while marshalling route: %s", err.Error())
+		http.Error(w, "invalid round trip query", http.StatusBadRequest)
+		return
+	}
+
+	var start geojson.Point
+	err = json.Unmarshal(startQuery, &start)
+	if err != nil {
+		http.Error(w, "invalid round trip query", http.StatusBadRequest)
+		return
+	}
+
+	dimensions, err := parseDimensions(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	options := router.RoundTripOptions{
+		Profile:    query.Get("profile"),
+		Dimensions: dimensions,
+	}
+
+	numbers := []struct {
+		name  string
+		value *float64
+	}{
+		{"distance", &options.Distance},
+		{"duration", &options.Duration},
+	}
+
+	for _, number := range numbers {
+		value := query.Get(number.name)
+		if value == "" {
+			continue
+		}
+
+		*number.value, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s: %s", number.name, value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if value := query.Get("seed"); value != "" {
+		options.Seed, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid seed: %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if value := query.Get("candidates"); value != "" {
+		options.Candidates, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid candidates: %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	roundTrips, err := i.application.FindRoundTrips(start, options)
+	if errors.Is(err, router.ErrInvalidRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		i.logger.Error().Msgf("error while finding round trips: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error while finding round trips: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	roundTripBytes, err := json.Marshal(roundTrips)
+	if err != nil {
+		i.logger.Error().Msgf("error while marshalling round trips: %s", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(routeBytes)
+	_, err = w.Write(roundTripBytes)
 	if err != nil {
 		i.logger.Error().Msgf("error while writing response: %s", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -96,9 +261,54 @@ func (i *impl) route(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (i *impl) locate(w http.ResponseWriter, r *http.Request) {
-	cors(&w)
+// parseAvoidAreas reads the polygons of base64 encoded geojson
+func parseAvoidAreas(value string) ([]geojson.Polygon, error) {
+	areaQuery, err := base64.URLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avoid areas: %s", err.Error())
+	}
+
+	areas, err := geojson.ParsePolygons(areaQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avoid areas: %s", err.Error())
+	}
+
+	return areas, nil
+}
 
+// parseDimensions reads the optional vehicle dimensions in meters and tonnes
+func parseDimensions(query url.Values) (weightRepository.VehicleDimensions, error) {
+	var dimensions weightRepository.VehicleDimensions
+
+	parameters := []struct {
+		name  string
+		value *float64
+	}{
+		{"height", &dimensions.Height},
+		{"width", &dimensions.Width},
+		{"length", &dimensions.Length},
+		{"weight", &dimensions.Weight},
+		{"axleload", &dimensions.AxleLoad},
+	}
+
+	for _, parameter := range parameters {
+		value := query.Get(parameter.name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return dimensions, fmt.Errorf("invalid %s: %s", parameter.name, value)
+		}
+
+		*parameter.value = parsed
+	}
+
+	return dimensions, nil
+}
+
+func (i *impl) locate(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 
 	idInt, err := strconv.ParseInt(id, 10, 64)
@@ -131,8 +341,6 @@ func (i *impl) locate(w http.ResponseWriter, r *http.Request) {
 }
 
 func (i *impl) search(w http.ResponseWriter, r *http.Request) {
-	cors(&w)
-
 	query := r.URL.Query().Get("q")
 
 	addresses, err := i.application.FindAddresses(query)
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	requestsTotal = metrics.Default.NewCounterVec(
+		"gosmroutify_http_requests_total",
+		"Handled HTTP requests per endpoint and status.",
+		"endpoint", "status",
+	)
+	requestDuration = metrics.Default.NewHistogramVec(
+		"gosmroutify_http_request_duration_seconds",
+		"Latency of the HTTP requests per endpoint and status.",
+		metrics.DefaultBuckets,
+		"endpoint", "status",
+	)
+)
+
+// statusRecorder remembers the status written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController flush the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// instrument records the requests of a handler, the endpoint is the registered pattern to keep the label set small
+func instrument(endpoint string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		handler(recorder, r)
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		status := strconv.Itoa(recorder.status)
+		requestsTotal.With(endpoint, status).Inc()
+		requestDuration.With(endpoint, status).ObserveSince(start)
+	}
+}
+
+// metrics serves the metrics in the Prometheus text exposition format
+func (i *impl) metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	err := metrics.Default.WriteText(w)
+	if err != nil {
+		i.logger.Error().Msgf("error while writing metrics: %s", err.Error())
+	}
+}
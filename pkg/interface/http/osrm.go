@@ -0,0 +1,528 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geoformat"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	osrmVersion             = "v1"
+	maxOsrmCoordinates      = 100
+	maxOsrmTableCoordinates = 25
+	maxOsrmNearest          = 100
+)
+
+// osrm error codes, see the OSRM v5 HTTP API documentation
+const (
+	osrmOk             = "Ok"
+	osrmInvalidUrl     = "InvalidUrl"
+	osrmInvalidService = "InvalidService"
+	osrmInvalidVersion = "InvalidVersion"
+	osrmInvalidOptions = "InvalidOptions"
+	osrmInvalidQuery   = "InvalidQuery"
+	osrmInvalidValue   = "InvalidValue"
+	osrmNoSegment      = "NoSegment"
+	osrmNoRoute        = "NoRoute"
+	osrmNoMatch        = "NoMatch"
+	osrmTooBig         = "TooBig"
+)
+
+// osrmProfiles maps the OSRM profile names to the built-in profiles, other names are used as they are
+var osrmProfiles = map[string]string{
+	"driving": "car",
+	"cycling": "bike",
+	"walking": "pedestrian",
+	"foot":    "pedestrian",
+}
+
+// osrmRequest is a parsed request of the form /{service}/v1/{profile}/{coordinates}
+type osrmRequest struct {
+	profile     string
+	coordinates []geojson.Point
+	query       url.Values
+}
+
+type osrmError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (i *impl) writeOsrmError(w http.ResponseWriter, code string, message string) {
+	i.writeJSON(w, http.StatusBadRequest, osrmError{Code: code, Message: message})
+}
+
+// writeOsrmRoutingError translates the errors of the router into OSRM error codes
+func (i *impl) writeOsrmRoutingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, router.ErrInvalidRequest):
+		i.writeOsrmError(w, osrmInvalidQuery, err.Error())
+	case errors.Is(err, router.ErrNoNearNode):
+		i.writeOsrmError(w, osrmNoSegment, err.Error())
+	case errors.Is(err, router.ErrNoRoute):
+		i.writeOsrmError(w, osrmNoRoute, err.Error())
+	default:
+		i.logger.Error().Msgf("error while answering osrm request: %s", err.Error())
+		i.writeJSON(w, http.StatusInternalServerError, osrmError{Code: "InternalError", Message: "internal error"})
+	}
+}
+
+// parseOsrmRequest reads the profile and coordinates from the path and writes the error response, if it is invalid
+func (i *impl) parseOsrmRequest(w http.ResponseWriter, r *http.Request, service string) (*osrmRequest, bool) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+	if len(parts) != 4 {
+		i.writeOsrmError(w, osrmInvalidUrl, "expected /{service}/{version}/{profile}/{coordinates}")
+		return nil, false
+	}
+
+	if parts[0] != service {
+		i.writeOsrmError(w, osrmInvalidService, fmt.Sprintf("unknown service: %s", parts[0]))
+		return nil, false
+	}
+
+	if parts[1] != osrmVersion {
+		i.writeOsrmError(w, osrmInvalidVersion, fmt.Sprintf("unsupported version: %s", parts[1]))
+		return nil, false
+	}
+
+	profile, err := url.PathUnescape(parts[2])
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidUrl, "invalid profile")
+		return nil, false
+	}
+
+	if name, ok := osrmProfiles[profile]; ok {
+		profile = name
+	}
+
+	coordinatesPart, err := url.PathUnescape(parts[3])
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidUrl, "invalid coordinates")
+		return nil, false
+	}
+
+	coordinates, err := parseOsrmCoordinates(coordinatesPart)
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidValue, err.Error())
+		return nil, false
+	}
+
+	return &osrmRequest{
+		profile:     profile,
+		coordinates: coordinates,
+		query:       r.URL.Query(),
+	}, true
+}
+
+// parseOsrmCoordinates reads coordinates as lon,lat;lon,lat or as polyline(...) and polyline6(...)
+func parseOsrmCoordinates(value string) ([]geojson.Point, error) {
+	for _, precision := range []int{5, 6} {
+		prefix := "polyline("
+		if precision == 6 {
+			prefix = "polyline6("
+		}
+
+		if strings.HasPrefix(value, prefix) && strings.HasSuffix(value, ")") {
+			points, err := geoformat.DecodePolyline(strings.TrimSuffix(strings.TrimPrefix(value, prefix), ")"), precision)
+			if err != nil {
+				return nil, fmt.Errorf("invalid polyline: %s", err.Error())
+			}
+			return points, nil
+		}
+	}
+
+	var out []geojson.Point
+	for _, pair := range strings.Split(value, ";") {
+		lonValue, latValue, ok := strings.Cut(pair, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid coordinate: %s", pair)
+		}
+
+		lon, err := strconv.ParseFloat(lonValue, 64)
+		if err != nil || lon < -180 || lon > 180 {
+			return nil, fmt.Errorf("invalid longitude: %s", lonValue)
+		}
+
+		lat, err := strconv.ParseFloat(latValue, 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return nil, fmt.Errorf("invalid latitude: %s", latValue)
+		}
+
+		out = append(out, geojson.NewPoint(lon, lat))
+	}
+
+	return out, nil
+}
+
+// osrmGeometryOptions are the options shared by the route and the match service
+type osrmGeometryOptions struct {
+	geometries string
+	overview   string
+	steps      bool
+}
+
+func parseOsrmGeometryOptions(query url.Values) (osrmGeometryOptions, error) {
+	out := osrmGeometryOptions{
+		geometries: "polyline",
+		overview:   "simplified",
+	}
+
+	if value := query.Get("geometries"); value != "" {
+		if value != "polyline" && value != "polyline6" && value != "geojson" {
+			return out, fmt.Errorf("geometries has to be polyline, polyline6 or geojson")
+		}
+		out.geometries = value
+	}
+
+	if value := query.Get("overview"); value != "" {
+		if value != "simplified" && value != "full" && value != "false" {
+			return out, fmt.Errorf("overview has to be simplified, full or false")
+		}
+		out.overview = value
+	}
+
+	if value := query.Get("steps"); value != "" {
+		steps, err := strconv.ParseBool(value)
+		if err != nil {
+			return out, fmt.Errorf("steps has to be true or false")
+		}
+		out.steps = steps
+	}
+
+	return out, nil
+}
+
+// parseOsrmIndices reads a list like 0;2;3 or all, nil is returned for all
+func parseOsrmIndices(value string, count int) ([]int, error) {
+	if value == "" || value == "all" {
+		return nil, nil
+	}
+
+	var out []int
+	for _, part := range strings.Split(value, ";") {
+		index, err := strconv.Atoi(part)
+		if err != nil || index < 0 || index >= count {
+			return nil, fmt.Errorf("invalid index: %s", part)
+		}
+		out = append(out, index)
+	}
+
+	return out, nil
+}
+
+// parseOsrmRadiuses reads a list like 10;unlimited;5, zero means unlimited
+func parseOsrmRadiuses(value string, count int) ([]float64, error) {
+	out := make([]float64, count)
+	if value == "" {
+		return out, nil
+	}
+
+	parts := strings.Split(value, ";")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d radiuses", count)
+	}
+
+	for index, part := range parts {
+		if part == "" || part == "unlimited" {
+			continue
+		}
+
+		radius, err := strconv.ParseFloat(part, 64)
+		if err != nil || radius < 0 {
+			return nil, fmt.Errorf("invalid radius: %s", part)
+		}
+		out[index] = radius
+	}
+
+	return out, nil
+}
+
+func (i *impl) osrmRoute(w http.ResponseWriter, r *http.Request) {
+	request, ok := i.parseOsrmRequest(w, r, "route")
+	if !ok {
+		return
+	}
+
+	if len(request.coordinates) < 2 {
+		i.writeOsrmError(w, osrmInvalidValue, "at least two coordinates are required")
+		return
+	}
+
+	if len(request.coordinates) > maxOsrmCoordinates {
+		i.writeOsrmError(w, osrmTooBig, fmt.Sprintf("at most %d coordinates are supported", maxOsrmCoordinates))
+		return
+	}
+
+	options, err := parseOsrmGeometryOptions(request.query)
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidOptions, err.Error())
+		return
+	}
+
+	routeOptions := router.RouteOptions{Profile: request.profile}
+
+	waypoints, err := i.osrmWaypoints(request.coordinates, routeOptions)
+	if err != nil {
+		i.writeOsrmRoutingError(w, err)
+		return
+	}
+
+	segments, err := i.application.FindRoute(request.coordinates, routeOptions)
+	if err != nil {
+		i.writeOsrmRoutingError(w, err)
+		return
+	}
+
+	i.writeJSON(w, http.StatusOK, osrmRouteResponse{
+		Code:      osrmOk,
+		Routes:    []osrmRoute{newOsrmRoute(segments, request.profile, options)},
+		Waypoints: waypoints,
+	})
+}
+
+func (i *impl) osrmNearest(w http.ResponseWriter, r *http.Request) {
+	request, ok := i.parseOsrmRequest(w, r, "nearest")
+	if !ok {
+		return
+	}
+
+	if len(request.coordinates) != 1 {
+		i.writeOsrmError(w, osrmInvalidValue, "exactly one coordinate is required")
+		return
+	}
+
+	number := 1
+	if value := request.query.Get("number"); value != "" {
+		var err error
+		number, err = strconv.Atoi(value)
+		if err != nil || number < 1 {
+			i.writeOsrmError(w, osrmInvalidOptions, "number has to be a positive integer")
+			return
+		}
+	}
+
+	if number > maxOsrmNearest {
+		i.writeOsrmError(w, osrmTooBig, fmt.Sprintf("at most %d nearest nodes are supported", maxOsrmNearest))
+		return
+	}
+
+	snapped, err := i.application.FindNearestNodes(request.coordinates[0], router.RouteOptions{Profile: request.profile}, number)
+	if err != nil {
+		i.writeOsrmRoutingError(w, err)
+		return
+	}
+
+	response := osrmNearestResponse{
+		Code:      osrmOk,
+		Waypoints: make([]osrmWaypoint, len(snapped)),
+	}
+
+	for index, point := range snapped {
+		response.Waypoints[index] = newOsrmWaypoint(point)
+		response.Waypoints[index].Nodes = []int64{point.NodeID, 0}
+	}
+
+	i.writeJSON(w, http.StatusOK, response)
+}
+
+func (i *impl) osrmTable(w http.ResponseWriter, r *http.Request) {
+	request, ok := i.parseOsrmRequest(w, r, "table")
+	if !ok {
+		return
+	}
+
+	if len(request.coordinates) < 1 {
+		i.writeOsrmError(w, osrmInvalidValue, "at least one coordinate is required")
+		return
+	}
+
+	if len(request.coordinates) > maxOsrmTableCoordinates {
+		i.writeOsrmError(w, osrmTooBig, fmt.Sprintf("at most %d coordinates are supported", maxOsrmTableCoordinates))
+		return
+	}
+
+	sources, err := parseOsrmIndices(request.query.Get("sources"), len(request.coordinates))
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidOptions, fmt.Sprintf("invalid sources: %s", err.Error()))
+		return
+	}
+
+	destinations, err := parseOsrmIndices(request.query.Get("destinations"), len(request.coordinates))
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidOptions, fmt.Sprintf("invalid destinations: %s", err.Error()))
+		return
+	}
+
+	withDuration, withDistance := true, false
+	if value := request.query.Get("annotations"); value != "" {
+		withDuration, withDistance = false, false
+		for _, annotation := range strings.Split(value, ",") {
+			switch annotation {
+			case "duration":
+				withDuration = true
+			case "distance":
+				withDistance = true
+			default:
+				i.writeOsrmError(w, osrmInvalidOptions, fmt.Sprintf("unsupported annotation: %s", annotation))
+				return
+			}
+		}
+	}
+
+	all := make([]int, len(request.coordinates))
+	for index := range all {
+		all[index] = index
+	}
+	if sources == nil {
+		sources = all
+	}
+	if destinations == nil {
+		destinations = all
+	}
+
+	routeOptions := router.RouteOptions{Profile: request.profile}
+
+	waypoints, err := i.osrmWaypoints(request.coordinates, routeOptions)
+	if err != nil {
+		i.writeOsrmRoutingError(w, err)
+		return
+	}
+
+	sourcePoints := make([]geojson.Point, len(sources))
+	for index, source := range sources {
+		sourcePoints[index] = request.coordinates[source]
+	}
+
+	destinationPoints := make([]geojson.Point, len(destinations))
+	for index, destination := range destinations {
+		destinationPoints[index] = request.coordinates[destination]
+	}
+
+	matrix, err := i.application.FindMatrix(sourcePoints, destinationPoints, routeOptions)
+	if err != nil {
+		i.writeOsrmRoutingError(w, err)
+		return
+	}
+
+	// unreachable pairs stay null
+	durations := make([][]*float64, len(sources))
+	distances := make([][]*float64, len(sources))
+	for sourceIndex, row := range matrix {
+		durations[sourceIndex] = make([]*float64, len(destinations))
+		distances[sourceIndex] = make([]*float64, len(destinations))
+
+		for destinationIndex, cell := range row {
+			if cell == nil {
+				continue
+			}
+
+			duration := float64(cell.LengthInTime)
+			distance := cell.LengthInMeters
+			durations[sourceIndex][destinationIndex] = &duration
+			distances[sourceIndex][destinationIndex] = &distance
+		}
+	}
+
+	response := osrmTableResponse{
+		Code:         osrmOk,
+		Sources:      make([]osrmWaypoint, len(sources)),
+		Destinations: make([]osrmWaypoint, len(destinations)),
+	}
+
+	for index, source := range sources {
+		response.Sources[index] = waypoints[source]
+	}
+
+	for index, destination := range destinations {
+		response.Destinations[index] = waypoints[destination]
+	}
+
+	if withDuration {
+		response.Durations = durations
+	}
+
+	if withDistance {
+		response.Distances = distances
+	}
+
+	i.writeJSON(w, http.StatusOK, response)
+}
+
+func (i *impl) osrmMatch(w http.ResponseWriter, r *http.Request) {
+	request, ok := i.parseOsrmRequest(w, r, "match")
+	if !ok {
+		return
+	}
+
+	if len(request.coordinates) < 2 {
+		i.writeOsrmError(w, osrmInvalidValue, "at least two coordinates are required")
+		return
+	}
+
+	if len(request.coordinates) > maxOsrmCoordinates {
+		i.writeOsrmError(w, osrmTooBig, fmt.Sprintf("at most %d coordinates are supported", maxOsrmCoordinates))
+		return
+	}
+
+	options, err := parseOsrmGeometryOptions(request.query)
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidOptions, err.Error())
+		return
+	}
+
+	radiuses, err := parseOsrmRadiuses(request.query.Get("radiuses"), len(request.coordinates))
+	if err != nil {
+		i.writeOsrmError(w, osrmInvalidOptions, err.Error())
+		return
+	}
+
+	if value := request.query.Get("timestamps"); value != "" && len(strings.Split(value, ";")) != len(request.coordinates) {
+		i.writeOsrmError(w, osrmInvalidOptions, fmt.Sprintf("expected %d timestamps", len(request.coordinates)))
+		return
+	}
+
+	routeOptions := router.RouteOptions{Profile: request.profile}
+
+	trace, err := i.matchTrace(request.coordinates, radiuses, routeOptions)
+	if err != nil {
+		i.writeOsrmRoutingError(w, err)
+		return
+	}
+
+	if len(trace.matchings) == 0 {
+		i.writeOsrmError(w, osrmNoMatch, "no matching found")
+		return
+	}
+
+	response := osrmMatchResponse{
+		Code:        osrmOk,
+		Tracepoints: trace.tracepoints,
+	}
+
+	for _, matching := range trace.matchings {
+		route := newOsrmRoute(matching.segments, request.profile, options)
+		route.Confidence = &matching.confidence
+		response.Matchings = append(response.Matchings, route)
+	}
+
+	i.writeJSON(w, http.StatusOK, response)
+}
+
+// osrmWaypoints snaps every coordinate to the graph
+func (i *impl) osrmWaypoints(coordinates []geojson.Point, options router.RouteOptions) ([]osrmWaypoint, error) {
+	out := make([]osrmWaypoint, len(coordinates))
+	for index, coordinate := range coordinates {
+		snapped, err := i.application.FindNearest(coordinate, options)
+		if err != nil {
+			return nil, err
+		}
+		out[index] = newOsrmWaypoint(snapped)
+	}
+	return out, nil
+}
@@ -0,0 +1,330 @@
+package http
+
+import (
+	"errors"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geoformat"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"math"
+)
+
+type osrmRouteResponse struct {
+	Code      string         `json:"code"`
+	Routes    []osrmRoute    `json:"routes"`
+	Waypoints []osrmWaypoint `json:"waypoints"`
+}
+
+type osrmNearestResponse struct {
+	Code      string         `json:"code"`
+	Waypoints []osrmWaypoint `json:"waypoints"`
+}
+
+type osrmTableResponse struct {
+	Code         string         `json:"code"`
+	Durations    [][]*float64   `json:"durations,omitempty"`
+	Distances    [][]*float64   `json:"distances,omitempty"`
+	Sources      []osrmWaypoint `json:"sources"`
+	Destinations []osrmWaypoint `json:"destinations"`
+}
+
+type osrmMatchResponse struct {
+	Code        string            `json:"code"`
+	Matchings   []osrmRoute       `json:"matchings"`
+	Tracepoints []*osrmTracepoint `json:"tracepoints"`
+}
+
+type osrmWaypoint struct {
+	Hint     string        `json:"hint"`
+	Distance float64       `json:"distance"`
+	Name     string        `json:"name"`
+	Location geojson.Point `json:"location"`
+	Nodes    []int64       `json:"nodes,omitempty"`
+}
+
+type osrmTracepoint struct {
+	osrmWaypoint
+	MatchingsIndex    int `json:"matchings_index"`
+	WaypointIndex     int `json:"waypoint_index"`
+	AlternativesCount int `json:"alternatives_count"`
+}
+
+type osrmRoute struct {
+	Geometry   any       `json:"geometry,omitempty"`
+	Legs       []osrmLeg `json:"legs"`
+	Distance   float64   `json:"distance"`
+	Duration   float64   `json:"duration"`
+	Weight     float64   `json:"weight"`
+	WeightName string    `json:"weight_name"`
+	Confidence *float64  `json:"confidence,omitempty"`
+}
+
+type osrmLeg struct {
+	Steps    []osrmStep `json:"steps"`
+	Summary  string     `json:"summary"`
+	Distance float64    `json:"distance"`
+	Duration float64    `json:"duration"`
+	Weight   float64    `json:"weight"`
+}
+
+type osrmStep struct {
+	Geometry      any                `json:"geometry"`
+	Maneuver      osrmManeuver       `json:"maneuver"`
+	Mode          string             `json:"mode"`
+	DrivingSide   string             `json:"driving_side"`
+	Name          string             `json:"name"`
+	Intersections []osrmIntersection `json:"intersections"`
+	Distance      float64            `json:"distance"`
+	Duration      float64            `json:"duration"`
+	Weight        float64            `json:"weight"`
+}
+
+type osrmManeuver struct {
+	Location      geojson.Point `json:"location"`
+	BearingBefore int           `json:"bearing_before"`
+	BearingAfter  int           `json:"bearing_after"`
+	Type          string        `json:"type"`
+}
+
+type osrmIntersection struct {
+	Location geojson.Point `json:"location"`
+	Bearings []int         `json:"bearings"`
+	Entry    []bool        `json:"entry"`
+	In       *int          `json:"in,omitempty"`
+	Out      *int          `json:"out,omitempty"`
+}
+
+func newOsrmWaypoint(snapped *router.SnappedPoint) osrmWaypoint {
+	return osrmWaypoint{
+		Distance: snapped.Distance,
+		Location: snapped.Location,
+	}
+}
+
+// newOsrmRoute converts the segments of a route into OSRM legs. Turn instructions are not known to the router,
+// so the steps of a leg only contain the departure with the geometry of the leg and the arrival at its end.
+func newOsrmRoute(segments []route.SegmentInfo, profile string, options osrmGeometryOptions) osrmRoute {
+	out := osrmRoute{
+		Legs:       make([]osrmLeg, 0, len(segments)),
+		WeightName: "duration",
+	}
+
+	var lines [][]geojson.Point
+	for _, segment := range segments {
+		line := joinLines(segment.Lines())
+		lines = append(lines, line)
+
+		duration := float64(segment.LengthInTime)
+		leg := osrmLeg{
+			Steps:    []osrmStep{},
+			Distance: segment.LengthInMeters,
+			Duration: duration,
+			Weight:   duration,
+		}
+
+		// clients like Leaflet Routing Machine join the step geometries, so the arrival only repeats the last point
+		if options.steps && len(line) > 0 {
+			last := line[len(line)-1]
+			leg.Steps = []osrmStep{
+				newOsrmStep("depart", line, 0, bearing(line, 0), osrmMode(profile), options.geometries, segment.LengthInMeters, duration),
+				newOsrmStep("arrive", []geojson.Point{last, last}, bearing(line, len(line)-2), 0, osrmMode(profile), options.geometries, 0, 0),
+			}
+		}
+
+		out.Legs = append(out.Legs, leg)
+		out.Distance += segment.LengthInMeters
+		out.Duration += duration
+	}
+
+	out.Weight = out.Duration
+
+	// the full geometry is also used for the simplified overview
+	if options.overview != "false" {
+		out.Geometry = encodeOsrmGeometry(joinLines(lines), options.geometries)
+	}
+
+	return out
+}
+
+func newOsrmStep(maneuverType string, line []geojson.Point, bearingBefore int, bearingAfter int, mode string, geometries string, distance float64, duration float64) osrmStep {
+	location := line[0]
+	if maneuverType == "arrive" {
+		location = line[len(line)-1]
+	}
+
+	intersection := osrmIntersection{Location: location}
+	if maneuverType == "depart" {
+		out := 0
+		intersection.Bearings = []int{bearingAfter}
+		intersection.Entry = []bool{true}
+		intersection.Out = &out
+	} else {
+		in := 0
+		intersection.Bearings = []int{(bearingBefore + 180) % 360}
+		intersection.Entry = []bool{true}
+		intersection.In = &in
+	}
+
+	return osrmStep{
+		Geometry: encodeOsrmGeometry(line, geometries),
+		Maneuver: osrmManeuver{
+			Location:      location,
+			BearingBefore: bearingBefore,
+			BearingAfter:  bearingAfter,
+			Type:          maneuverType,
+		},
+		Mode:          mode,
+		DrivingSide:   "right",
+		Intersections: []osrmIntersection{intersection},
+		Distance:      distance,
+		Duration:      duration,
+		Weight:        duration,
+	}
+}
+
+func encodeOsrmGeometry(points []geojson.Point, geometries string) any {
+	switch geometries {
+	case "geojson":
+		return geojson.LineString(points).ToGeometry()
+	case "polyline6":
+		return geoformat.EncodePolyline(points, 6)
+	default:
+		return geoformat.EncodePolyline(points, 5)
+	}
+}
+
+// bearing returns the direction from the point at index to the next one in degrees clockwise from north
+func bearing(line []geojson.Point, index int) int {
+	if index < 0 || index+1 >= len(line) {
+		return 0
+	}
+
+	radians := sphericmath.CalculateBearing(
+		sphericmath.NewPoint(line[index].Lon(), line[index].Lat()),
+		sphericmath.NewPoint(line[index+1].Lon(), line[index+1].Lat()),
+	)
+
+	degrees := int(math.Round(radians * 180 / math.Pi))
+	return (degrees%360 + 360) % 360
+}
+
+func osrmMode(profile string) string {
+	switch profile {
+	case "bike":
+		return "cycling"
+	case "pedestrian", router.TransitProfileName:
+		return "walking"
+	default:
+		return "driving"
+	}
+}
+
+type matchedTrace struct {
+	matchings   []matching
+	tracepoints []*osrmTracepoint
+}
+
+type matching struct {
+	points     []int // indices of the used coordinates
+	segments   []route.SegmentInfo
+	confidence float64
+}
+
+// matchTrace snaps the coordinates to the graph and connects consecutive ones with routes. Coordinates outside their
+// radius are skipped and unconnected parts of the trace start a new matching.
+func (i *impl) matchTrace(coordinates []geojson.Point, radiuses []float64, options router.RouteOptions) (*matchedTrace, error) {
+	out := &matchedTrace{
+		tracepoints: make([]*osrmTracepoint, len(coordinates)),
+	}
+
+	waypoints := make([]*osrmWaypoint, len(coordinates))
+	for index, coordinate := range coordinates {
+		snapped, err := i.application.FindNearest(coordinate, options)
+		if errors.Is(err, router.ErrNoNearNode) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if radiuses[index] > 0 && snapped.Distance > radiuses[index] {
+			continue
+		}
+
+		waypoint := newOsrmWaypoint(snapped)
+		waypoints[index] = &waypoint
+	}
+
+	var current *matching
+	closeMatching := func() {
+		if current != nil && len(current.segments) > 0 {
+			current.confidence = traceConfidence(coordinates, *current)
+			out.matchings = append(out.matchings, *current)
+		}
+		current = nil
+	}
+
+	for index := range coordinates {
+		if waypoints[index] == nil {
+			continue
+		}
+
+		if current == nil {
+			current = &matching{points: []int{index}}
+			continue
+		}
+
+		previous := current.points[len(current.points)-1]
+		segments, err := i.application.FindRoute([]geojson.Point{coordinates[previous], coordinates[index]}, options)
+		if errors.Is(err, router.ErrNoRoute) {
+			closeMatching()
+			current = &matching{points: []int{index}}
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		current.points = append(current.points, index)
+		current.segments = append(current.segments, segments...)
+	}
+	closeMatching()
+
+	for matchingIndex, m := range out.matchings {
+		for waypointIndex, index := range m.points {
+			out.tracepoints[index] = &osrmTracepoint{
+				osrmWaypoint:   *waypoints[index],
+				MatchingsIndex: matchingIndex,
+				WaypointIndex:  waypointIndex,
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// traceConfidence compares the beeline length of the trace with the matched length, detours lower the confidence
+func traceConfidence(coordinates []geojson.Point, m matching) float64 {
+	var traceLength, matchedLength float64
+	for index := 1; index < len(m.points); index++ {
+		a := coordinates[m.points[index-1]]
+		b := coordinates[m.points[index]]
+		traceLength += sphericmath.CalcDistanceInMeters(
+			sphericmath.NewPoint(a.Lon(), a.Lat()),
+			sphericmath.NewPoint(b.Lon(), b.Lat()),
+		)
+	}
+
+	for _, segment := range m.segments {
+		matchedLength += segment.LengthInMeters
+	}
+
+	if matchedLength <= 0 || traceLength >= matchedLength {
+		return 1
+	}
+
+	return traceLength / matchedLength
+}
@@ -0,0 +1,146 @@
+package http_test
+
+import (
+	"encoding/json"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/config"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/interface/access"
+	httpInterface "github.com/paulkoehlerdev/gosmRoutify/pkg/interface/http"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// nearestApplication knows five nodes near every point and routes along a straight line between the points
+type nearestApplication struct {
+	router.Application
+}
+
+func (nearestApplication) FindNearest(point geojson.Point, _ router.RouteOptions) (*router.SnappedPoint, error) {
+	return &router.SnappedPoint{NodeID: 1, Location: point}, nil
+}
+
+func (nearestApplication) FindRoute(points []geojson.Point, _ router.RouteOptions) ([]route.SegmentInfo, error) {
+	var out []route.SegmentInfo
+	for index := 1; index < len(points); index++ {
+		segment := route.SegmentInfo{LengthInMeters: 1000, LengthInTime: 100, GeoJson: geojson.NewEmptyGeoJson()}
+		segment.GeoJson.AddFeature(geojson.NewFeature(geojson.LineString{points[index-1], points[index]}.ToGeometry()))
+		out = append(out, segment)
+	}
+	return out, nil
+}
+
+func (nearestApplication) FindNearestNodes(point geojson.Point, _ router.RouteOptions, number int) ([]*router.SnappedPoint, error) {
+	var out []*router.SnappedPoint
+	for index := 1; index <= min(number, 5); index++ {
+		out = append(out, &router.SnappedPoint{NodeID: int64(index), Location: point, Distance: float64(index)})
+	}
+	return out, nil
+}
+
+// serve handles the request with a server without api keys and rate limits
+func serve(t *testing.T, request *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	serverConfig := &config.ServerConfig{}
+	server, err := httpInterface.NewHttpServer(logging.New(logging.LevelError, io.Discard), nearestApplication{}, serverConfig, access.New(serverConfig), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	recorder := httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func osrmGet(t *testing.T, path string) (int, map[string]any) {
+	t.Helper()
+
+	recorder := serve(t, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response %q: %s", recorder.Body.String(), err.Error())
+	}
+	return recorder.Code, body
+}
+
+func TestOsrmNearestNumber(t *testing.T) {
+	tests := []struct {
+		query     string
+		waypoints int
+	}{
+		{"", 1},
+		{"?number=3", 3},
+		{"?number=10", 5},
+	}
+
+	for _, test := range tests {
+		code, body := osrmGet(t, "/nearest/v1/driving/11.57,48.13"+test.query)
+		if code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d", test.query, code)
+		}
+
+		waypoints, _ := body["waypoints"].([]any)
+		if len(waypoints) != test.waypoints {
+			t.Errorf("%q: expected %d waypoints, got %d", test.query, test.waypoints, len(waypoints))
+		}
+	}
+
+	for _, query := range []string{"?number=0", "?number=101"} {
+		if code, body := osrmGet(t, "/nearest/v1/driving/11.57,48.13"+query); code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d (%v)", query, code, body["code"])
+		}
+	}
+}
+
+func TestOsrmRouteSteps(t *testing.T) {
+	code, body := osrmGet(t, "/route/v1/driving/11.57,48.13;11.58,48.14;11.59,48.14?steps=true&geometries=geojson")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%v)", code, body["code"])
+	}
+
+	routes, _ := body["routes"].([]any)
+	if len(routes) != 1 {
+		t.Fatalf("expected one route, got %d", len(routes))
+	}
+
+	legs, _ := routes[0].(map[string]any)["legs"].([]any)
+	if len(legs) != 2 {
+		t.Fatalf("expected two legs, got %d", len(legs))
+	}
+
+	for index, leg := range legs {
+		steps, _ := leg.(map[string]any)["steps"].([]any)
+		if len(steps) != 2 {
+			t.Fatalf("leg %d: expected two steps, got %d", index, len(steps))
+		}
+
+		depart, arrive := steps[0].(map[string]any), steps[1].(map[string]any)
+		if depart["maneuver"].(map[string]any)["type"] != "depart" || arrive["maneuver"].(map[string]any)["type"] != "arrive" {
+			t.Errorf("leg %d: expected a depart and an arrive step, got %v and %v", index, depart["maneuver"], arrive["maneuver"])
+		}
+
+		if coordinates := depart["geometry"].(map[string]any)["coordinates"].([]any); len(coordinates) != 2 {
+			t.Errorf("leg %d: expected the depart step to have the leg geometry, got %v", index, coordinates)
+		}
+
+		if depart["distance"] != 1000.0 || arrive["distance"] != 0.0 {
+			t.Errorf("leg %d: expected the distance on the depart step, got %v and %v", index, depart["distance"], arrive["distance"])
+		}
+	}
+
+	code, body = osrmGet(t, "/route/v1/driving/11.57,48.13;11.58,48.14")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%v)", code, body["code"])
+	}
+
+	leg := body["routes"].([]any)[0].(map[string]any)["legs"].([]any)[0].(map[string]any)
+	if steps, _ := leg["steps"].([]any); len(steps) != 0 {
+		t.Errorf("expected no steps without steps=true, got %d", len(steps))
+	}
+}
@@ -0,0 +1,316 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/repository/weightRepository"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geoformat"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// arrayIndexPattern matches the array indices in the field paths of encoding/json, e.g. location.1
+var arrayIndexPattern = regexp.MustCompile(`\.(\d+)`)
+
+const maxRouteBodySize = 1 << 20
+
+// routeRequest is the body of POST /api/route, the waypoints are decoded one by one to report the index of invalid waypoints
+type routeRequest struct {
+	route.Request
+	Waypoints []json.RawMessage `json:"waypoints"`
+}
+
+type routeResponse struct {
+	Version int   `json:"version"`
+	Routes  []any `json:"routes"`
+}
+
+// polylineRoute replaces the geojson of all segments of a route with encoded polylines
+type polylineRoute struct {
+	route.Route
+	Segments []polylineSegment `json:"segments"`
+}
+
+func (i *impl) postRoute(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRouteBodySize))
+	if err != nil {
+		i.writeRouteError(w, http.StatusRequestEntityTooLarge, &router.FieldError{Field: "body", Message: err.Error()})
+		return
+	}
+
+	request, waypoints, options, err := parseRouteRequest(body)
+	if err != nil {
+		i.writeRouteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	routes, status, err := i.findRoutes(waypoints, options)
+	if err != nil {
+		i.writeRouteError(w, status, err)
+		return
+	}
+
+	if request.Format == formatGPX || request.Format == formatKML {
+		i.writeRoute(w, routes[0].Waypoints, routes[0].Segments, request.Format)
+		return
+	}
+
+	i.writeJSON(w, http.StatusOK, encodeRoutes(routes, request.Format))
+}
+
+// findRoutes finds the routes of a parsed request, on error it returns the http status of the error
+func (i *impl) findRoutes(waypoints []router.Waypoint, options router.RouteOptions) ([]route.Route, int, error) {
+	routes, err := i.application.FindRoutes(waypoints, options)
+	if errors.Is(err, router.ErrInvalidRequest) {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if errors.Is(err, router.ErrNoNearNode) || errors.Is(err, router.ErrNoRoute) {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	if err != nil {
+		i.logger.Error().Msgf("error while finding route: %s", err.Error())
+		return nil, http.StatusInternalServerError, fmt.Errorf("error while finding route")
+	}
+
+	return routes, http.StatusOK, nil
+}
+
+// encodeRoutes creates the json response of the routes, it encodes the geometries as polylines if requested
+func encodeRoutes(routes []route.Route, format string) routeResponse {
+	out := routeResponse{
+		Version: route.RequestVersion,
+		Routes:  make([]any, len(routes)),
+	}
+
+	for index, found := range routes {
+		if format != formatPolyline && format != formatPolyline6 {
+			out.Routes[index] = found
+			continue
+		}
+
+		precision := 5
+		if format == formatPolyline6 {
+			precision = 6
+		}
+
+		encoded := polylineRoute{Route: found, Segments: make([]polylineSegment, len(found.Segments))}
+		for segmentIndex, segment := range found.Segments {
+			encoded.Segments[segmentIndex] = polylineSegment{
+				SegmentInfo: segment,
+				Polyline:    geoformat.EncodePolyline(joinLines(segment.Lines()), precision),
+			}
+		}
+		out.Routes[index] = encoded
+	}
+
+	return out
+}
+
+func (i *impl) writeRouteError(w http.ResponseWriter, status int, err error) {
+	i.writeJSON(w, status, route.ErrorResponse{Error: newRouteError(err)})
+}
+
+func newRouteError(err error) route.ErrorMessage {
+	out := route.ErrorMessage{Message: err.Error()}
+
+	var fieldError *router.FieldError
+	if errors.As(err, &fieldError) {
+		out.Field = fieldError.Field
+		out.Message = fieldError.Message
+	}
+
+	return out
+}
+
+// parseRouteRequest decodes and validates the body, all errors are *router.FieldError
+func parseRouteRequest(body []byte) (*routeRequest, []router.Waypoint, router.RouteOptions, error) {
+	var options router.RouteOptions
+
+	var request routeRequest
+	err := decodeStrict(body, &request, "")
+	if err != nil {
+		return nil, nil, options, err
+	}
+
+	if request.Version != route.RequestVersion {
+		return nil, nil, options, &router.FieldError{Field: "version", Message: fmt.Sprintf("unsupported version %d, has to be %d", request.Version, route.RequestVersion)}
+	}
+
+	if len(request.Waypoints) < 2 {
+		return nil, nil, options, &router.FieldError{Field: "waypoints", Message: "at least two waypoints are required"}
+	}
+
+	waypoints := make([]router.Waypoint, len(request.Waypoints))
+	for index, raw := range request.Waypoints {
+		waypoints[index], err = parseRouteWaypoint(raw, fmt.Sprintf("waypoints[%d]", index))
+		if err != nil {
+			return nil, nil, options, err
+		}
+	}
+
+	if !isValidFormat(request.Format) {
+		return nil, nil, options, &router.FieldError{Field: "format", Message: fmt.Sprintf("unknown format %q", request.Format)}
+	}
+
+	options.Profile = request.Profile
+	options.Alternatives = request.Alternatives
+	options.Debug = request.Debug
+
+	if request.Dimensions != nil {
+		options.Dimensions, err = toVehicleDimensions(request.Dimensions)
+		if err != nil {
+			return nil, nil, options, err
+		}
+	}
+
+	if request.Departure != "" {
+		options.Departure, err = time.Parse(time.RFC3339, request.Departure)
+		if err != nil {
+			return nil, nil, options, &router.FieldError{Field: "departure", Message: "has to be a RFC 3339 timestamp"}
+		}
+	}
+
+	if request.Avoid != nil && len(request.Avoid.Areas) > 0 {
+		options.AvoidAreas, err = geojson.ParsePolygons(request.Avoid.Areas)
+		if err != nil {
+			return nil, nil, options, &router.FieldError{Field: "avoid.areas", Message: err.Error()}
+		}
+	}
+
+	return &request, waypoints, options, nil
+}
+
+func parseRouteWaypoint(raw json.RawMessage, field string) (router.Waypoint, error) {
+	var waypoint route.Waypoint
+	err := decodeStrict(raw, &waypoint, field)
+	if err != nil {
+		return router.Waypoint{}, err
+	}
+
+	out := router.Waypoint{
+		Heading:          waypoint.Heading,
+		HeadingTolerance: waypoint.HeadingTolerance,
+		Radius:           waypoint.Radius,
+		NodeID:           waypoint.NodeID,
+	}
+
+	switch {
+	case waypoint.Location == nil && waypoint.NodeID == 0:
+		return out, &router.FieldError{Field: field + ".location", Message: "either location or nodeId is required"}
+	case waypoint.Location != nil && (math.Abs((*waypoint.Location)[0]) > 180 || math.Abs((*waypoint.Location)[1]) > 90):
+		return out, &router.FieldError{Field: field + ".location", Message: "coordinates out of range"}
+	case waypoint.Heading != nil && (*waypoint.Heading < 0 || *waypoint.Heading >= 360):
+		return out, &router.FieldError{Field: field + ".heading", Message: "has to be between 0 and 360 degrees"}
+	case waypoint.HeadingTolerance < 0 || waypoint.HeadingTolerance > 180:
+		return out, &router.FieldError{Field: field + ".headingTolerance", Message: "has to be between 0 and 180 degrees"}
+	case waypoint.Radius < 0:
+		return out, &router.FieldError{Field: field + ".radius", Message: "must not be negative"}
+	case waypoint.NodeID < 0:
+		return out, &router.FieldError{Field: field + ".nodeId", Message: "must not be negative"}
+	}
+
+	if waypoint.Location != nil {
+		out.Location = *waypoint.Location
+	}
+
+	return out, nil
+}
+
+func toVehicleDimensions(d *route.Dimensions) (weightRepository.VehicleDimensions, error) {
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"height", d.Height},
+		{"width", d.Width},
+		{"length", d.Length},
+		{"weight", d.Weight},
+		{"axleload", d.AxleLoad},
+	}
+
+	for _, value := range values {
+		if value.value < 0 {
+			return weightRepository.VehicleDimensions{}, &router.FieldError{Field: "dimensions." + value.name, Message: "must not be negative"}
+		}
+	}
+
+	return weightRepository.VehicleDimensions{
+		Height:   d.Height,
+		Width:    d.Width,
+		Length:   d.Length,
+		Weight:   d.Weight,
+		AxleLoad: d.AxleLoad,
+	}, nil
+}
+
+// decodeStrict decodes a single json value without unknown fields and converts decoding errors into field errors
+func decodeStrict(data []byte, value any, field string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(value)
+	if err == nil && decoder.More() {
+		err = fmt.Errorf("unexpected data after the request")
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	out := &router.FieldError{Field: field, Message: err.Error()}
+	if out.Field == "" {
+		out.Field = "body"
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		out.Field = joinField(field, arrayIndexPattern.ReplaceAllString(typeError.Field, "[$1]"))
+		out.Message = fmt.Sprintf("has to be %s, not %s", jsonTypeName(typeError.Type), typeError.Value)
+	}
+
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		out.Field = joinField(field, strings.Trim(name, "\""))
+		out.Message = "unknown field"
+	}
+
+	return out
+}
+
+// jsonTypeName names the json type, that is decoded into the go type
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+func joinField(prefix string, field string) string {
+	if prefix == "" {
+		return field
+	}
+	if field == "" {
+		return prefix
+	}
+	return prefix + "." + field
+}
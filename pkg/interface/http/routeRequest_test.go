@@ -0,0 +1,107 @@
+package http_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/domain/entity/route"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func (a nearestApplication) FindRoutes(waypoints []router.Waypoint, options router.RouteOptions) ([]route.Route, error) {
+	points := make([]geojson.Point, len(waypoints))
+	for index, waypoint := range waypoints {
+		points[index] = waypoint.Location
+	}
+
+	segments, err := a.FindRoute(points, options)
+	if err != nil {
+		return nil, err
+	}
+	return []route.Route{{Waypoints: points, Segments: segments}}, nil
+}
+
+func routePost(t *testing.T, body string) (int, route.ErrorResponse) {
+	t.Helper()
+
+	recorder := serve(t, httptest.NewRequest(http.MethodPost, "/api/route", strings.NewReader(body)))
+
+	var response route.ErrorResponse
+	if recorder.Code != http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid error response %q: %s", recorder.Body.String(), err.Error())
+		}
+	}
+	return recorder.Code, response
+}
+
+func TestRouteRequest(t *testing.T) {
+	const first = `{"location": [11.57, 48.13]}`
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		field  string
+	}{
+		{"valid", `{"version": 1, "waypoints": [` + first + `, {"location": [11.58, 48.14], "heading": 90, "headingTolerance": 45}]}`, http.StatusOK, ""},
+		{"unknown field", `{"version": 1, "waypoints": [` + first + `, ` + first + `], "profil": "car"}`, http.StatusBadRequest, "profil"},
+		{"unknown waypoint field", `{"version": 1, "waypoints": [` + first + `, {"location": [11.58, 48.14], "bearing": 90}]}`, http.StatusBadRequest, "waypoints[1].bearing"},
+		{"location not an array", `{"version": 1, "waypoints": [` + first + `, {"location": "11.58,48.14"}]}`, http.StatusBadRequest, "waypoints[1].location"},
+		{"coordinate not a number", `{"version": 1, "waypoints": [` + first + `, {"location": [11.58, "48.14"]}]}`, http.StatusBadRequest, "waypoints[1].location[1]"},
+		{"longitude out of range", `{"version": 1, "waypoints": [` + first + `, {"location": [191.58, 48.14]}]}`, http.StatusBadRequest, "waypoints[1].location"},
+		{"latitude out of range", `{"version": 1, "waypoints": [` + first + `, {"location": [11.58, -91]}]}`, http.StatusBadRequest, "waypoints[1].location"},
+		{"missing location", `{"version": 1, "waypoints": [` + first + `, {"radius": 10}]}`, http.StatusBadRequest, "waypoints[1].location"},
+		{"heading of 360 degrees", `{"version": 1, "waypoints": [{"location": [11.57, 48.13], "heading": 360}, ` + first + `]}`, http.StatusBadRequest, "waypoints[0].heading"},
+		{"negative heading", `{"version": 1, "waypoints": [{"location": [11.57, 48.13], "heading": -1}, ` + first + `]}`, http.StatusBadRequest, "waypoints[0].heading"},
+		{"heading tolerance above 180 degrees", `{"version": 1, "waypoints": [{"location": [11.57, 48.13], "headingTolerance": 181}, ` + first + `]}`, http.StatusBadRequest, "waypoints[0].headingTolerance"},
+		{"negative heading tolerance", `{"version": 1, "waypoints": [{"location": [11.57, 48.13], "headingTolerance": -1}, ` + first + `]}`, http.StatusBadRequest, "waypoints[0].headingTolerance"},
+		{"missing version", `{"waypoints": [` + first + `, ` + first + `]}`, http.StatusBadRequest, "version"},
+		{"unsupported version", `{"version": 2, "waypoints": [` + first + `, ` + first + `]}`, http.StatusBadRequest, "version"},
+		{"one waypoint", `{"version": 1, "waypoints": [` + first + `]}`, http.StatusBadRequest, "waypoints"},
+		{"unknown format", `{"version": 1, "waypoints": [` + first + `, ` + first + `], "format": "shp"}`, http.StatusBadRequest, "format"},
+		{"negative dimension", `{"version": 1, "waypoints": [` + first + `, ` + first + `], "dimensions": {"height": -1}}`, http.StatusBadRequest, "dimensions.height"},
+		{"data after the request", `{"version": 1, "waypoints": [` + first + `, ` + first + `]} {}`, http.StatusBadRequest, "body"},
+	}
+
+	for _, test := range tests {
+		status, response := routePost(t, test.body)
+		if status != test.status {
+			t.Errorf("%s: expected status %d, got %d (%v)", test.name, test.status, status, response.Error)
+			continue
+		}
+
+		if response.Error.Field != test.field {
+			t.Errorf("%s: expected field %q, got %q (%s)", test.name, test.field, response.Error.Field, response.Error.Message)
+		}
+	}
+}
+
+func TestRouteQuery(t *testing.T) {
+	query := base64.URLEncoding.EncodeToString([]byte(`[[11.57, 48.13], [11.58, 48.14]]`))
+
+	recorder := serve(t, httptest.NewRequest(http.MethodGet, "/api/route?r="+query+"&format=polyline", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var segments []map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &segments); err != nil {
+		t.Fatalf("invalid response %q: %s", recorder.Body.String(), err.Error())
+	}
+	if len(segments) != 1 {
+		t.Fatalf("expected one segment, got %d", len(segments))
+	}
+	if polyline, _ := segments[0]["polyline"].(string); polyline == "" {
+		t.Errorf("expected the segment to have a polyline, got %v", segments[0])
+	}
+
+	recorder = serve(t, httptest.NewRequest(http.MethodGet, "/api/route?r=not-base64", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid query to be rejected, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"errors"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/application/router"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// tiles serves /tiles/{z}/{x}/{y}.mvt
+func (i *impl) tiles(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/tiles/"), ".mvt")
+	parts := strings.Split(path, "/")
+	if !ok || len(parts) != 3 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	var coordinates [3]int
+	for index, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			http.Error(w, "invalid tile coordinates", http.StatusBadRequest)
+			return
+		}
+		coordinates[index] = value
+	}
+
+	tile, err := i.application.GetTile(coordinates[0], coordinates[1], coordinates[2])
+	if errors.Is(err, router.ErrInvalidRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		i.logger.Error().Msgf("error while generating tile: %s", err.Error())
+		http.Error(w, "error while generating tile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	_, err = w.Write(tile)
+	if err != nil {
+		i.logger.Error().Msgf("error while writing response: %s", err.Error())
+	}
+}
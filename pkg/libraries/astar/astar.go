@@ -1,17 +1,35 @@
 package astar
 
 import (
+	"errors"
 	"fmt"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/arrayutil"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/priorityQueue"
 	"golang.org/x/exp/constraints"
 )
 
+// ErrNoRoute is returned, if the end can not be reached within the iteration limit
+var ErrNoRoute = errors.New("no route found")
+
 type number interface {
 	constraints.Float | constraints.Integer
 }
 
+// Hooks observe a search, e.g. to debug it. All functions are optional.
+type Hooks[K comparable, N number] struct {
+	// OnSettle is called for every element taken from the open set with its costs from the start and its heuristic
+	OnSettle func(element K, gScore N, heuristic N)
+
+	// OnRelax is called for every edge of a settled element, improved is true if the edge lowered the costs of to
+	OnRelax func(from K, to K, weight N, improved bool)
+}
+
 func AStar[K comparable, N number](start K, end K, connections func(previousElement, element K) map[K]N, heuristic func(K) N, stopAfter int) ([]K, N, error) {
+	return AStarWithHooks(start, end, connections, heuristic, stopAfter, nil)
+}
+
+// AStarWithHooks is AStar, that calls the hooks during the search, hooks may be nil
+func AStarWithHooks[K comparable, N number](start K, end K, connections func(previousElement, element K) map[K]N, heuristic func(K) N, stopAfter int, hooks *Hooks[K, N]) ([]K, N, error) {
 	open := priorityQueue.NewPriorityQueue[K, N]()
 	open.Push(start, 0)
 
@@ -20,16 +38,26 @@ func AStar[K comparable, N number](start K, end K, connections func(previousElem
 	gScore := make(map[K]N)
 	gScore[start] = 0
 
+	// the heuristic is only stored for the hooks, the search itself does not need it again
+	var hScore map[K]N
+	if hooks != nil && hooks.OnSettle != nil {
+		hScore = map[K]N{start: heuristic(start)}
+	}
+
 	count := 0
 	for open.Len() > 0 {
 		count++
 
 		if count > stopAfter {
-			return nil, 0, fmt.Errorf("error: no route found, after %d (max) iterations", count)
+			return nil, 0, fmt.Errorf("error: %w, after %d (max) iterations", ErrNoRoute, count)
 		}
 
 		current := open.Pop()
 
+		if hScore != nil {
+			hooks.OnSettle(current, gScore[current], hScore[current])
+		}
+
 		if current == end {
 			return generatePath(parent, end), gScore[current], nil
 		}
@@ -37,15 +65,27 @@ func AStar[K comparable, N number](start K, end K, connections func(previousElem
 		neighbors := connections(parent[current], current)
 		for neighbor, weight := range neighbors {
 			tentativeScore := gScore[current] + weight
-			if score, ok := gScore[neighbor]; !ok || tentativeScore < score {
+			score, ok := gScore[neighbor]
+			improved := !ok || tentativeScore < score
+
+			if hooks != nil && hooks.OnRelax != nil {
+				hooks.OnRelax(current, neighbor, weight, improved)
+			}
+
+			if improved {
+				h := heuristic(neighbor)
+				if hScore != nil {
+					hScore[neighbor] = h
+				}
+
 				parent[neighbor] = current
 				gScore[neighbor] = tentativeScore
-				open.Push(neighbor, -(tentativeScore + heuristic(neighbor)))
+				open.Push(neighbor, -(tentativeScore + h))
 			}
 		}
 	}
 
-	return nil, 0, fmt.Errorf("error: no route found, after %d iterations", count)
+	return nil, 0, fmt.Errorf("error: %w, after %d iterations", ErrNoRoute, count)
 }
 
 func generatePath[K comparable](parent map[K]K, end K) []K {
@@ -0,0 +1,89 @@
+package astar
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/priorityQueue"
+)
+
+// ShortestPathTree contains the costs of all elements reached by Dijkstra and the paths to them
+type ShortestPathTree[K comparable, N number] struct {
+	Costs  map[K]N
+	parent map[K]K
+}
+
+// Path returns the path from the root of the tree to end, or nil if end was not reached
+func (t *ShortestPathTree[K, N]) Path(end K) []K {
+	if _, ok := t.Costs[end]; !ok {
+		return nil
+	}
+
+	return generatePath(t.parent, end)
+}
+
+// Dijkstra expands all elements that are reachable from start with costs of at most maxCost
+func Dijkstra[K comparable, N number](start K, connections func(previousElement, element K) map[K]N, maxCost N, stopAfter int) (*ShortestPathTree[K, N], error) {
+	return dijkstra(start, nil, connections, maxCost, stopAfter)
+}
+
+// DijkstraToTargets expands the elements like Dijkstra, but stops as soon as all targets are settled.
+// Targets, that are missing in the costs of the tree, are not reachable.
+func DijkstraToTargets[K comparable, N number](start K, targets []K, connections func(previousElement, element K) map[K]N, maxCost N, stopAfter int) (*ShortestPathTree[K, N], error) {
+	remaining := make(map[K]bool, len(targets))
+	for _, target := range targets {
+		remaining[target] = true
+	}
+
+	return dijkstra(start, remaining, connections, maxCost, stopAfter)
+}
+
+// dijkstra stops after settling all remaining elements, if remaining is not nil
+func dijkstra[K comparable, N number](start K, remaining map[K]bool, connections func(previousElement, element K) map[K]N, maxCost N, stopAfter int) (*ShortestPathTree[K, N], error) {
+	open := priorityQueue.NewPriorityQueue[K, N]()
+	open.Push(start, 0)
+
+	parent := make(map[K]K)
+	settled := make(map[K]bool)
+
+	gScore := make(map[K]N)
+	gScore[start] = 0
+
+	count := 0
+	for open.Len() > 0 {
+		current := open.Pop()
+		if settled[current] {
+			continue
+		}
+		settled[current] = true
+
+		if remaining != nil {
+			delete(remaining, current)
+			if len(remaining) == 0 {
+				break
+			}
+		}
+
+		count++
+		if count > stopAfter {
+			return nil, fmt.Errorf("error: search space exceeded, after %d (max) iterations", count)
+		}
+
+		neighbors := connections(parent[current], current)
+		for neighbor, weight := range neighbors {
+			tentativeScore := gScore[current] + weight
+			if tentativeScore > maxCost || settled[neighbor] {
+				continue
+			}
+
+			if score, ok := gScore[neighbor]; !ok || tentativeScore < score {
+				parent[neighbor] = current
+				gScore[neighbor] = tentativeScore
+				open.Push(neighbor, -tentativeScore)
+			}
+		}
+	}
+
+	return &ShortestPathTree[K, N]{
+		Costs:  gScore,
+		parent: parent,
+	}, nil
+}
@@ -0,0 +1,133 @@
+package clip
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Area decides which coordinates of an import are kept
+type Area interface {
+	Contains(lat, lon float64) bool
+}
+
+type boundingBox struct {
+	minLat, minLon, maxLat, maxLon float64
+}
+
+func NewBoundingBox(minLat, minLon, maxLat, maxLon float64) Area {
+	return &boundingBox{minLat: minLat, minLon: minLon, maxLat: maxLat, maxLon: maxLon}
+}
+
+// ParseBoundingBox reads a bounding box in the order minLon,minLat,maxLon,maxLat, like osmium and the GeoJSON bbox
+func ParseBoundingBox(value string) (Area, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid bounding box %q: expected minLon,minLat,maxLon,maxLat", value)
+	}
+
+	var coordinates [4]float64
+	for index, part := range parts {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bounding box %q: %s", value, err.Error())
+		}
+		coordinates[index] = coordinate
+	}
+
+	if coordinates[0] >= coordinates[2] || coordinates[1] >= coordinates[3] {
+		return nil, fmt.Errorf("invalid bounding box %q: the minimum has to be south-west of the maximum", value)
+	}
+
+	return NewBoundingBox(coordinates[1], coordinates[0], coordinates[3], coordinates[2]), nil
+}
+
+func (b *boundingBox) Contains(lat, lon float64) bool {
+	return b.minLat <= lat && lat <= b.maxLat && b.minLon <= lon && lon <= b.maxLon
+}
+
+type polygonArea struct {
+	areas  []sphericmath.Area
+	bounds []boundingBox
+}
+
+// NewPolygons keeps the coordinates inside any of the areas
+func NewPolygons(areas []sphericmath.Area) Area {
+	out := &polygonArea{
+		areas:  areas,
+		bounds: make([]boundingBox, len(areas)),
+	}
+
+	for index, area := range areas {
+		min, max := area.BoundingBox()
+		out.bounds[index] = boundingBox{minLat: min.Lat(), minLon: min.Lon(), maxLat: max.Lat(), maxLon: max.Lon()}
+	}
+
+	return out
+}
+
+func (p *polygonArea) Contains(lat, lon float64) bool {
+	point := sphericmath.NewPoint(lat, lon)
+
+	for index, area := range p.areas {
+		// the bounding box skips most of the points of an import before the more expensive polygon test
+		if !p.bounds[index].Contains(lat, lon) {
+			continue
+		}
+
+		if area.Contains(point) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseGeoJson reads the polygons of a GeoJSON geometry, feature or collection
+func ParseGeoJson(data []byte) (Area, error) {
+	polygons, err := geojson.ParsePolygons(data)
+	if err != nil {
+		return nil, err
+	}
+
+	areas := make([]sphericmath.Area, 0, len(polygons))
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+
+		rings := make([]sphericmath.Polygon, len(polygon))
+		for index, ring := range polygon {
+			rings[index] = make(sphericmath.Polygon, len(ring))
+			for pointIndex, point := range ring {
+				// geojson points are [lon, lat]
+				rings[index][pointIndex] = sphericmath.NewPoint(point[1], point[0])
+			}
+		}
+
+		areas = append(areas, sphericmath.Area{Outer: rings[0], Holes: rings[1:]})
+	}
+
+	if len(areas) == 0 {
+		return nil, fmt.Errorf("no polygon found")
+	}
+
+	return NewPolygons(areas), nil
+}
+
+// Open reads a bounding polygon from a .poly file or a GeoJSON file
+func Open(path string) (Area, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading file: %s", err.Error())
+	}
+
+	if strings.HasSuffix(path, ".poly") {
+		return ParsePoly(strings.NewReader(string(data)))
+	}
+
+	return ParseGeoJson(data)
+}
@@ -0,0 +1,93 @@
+package clip_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/clip"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	lat, lon float64
+	expected bool
+}
+
+func testArea(t *testing.T, area clip.Area, points []point) {
+	t.Helper()
+
+	for _, p := range points {
+		if actual := area.Contains(p.lat, p.lon); actual != p.expected {
+			t.Errorf("Contains(%f, %f) = %t, expected %t", p.lat, p.lon, actual, p.expected)
+		}
+	}
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	area, err := clip.ParseBoundingBox("11.3,48.0,11.8,48.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	testArea(t, area, []point{
+		{48.14, 11.58, true},
+		{48.14, 12.0, false},
+		{47.9, 11.58, false},
+	})
+
+	for _, invalid := range []string{"11.3,48.0,11.8", "11.8,48.0,11.3,48.3", "a,b,c,d"} {
+		if _, err := clip.ParseBoundingBox(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestParsePoly(t *testing.T) {
+	poly := `munich
+1
+   11.0   48.0
+   12.0   48.0
+   12.0   49.0
+   11.0   49.0
+END
+!2
+   11.4   48.4
+   11.6   48.4
+   11.6   48.6
+   11.4   48.6
+END
+END
+`
+
+	area, err := clip.ParsePoly(strings.NewReader(poly))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	testArea(t, area, []point{
+		{48.2, 11.2, true},
+		{48.5, 11.5, false},
+		{48.5, 12.5, false},
+	})
+
+	_, err = clip.ParsePoly(strings.NewReader("broken\n1\n 11.0 48.0\n"))
+	if err == nil {
+		t.Errorf("expected an error for a ring without END")
+	}
+}
+
+func TestParseGeoJson(t *testing.T) {
+	feature := `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[
+		[[11.0,48.0],[12.0,48.0],[12.0,49.0],[11.0,49.0],[11.0,48.0]],
+		[[11.4,48.4],[11.6,48.4],[11.6,48.6],[11.4,48.6],[11.4,48.4]]
+	]}}`
+
+	area, err := clip.ParseGeoJson([]byte(feature))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	testArea(t, area, []point{
+		{48.2, 11.2, true},
+		{48.5, 11.5, false},
+		{47.5, 11.2, false},
+	})
+}
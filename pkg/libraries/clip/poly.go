@@ -0,0 +1,96 @@
+package clip
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParsePoly reads the Osmosis polygon filter format, as used by the extracts of Geofabrik.
+// Rings starting with ! are holes, they are cut out of all other rings.
+func ParsePoly(reader io.Reader) (Area, error) {
+	scanner := bufio.NewScanner(reader)
+
+	// the first line is the name of the polygon
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("error while reading poly: empty file")
+	}
+
+	var outers, holes []sphericmath.Polygon
+	var ring sphericmath.Polygon
+	inRing, hole := false, false
+
+	lineNumber := 1
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line == "END" {
+			if !inRing {
+				// the end of the file
+				break
+			}
+
+			if len(ring) < 3 {
+				return nil, fmt.Errorf("error while reading poly: ring ending in line %d has less than 3 points", lineNumber)
+			}
+
+			if hole {
+				holes = append(holes, ring)
+			} else {
+				outers = append(outers, ring)
+			}
+
+			ring, inRing = nil, false
+			continue
+		}
+
+		if !inRing {
+			// the name of a ring
+			ring, inRing, hole = sphericmath.Polygon{}, true, strings.HasPrefix(line, "!")
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("error while reading poly: invalid coordinate in line %d", lineNumber)
+		}
+
+		lon, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading poly: invalid longitude in line %d", lineNumber)
+		}
+
+		lat, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading poly: invalid latitude in line %d", lineNumber)
+		}
+
+		ring = append(ring, sphericmath.NewPoint(lat, lon))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading poly: %s", err.Error())
+	}
+
+	if inRing {
+		return nil, fmt.Errorf("error while reading poly: missing END")
+	}
+
+	if len(outers) == 0 {
+		return nil, fmt.Errorf("error while reading poly: no polygon found")
+	}
+
+	areas := make([]sphericmath.Area, len(outers))
+	for index, outer := range outers {
+		areas[index] = sphericmath.Area{Outer: outer, Holes: holes}
+	}
+
+	return NewPolygons(areas), nil
+}
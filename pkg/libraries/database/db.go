@@ -3,7 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 // 7 minutes
@@ -15,11 +15,28 @@ PRAGMA foreign_keys = ON;
 PRAGMA synchronous = OFF;
 `
 
+// driverName is the sqlite3 driver applying the settings to each connection, database/sql opens more than one
+// connection, e.g. for a transaction while a query is read
+const driverName = "sqlite3_settings"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec(defaultDatabaseSettings, nil)
+			if err != nil {
+				return fmt.Errorf("error while setting database settings: %s", err.Error())
+			}
+			return nil
+		},
+	})
+}
+
 type Database interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	Begin() (*sql.Tx, error)
 	Prepare(query string) (*sql.Stmt, error)
 	Exec(query string, args ...interface{}) (sql.Result, error)
+	Ping() error
 	Close() error
 }
 
@@ -28,14 +45,15 @@ type impl struct {
 }
 
 func New(filename string) (Database, error) {
-	db, err := sql.Open("sqlite3", filename)
+	db, err := sql.Open(driverName, filename)
 	if err != nil {
 		return nil, fmt.Errorf("error while opening database: %s", err.Error())
 	}
 
-	_, err = db.Exec(defaultDatabaseSettings)
+	// sql.Open does not connect, opening the first connection checks the file and the settings
+	err = db.Ping()
 	if err != nil {
-		return nil, fmt.Errorf("error while setting database settings: %s", err.Error())
+		return nil, fmt.Errorf("error while connecting to database: %s", err.Error())
 	}
 
 	return &impl{
@@ -59,6 +77,10 @@ func (i *impl) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return i.db.Exec(query, args...)
 }
 
+func (i *impl) Ping() error {
+	return i.db.Ping()
+}
+
 func (i *impl) Close() error {
 	return i.db.Close()
 }
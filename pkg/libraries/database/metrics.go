@@ -0,0 +1,20 @@
+package database
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/metrics"
+	"time"
+)
+
+var queryDuration = metrics.Default.NewHistogramVec(
+	"gosmroutify_sqlite_query_duration_seconds",
+	"Latency of the SQLite queries per repository method.",
+	metrics.DefaultBuckets,
+	"repository", "method",
+)
+
+// ObserveQuery records the latency of a repository method, it is meant to be deferred:
+//
+//	defer database.ObserveQuery("node", "SelectNodeFromID", time.Now())
+func ObserveQuery(repository string, method string, start time.Time) {
+	queryDuration.With(repository, method).ObserveSince(start)
+}
@@ -0,0 +1,12 @@
+package database
+
+import (
+	"strings"
+)
+
+// Values repeats the placeholders of a row, e.g. Values(2, 3) is "(?, ?), (?, ?), (?, ?)", to insert several rows
+// with one statement
+func Values(columns int, rows int) string {
+	row := "(" + strings.Repeat("?, ", columns-1) + "?)"
+	return strings.Repeat(row+", ", rows-1) + row
+}
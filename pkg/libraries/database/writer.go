@@ -0,0 +1,72 @@
+package database
+
+import (
+	"sync"
+)
+
+// Writer executes writes one after another on a dedicated goroutine. The caller can prepare the next batch while
+// the last one is written, and sqlite, which only allows one writing transaction at a time, is never contended.
+type Writer interface {
+	// Write queues the write, it blocks while the queue is full. Errors are returned by the next Wait.
+	Write(write func() error)
+	// Wait blocks until all queued writes are done and returns the first error since the last Wait
+	Wait() error
+	Close()
+}
+
+type writerImpl struct {
+	queue chan func() error
+	wait  sync.WaitGroup
+
+	lock sync.Mutex
+	err  error
+
+	once sync.Once
+}
+
+func NewWriter(queueSize int) Writer {
+	i := &writerImpl{
+		queue: make(chan func() error, queueSize),
+	}
+
+	go i.run()
+
+	return i
+}
+
+func (i *writerImpl) run() {
+	for write := range i.queue {
+		err := write()
+		if err != nil {
+			i.lock.Lock()
+			if i.err == nil {
+				i.err = err
+			}
+			i.lock.Unlock()
+		}
+
+		i.wait.Done()
+	}
+}
+
+func (i *writerImpl) Write(write func() error) {
+	i.wait.Add(1)
+	i.queue <- write
+}
+
+func (i *writerImpl) Wait() error {
+	i.wait.Wait()
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	err := i.err
+	i.err = nil
+	return err
+}
+
+func (i *writerImpl) Close() {
+	i.once.Do(func() {
+		close(i.queue)
+	})
+}
@@ -0,0 +1,59 @@
+package database_test
+
+import (
+	"errors"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/database"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	writer := database.NewWriter(2)
+	defer writer.Close()
+
+	var written []int
+	for index := 0; index < 10; index++ {
+		index := index
+		writer.Write(func() error {
+			written = append(written, index)
+			return nil
+		})
+	}
+
+	err := writer.Wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(written) != 10 {
+		t.Fatalf("expected 10 writes, got %d", len(written))
+	}
+
+	for index, value := range written {
+		if value != index {
+			t.Errorf("writes are not in order: %v", written)
+			break
+		}
+	}
+
+	writer.Write(func() error { return errors.New("first") })
+	writer.Write(func() error { return errors.New("second") })
+
+	err = writer.Wait()
+	if err == nil || err.Error() != "first" {
+		t.Errorf("expected the first error, got %v", err)
+	}
+
+	if err = writer.Wait(); err != nil {
+		t.Errorf("expected the error to be reset, got %s", err.Error())
+	}
+}
+
+func TestValues(t *testing.T) {
+	if actual := database.Values(2, 3); actual != "(?, ?), (?, ?), (?, ?)" {
+		t.Errorf("Values(2, 3) = %q", actual)
+	}
+
+	if actual := database.Values(1, 1); actual != "(?)" {
+		t.Errorf("Values(1, 1) = %q", actual)
+	}
+}
@@ -0,0 +1,193 @@
+package elevation
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ElevationModel returns the terrain elevation in meters for a coordinate.
+// Lookup returns NaN if no tile covers the coordinate or the tile has a void at that position.
+type ElevationModel interface {
+	Lookup(lat float64, lon float64) float64
+}
+
+type tile interface {
+	covers(lat float64, lon float64) bool
+	lookup(lat float64, lon float64) float64
+}
+
+type tileLoader func(path string) (tile, error)
+
+type tileFile struct {
+	path string
+	load tileLoader
+	hgt  bool
+
+	once sync.Once
+	tile tile
+	err  error
+}
+
+type impl struct {
+	files []*tileFile
+}
+
+type none struct{}
+
+// NewNone returns an ElevationModel without any data, every lookup returns NaN.
+func NewNone() ElevationModel {
+	return &none{}
+}
+
+func (n *none) Lookup(_ float64, _ float64) float64 {
+	return math.NaN()
+}
+
+// New scans directory for SRTM (.hgt) and GeoTIFF (.tif, .tiff) tiles.
+// The tiles are loaded lazily on the first lookup inside their bounds.
+func New(directory string) (ElevationModel, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading elevation directory: %s", err.Error())
+	}
+
+	var files []*tileFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(directory, entry.Name())
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".hgt":
+			files = append(files, &tileFile{path: path, load: loadHgtTile, hgt: true})
+		case ".tif", ".tiff":
+			files = append(files, &tileFile{path: path, load: loadGeoTiffTile})
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no elevation tiles found in %s", directory)
+	}
+
+	return &impl{
+		files: files,
+	}, nil
+}
+
+func (i *impl) Lookup(lat float64, lon float64) float64 {
+	for _, file := range i.files {
+		if !file.mayCover(lat, lon) {
+			continue
+		}
+
+		file.once.Do(func() {
+			file.tile, file.err = file.load(file.path)
+		})
+
+		if file.err != nil || !file.tile.covers(lat, lon) {
+			continue
+		}
+
+		if ele := file.tile.lookup(lat, lon); !math.IsNaN(ele) {
+			return ele
+		}
+	}
+
+	return math.NaN()
+}
+
+// mayCover avoids loading .hgt tiles whose name already tells that they do not cover the coordinate
+func (t *tileFile) mayCover(lat float64, lon float64) bool {
+	if !t.hgt {
+		return true
+	}
+
+	south, west, err := parseHgtName(t.path)
+	if err != nil {
+		return true
+	}
+
+	return lat >= south && lat <= south+1 && lon >= west && lon <= west+1
+}
+
+// grid is a regular raster of elevations, with row 0 at the northern edge
+type grid struct {
+	north, west  float64
+	latStep      float64
+	lonStep      float64
+	rows, cols   int
+	values       []float64
+	pixelIsPoint bool
+}
+
+func (g *grid) covers(lat float64, lon float64) bool {
+	row, col := g.position(lat, lon)
+	return row >= 0 && col >= 0 && row <= float64(g.rows-1) && col <= float64(g.cols-1)
+}
+
+func (g *grid) position(lat float64, lon float64) (row float64, col float64) {
+	row = (g.north - lat) / g.latStep
+	col = (lon - g.west) / g.lonStep
+	if !g.pixelIsPoint {
+		row -= 0.5
+		col -= 0.5
+	}
+	return row, col
+}
+
+func (g *grid) value(row int, col int) float64 {
+	row = min(max(row, 0), g.rows-1)
+	col = min(max(col, 0), g.cols-1)
+	return g.values[row*g.cols+col]
+}
+
+// lookup interpolates bilinear between the four surrounding samples
+func (g *grid) lookup(lat float64, lon float64) float64 {
+	row, col := g.position(lat, lon)
+
+	r0 := int(math.Floor(row))
+	c0 := int(math.Floor(col))
+	dr := row - float64(r0)
+	dc := col - float64(c0)
+
+	v00 := g.value(r0, c0)
+	v01 := g.value(r0, c0+1)
+	v10 := g.value(r0+1, c0)
+	v11 := g.value(r0+1, c0+1)
+
+	if math.IsNaN(v00) || math.IsNaN(v01) || math.IsNaN(v10) || math.IsNaN(v11) {
+		return nearestValid(dr, dc, v00, v01, v10, v11)
+	}
+
+	top := v00*(1-dc) + v01*dc
+	bottom := v10*(1-dc) + v11*dc
+	return top*(1-dr) + bottom*dr
+}
+
+func nearestValid(dr, dc float64, v00, v01, v10, v11 float64) float64 {
+	candidates := []struct {
+		value float64
+		dist  float64
+	}{
+		{v00, dr*dr + dc*dc},
+		{v01, dr*dr + (1-dc)*(1-dc)},
+		{v10, (1-dr)*(1-dr) + dc*dc},
+		{v11, (1-dr)*(1-dr) + (1-dc)*(1-dc)},
+	}
+
+	out := math.NaN()
+	best := math.Inf(1)
+	for _, candidate := range candidates {
+		if !math.IsNaN(candidate.value) && candidate.dist < best {
+			out = candidate.value
+			best = candidate.dist
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,64 @@
+package elevation_test
+
+import (
+	"encoding/binary"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/elevation"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestHgt(t *testing.T, directory string, name string, values []int16) {
+	data := make([]byte, len(values)*2)
+	for index, value := range values {
+		binary.BigEndian.PutUint16(data[index*2:], uint16(value))
+	}
+
+	err := os.WriteFile(filepath.Join(directory, name), data, 0644)
+	if err != nil {
+		t.Fatalf("error writing test tile: %s", err.Error())
+	}
+}
+
+func TestHgtLookup(t *testing.T) {
+	directory := t.TempDir()
+
+	// 3x3 samples, row 0 is the northern edge at 49°N
+	writeTestHgt(t, directory, "N48E011.hgt", []int16{
+		100, 200, 300,
+		100, 200, 300,
+		0, 0, -32768,
+	})
+
+	model, err := elevation.New(directory)
+	if err != nil {
+		t.Fatalf("error loading tiles: %s", err.Error())
+	}
+
+	tests := []struct {
+		lat, lon float64
+		expected float64
+	}{
+		{49, 11, 100},
+		{49, 11.5, 200},
+		{49, 11.25, 150},
+		{48.75, 11.5, 200},
+		{48.25, 11, 50},
+	}
+
+	for _, test := range tests {
+		ele := model.Lookup(test.lat, test.lon)
+		if math.Abs(ele-test.expected) > 0.0001 {
+			t.Errorf("lookup(%f, %f): expected %f, got %f", test.lat, test.lon, test.expected, ele)
+		}
+	}
+
+	if ele := model.Lookup(48, 11.75); math.IsNaN(ele) || ele != 0 {
+		t.Errorf("expected void to fall back to nearest valid sample, got %f", ele)
+	}
+
+	if ele := model.Lookup(50, 11); !math.IsNaN(ele) {
+		t.Errorf("expected NaN outside of tiles, got %f", ele)
+	}
+}
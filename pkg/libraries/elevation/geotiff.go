@@ -0,0 +1,360 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// only uncompressed, single band, stripped GeoTIFFs in geographic coordinates are supported,
+// as this is what most DEM download portals provide (e.g. SRTM GeoTIFF, Copernicus GLO-30 after gdal_translate)
+
+const (
+	tiffTagImageWidth      = 256
+	tiffTagImageLength     = 257
+	tiffTagBitsPerSample   = 258
+	tiffTagCompression     = 259
+	tiffTagStripOffsets    = 273
+	tiffTagSamplesPerPixel = 277
+	tiffTagRowsPerStrip    = 278
+	tiffTagTileWidth       = 322
+	tiffTagSampleFormat    = 339
+	tiffTagModelPixelScale = 33550
+	tiffTagModelTiepoint   = 33922
+	tiffTagGeoKeyDirectory = 34735
+	tiffTagGdalNoData      = 42113
+
+	tiffTypeByte   = 1
+	tiffTypeASCII  = 2
+	tiffTypeShort  = 3
+	tiffTypeLong   = 4
+	tiffTypeDouble = 12
+
+	tiffSampleFormatUint  = 1
+	tiffSampleFormatInt   = 2
+	tiffSampleFormatFloat = 3
+
+	geoKeyRasterType    = 1025
+	rasterPixelIsPoint  = 2
+	tiffCompressionNone = 1
+)
+
+type tiffEntry struct {
+	fieldType uint16
+	count     uint32
+	data      []byte
+}
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func loadGeoTiffTile(path string) (tile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading geotiff file: %s", err.Error())
+	}
+
+	reader, err := newTiffReader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := reader.readFirstIFD()
+	if err != nil {
+		return nil, fmt.Errorf("error while reading geotiff directory: %s", err.Error())
+	}
+
+	return reader.decodeGrid(entries)
+}
+
+func newTiffReader(data []byte) (*tiffReader, error) {
+	if len(data) < 8 {
+		return nil, errors.New("file too short for tiff header")
+	}
+
+	var order binary.ByteOrder
+	switch string(data[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid tiff byte order")
+	}
+
+	if order.Uint16(data[2:4]) != 42 {
+		return nil, errors.New("unsupported tiff version (BigTIFF is not supported)")
+	}
+
+	return &tiffReader{data: data, order: order}, nil
+}
+
+func (r *tiffReader) readFirstIFD() (map[uint16]tiffEntry, error) {
+	offset := int(r.order.Uint32(r.data[4:8]))
+	if offset+2 > len(r.data) {
+		return nil, errors.New("ifd offset out of range")
+	}
+
+	count := int(r.order.Uint16(r.data[offset:]))
+	entries := make(map[uint16]tiffEntry, count)
+
+	for index := 0; index < count; index++ {
+		start := offset + 2 + index*12
+		if start+12 > len(r.data) {
+			return nil, errors.New("ifd entry out of range")
+		}
+
+		tag := r.order.Uint16(r.data[start:])
+		fieldType := r.order.Uint16(r.data[start+2:])
+		valueCount := r.order.Uint32(r.data[start+4:])
+
+		size := int(valueCount) * tiffTypeSize(fieldType)
+		valueData := r.data[start+8 : start+12]
+		if size > 4 {
+			valueOffset := int(r.order.Uint32(valueData))
+			if valueOffset+size > len(r.data) {
+				return nil, fmt.Errorf("value of tag %d out of range", tag)
+			}
+			valueData = r.data[valueOffset : valueOffset+size]
+		}
+
+		entries[tag] = tiffEntry{fieldType: fieldType, count: valueCount, data: valueData}
+	}
+
+	return entries, nil
+}
+
+func tiffTypeSize(fieldType uint16) int {
+	switch fieldType {
+	case tiffTypeByte, tiffTypeASCII:
+		return 1
+	case tiffTypeShort:
+		return 2
+	case tiffTypeLong:
+		return 4
+	case tiffTypeDouble:
+		return 8
+	default:
+		return 1
+	}
+}
+
+func (r *tiffReader) ints(entries map[uint16]tiffEntry, tag uint16) ([]int, error) {
+	entry, ok := entries[tag]
+	if !ok {
+		return nil, fmt.Errorf("missing tiff tag %d", tag)
+	}
+
+	out := make([]int, entry.count)
+	for index := range out {
+		switch entry.fieldType {
+		case tiffTypeShort:
+			out[index] = int(r.order.Uint16(entry.data[index*2:]))
+		case tiffTypeLong:
+			out[index] = int(r.order.Uint32(entry.data[index*4:]))
+		case tiffTypeByte:
+			out[index] = int(entry.data[index])
+		default:
+			return nil, fmt.Errorf("unexpected type %d for tiff tag %d", entry.fieldType, tag)
+		}
+	}
+
+	return out, nil
+}
+
+func (r *tiffReader) singleInt(entries map[uint16]tiffEntry, tag uint16, fallback int) (int, error) {
+	if _, ok := entries[tag]; !ok {
+		return fallback, nil
+	}
+
+	values, err := r.ints(entries, tag)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, fmt.Errorf("missing value of tiff tag %d", tag)
+	}
+
+	return values[0], nil
+}
+
+func (r *tiffReader) doubles(entries map[uint16]tiffEntry, tag uint16) ([]float64, error) {
+	entry, ok := entries[tag]
+	if !ok {
+		return nil, fmt.Errorf("missing tiff tag %d", tag)
+	}
+
+	if entry.fieldType != tiffTypeDouble {
+		return nil, fmt.Errorf("unexpected type %d for tiff tag %d", entry.fieldType, tag)
+	}
+
+	out := make([]float64, entry.count)
+	for index := range out {
+		out[index] = math.Float64frombits(r.order.Uint64(entry.data[index*8:]))
+	}
+
+	return out, nil
+}
+
+func (r *tiffReader) decodeGrid(entries map[uint16]tiffEntry) (tile, error) {
+	if _, ok := entries[tiffTagTileWidth]; ok {
+		return nil, errors.New("tiled geotiffs are not supported")
+	}
+
+	width, err := r.singleInt(entries, tiffTagImageWidth, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := r.singleInt(entries, tiffTagImageLength, 0)
+	if err != nil {
+		return nil, err
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid tiff size: %dx%d", width, height)
+	}
+
+	compression, err := r.singleInt(entries, tiffTagCompression, tiffCompressionNone)
+	if err != nil {
+		return nil, err
+	}
+	if compression != tiffCompressionNone {
+		return nil, fmt.Errorf("unsupported tiff compression: %d", compression)
+	}
+
+	samplesPerPixel, err := r.singleInt(entries, tiffTagSamplesPerPixel, 1)
+	if err != nil {
+		return nil, err
+	}
+	if samplesPerPixel != 1 {
+		return nil, fmt.Errorf("unsupported samples per pixel: %d", samplesPerPixel)
+	}
+
+	bitsPerSample, err := r.singleInt(entries, tiffTagBitsPerSample, 1)
+	if err != nil {
+		return nil, err
+	}
+	if bitsPerSample <= 0 || bitsPerSample%8 != 0 {
+		return nil, fmt.Errorf("unsupported bits per sample: %d", bitsPerSample)
+	}
+
+	sampleFormat, err := r.singleInt(entries, tiffTagSampleFormat, tiffSampleFormatUint)
+	if err != nil {
+		return nil, err
+	}
+
+	decodeSample, err := r.sampleDecoder(bitsPerSample, sampleFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsPerStrip, err := r.singleInt(entries, tiffTagRowsPerStrip, height)
+	if err != nil {
+		return nil, err
+	}
+	if rowsPerStrip <= 0 {
+		return nil, fmt.Errorf("invalid rows per strip: %d", rowsPerStrip)
+	}
+
+	stripOffsets, err := r.ints(entries, tiffTagStripOffsets)
+	if err != nil {
+		return nil, err
+	}
+
+	scale, err := r.doubles(entries, tiffTagModelPixelScale)
+	if err != nil {
+		return nil, err
+	}
+
+	tiepoint, err := r.doubles(entries, tiffTagModelTiepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scale) < 2 || len(tiepoint) < 6 {
+		return nil, errors.New("invalid geotiff georeference")
+	}
+
+	noData := math.NaN()
+	if entry, ok := entries[tiffTagGdalNoData]; ok {
+		value, err := strconv.ParseFloat(strings.Trim(string(entry.data), "\x00 "), 64)
+		if err == nil {
+			noData = value
+		}
+	}
+
+	bytesPerSample := bitsPerSample / 8
+	values := make([]float64, width*height)
+	for row := 0; row < height; row++ {
+		strip := row / rowsPerStrip
+		if strip >= len(stripOffsets) {
+			return nil, errors.New("strip offset missing")
+		}
+
+		rowStart := stripOffsets[strip] + (row%rowsPerStrip)*width*bytesPerSample
+		if rowStart+width*bytesPerSample > len(r.data) {
+			return nil, errors.New("strip data out of range")
+		}
+
+		for col := 0; col < width; col++ {
+			value := decodeSample(r.data[rowStart+col*bytesPerSample:])
+			if value == noData || value <= hgtVoid {
+				value = math.NaN()
+			}
+			values[row*width+col] = value
+		}
+	}
+
+	// the tiepoint maps raster position (i, j) to model position (x, y)
+	west := tiepoint[3] - tiepoint[0]*scale[0]
+	north := tiepoint[4] + tiepoint[1]*scale[1]
+
+	return &grid{
+		north:        north,
+		west:         west,
+		latStep:      scale[1],
+		lonStep:      scale[0],
+		rows:         height,
+		cols:         width,
+		values:       values,
+		pixelIsPoint: r.isPixelIsPoint(entries),
+	}, nil
+}
+
+func (r *tiffReader) sampleDecoder(bitsPerSample int, sampleFormat int) (func([]byte) float64, error) {
+	switch {
+	case bitsPerSample == 16 && sampleFormat == tiffSampleFormatInt:
+		return func(b []byte) float64 { return float64(int16(r.order.Uint16(b))) }, nil
+	case bitsPerSample == 16 && sampleFormat == tiffSampleFormatUint:
+		return func(b []byte) float64 { return float64(r.order.Uint16(b)) }, nil
+	case bitsPerSample == 32 && sampleFormat == tiffSampleFormatInt:
+		return func(b []byte) float64 { return float64(int32(r.order.Uint32(b))) }, nil
+	case bitsPerSample == 32 && sampleFormat == tiffSampleFormatFloat:
+		return func(b []byte) float64 { return float64(math.Float32frombits(r.order.Uint32(b))) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported sample type: %d bits, format %d", bitsPerSample, sampleFormat)
+	}
+}
+
+func (r *tiffReader) isPixelIsPoint(entries map[uint16]tiffEntry) bool {
+	keys, err := r.ints(entries, tiffTagGeoKeyDirectory)
+	if err != nil || len(keys) < 4 {
+		return false
+	}
+
+	// header: version, revision, minor revision, number of keys; then 4 values per key
+	for index := 4; index+3 < len(keys); index += 4 {
+		if keys[index] == geoKeyRasterType && keys[index+1] == 0 {
+			return keys[index+3] == rasterPixelIsPoint
+		}
+	}
+
+	return false
+}
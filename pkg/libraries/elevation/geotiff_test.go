@@ -0,0 +1,169 @@
+package elevation_test
+
+import (
+	"encoding/binary"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/elevation"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const (
+	tiffShort  = 3
+	tiffLong   = 4
+	tiffDouble = 12
+)
+
+type testTag struct {
+	tag       uint16
+	fieldType uint16
+	values    []float64
+}
+
+// writeTestGeoTiff writes a little endian float32 GeoTIFF with rowsPerStrip rows per strip.
+// The strips are written in reverse order, so that reading them depends on the strip offsets.
+// The tags replace the generated tags with the same number.
+func writeTestGeoTiff(t *testing.T, directory string, name string, rows [][]float32, rowsPerStrip int, tags []testTag) {
+	data := []byte("II\x2a\x00\x00\x00\x00\x00")
+
+	stripCount := (len(rows) + rowsPerStrip - 1) / rowsPerStrip
+	stripOffsets := make([]float64, stripCount)
+	for strip := stripCount - 1; strip >= 0; strip-- {
+		stripOffsets[strip] = float64(len(data))
+		for _, row := range rows[strip*rowsPerStrip : min((strip+1)*rowsPerStrip, len(rows))] {
+			for _, value := range row {
+				data = binary.LittleEndian.AppendUint32(data, math.Float32bits(value))
+			}
+		}
+	}
+
+	all := []testTag{
+		{256, tiffShort, []float64{float64(len(rows[0]))}},
+		{257, tiffShort, []float64{float64(len(rows))}},
+		{258, tiffShort, []float64{32}},
+		{273, tiffLong, stripOffsets},
+		{277, tiffShort, []float64{1}},
+		{278, tiffShort, []float64{float64(rowsPerStrip)}},
+		{339, tiffShort, []float64{3}},
+	}
+	for _, tag := range tags {
+		all = slices.DeleteFunc(all, func(generated testTag) bool { return generated.tag == tag.tag })
+		all = append(all, tag)
+	}
+	slices.SortFunc(all, func(a, b testTag) int { return int(a.tag) - int(b.tag) })
+
+	ifdOffset := len(data)
+	binary.LittleEndian.PutUint32(data[4:], uint32(ifdOffset))
+
+	valueOffset := ifdOffset + 2 + len(all)*12 + 4
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(all)))
+
+	var values []byte
+	for _, tag := range all {
+		var encoded []byte
+		for _, value := range tag.values {
+			switch tag.fieldType {
+			case tiffShort:
+				encoded = binary.LittleEndian.AppendUint16(encoded, uint16(value))
+			case tiffLong:
+				encoded = binary.LittleEndian.AppendUint32(encoded, uint32(value))
+			case tiffDouble:
+				encoded = binary.LittleEndian.AppendUint64(encoded, math.Float64bits(value))
+			}
+		}
+
+		data = binary.LittleEndian.AppendUint16(data, tag.tag)
+		data = binary.LittleEndian.AppendUint16(data, tag.fieldType)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(tag.values)))
+
+		if len(encoded) > 4 {
+			data = binary.LittleEndian.AppendUint32(data, uint32(valueOffset+len(values)))
+			values = append(values, encoded...)
+		} else {
+			data = append(data, append(encoded, make([]byte, 4-len(encoded))...)...)
+		}
+	}
+
+	data = binary.LittleEndian.AppendUint32(data, 0)
+	data = append(data, values...)
+
+	err := os.WriteFile(filepath.Join(directory, name), data, 0644)
+	if err != nil {
+		t.Fatalf("error writing test tile: %s", err.Error())
+	}
+}
+
+var testRows = [][]float32{
+	{100, 200, 300},
+	{400, 500, 600},
+	{700, 800, 900},
+}
+
+// geoTags places the raster position (1, 1) at 11.5°E 48.5°N with 0.5° pixels, so the raster starts at 11°E 49°N
+func geoTags(rasterType float64) []testTag {
+	return []testTag{
+		{33550, tiffDouble, []float64{0.5, 0.5, 0}},
+		{33922, tiffDouble, []float64{1, 1, 0, 11.5, 48.5, 0}},
+		{34735, tiffShort, []float64{1, 1, 0, 1, 1025, 0, 1, rasterType}},
+	}
+}
+
+func TestGeoTiffLookup(t *testing.T) {
+	tests := []struct {
+		name       string
+		rasterType float64
+		lat, lon   float64
+		expected   float64
+	}{
+		{"area center of first pixel", 1, 48.75, 11.25, 100},
+		{"area between four pixels", 1, 48.5, 11.5, 300},
+		{"area center of last pixel", 1, 47.75, 12.25, 900},
+		{"point first sample", 2, 49, 11, 100},
+		{"point second strip", 2, 48, 11.5, 800},
+		{"point between samples", 2, 48.25, 11.75, 700},
+	}
+
+	for _, test := range tests {
+		directory := t.TempDir()
+		writeTestGeoTiff(t, directory, "dem.tif", testRows, 2, geoTags(test.rasterType))
+
+		model, err := elevation.New(directory)
+		if err != nil {
+			t.Fatalf("error loading tiles: %s", err.Error())
+		}
+
+		if ele := model.Lookup(test.lat, test.lon); math.Abs(ele-test.expected) > 0.0001 {
+			t.Errorf("%s: lookup(%f, %f): expected %f, got %f", test.name, test.lat, test.lon, test.expected, ele)
+		}
+	}
+}
+
+func TestGeoTiffUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []testTag
+	}{
+		{"tiled", []testTag{{322, tiffShort, []float64{16}}}},
+		{"empty width", []testTag{{256, tiffShort, nil}}},
+		{"compressed", []testTag{{259, tiffShort, []float64{5}}}},
+		{"zero rows per strip", []testTag{{278, tiffShort, []float64{0}}}},
+		{"zero height", []testTag{{257, tiffShort, []float64{0}}}},
+		{"bits per sample not a multiple of 8", []testTag{{258, tiffShort, []float64{12}}}},
+	}
+
+	for _, test := range tests {
+		directory := t.TempDir()
+		writeTestGeoTiff(t, directory, "dem.tif", testRows, 2, append(geoTags(2), test.tags...))
+
+		model, err := elevation.New(directory)
+		if err != nil {
+			t.Fatalf("error loading tiles: %s", err.Error())
+		}
+
+		if ele := model.Lookup(49, 11); !math.IsNaN(ele) {
+			t.Errorf("%s: expected NaN for an unreadable tile, got %f", test.name, ele)
+		}
+	}
+}
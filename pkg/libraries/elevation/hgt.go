@@ -0,0 +1,88 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const hgtVoid = -32768
+
+// parseHgtName parses the south-west corner from SRTM file names like N48E011.hgt
+func parseHgtName(path string) (south float64, west float64, err error) {
+	name := strings.ToUpper(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if len(name) != 7 {
+		return 0, 0, fmt.Errorf("invalid hgt file name: %s", name)
+	}
+
+	lat, err := strconv.Atoi(name[1:3])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude in hgt file name: %s", name)
+	}
+
+	lon, err := strconv.Atoi(name[4:7])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude in hgt file name: %s", name)
+	}
+
+	switch name[0] {
+	case 'N':
+	case 'S':
+		lat = -lat
+	default:
+		return 0, 0, fmt.Errorf("invalid latitude hemisphere in hgt file name: %s", name)
+	}
+
+	switch name[3] {
+	case 'E':
+	case 'W':
+		lon = -lon
+	default:
+		return 0, 0, fmt.Errorf("invalid longitude hemisphere in hgt file name: %s", name)
+	}
+
+	return float64(lat), float64(lon), nil
+}
+
+func loadHgtTile(path string) (tile, error) {
+	south, west, err := parseHgtName(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading hgt file: %s", err.Error())
+	}
+
+	// SRTM1 tiles have 3601x3601 samples, SRTM3 tiles have 1201x1201 samples
+	size := int(math.Sqrt(float64(len(data) / 2)))
+	if size < 2 || size*size*2 != len(data) {
+		return nil, fmt.Errorf("invalid hgt file size: %d bytes", len(data))
+	}
+
+	values := make([]float64, size*size)
+	for index := range values {
+		value := int16(binary.BigEndian.Uint16(data[index*2:]))
+		if value == hgtVoid {
+			values[index] = math.NaN()
+			continue
+		}
+		values[index] = float64(value)
+	}
+
+	return &grid{
+		north:        south + 1,
+		west:         west,
+		latStep:      1 / float64(size-1),
+		lonStep:      1 / float64(size-1),
+		rows:         size,
+		cols:         size,
+		values:       values,
+		pixelIsPoint: true,
+	}, nil
+}
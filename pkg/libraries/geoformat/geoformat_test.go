@@ -0,0 +1,96 @@
+package geoformat_test
+
+import (
+	"encoding/xml"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geoformat"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"math"
+	"strings"
+	"testing"
+)
+
+// example from the format documentation, points are [lon, lat]
+var polylinePoints = []geojson.Point{
+	{-120.2, 38.5},
+	{-120.95, 40.7},
+	{-126.453, 43.252},
+}
+
+func TestEncodePolyline(t *testing.T) {
+	expected := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if actual := geoformat.EncodePolyline(polylinePoints, 5); actual != expected {
+		t.Errorf("EncodePolyline() = %q, expected %q", actual, expected)
+	}
+}
+
+func TestDecodePolyline(t *testing.T) {
+	for _, precision := range []int{5, 6} {
+		decoded, err := geoformat.DecodePolyline(geoformat.EncodePolyline(polylinePoints, precision), precision)
+		if err != nil {
+			t.Fatalf("error decoding polyline: %s", err.Error())
+		}
+
+		if len(decoded) != len(polylinePoints) {
+			t.Fatalf("decoded %d points, expected %d", len(decoded), len(polylinePoints))
+		}
+
+		for index, point := range decoded {
+			expected := polylinePoints[index]
+			if math.Abs(point[0]-expected[0]) > 1e-9 || math.Abs(point[1]-expected[1]) > 1e-9 {
+				t.Errorf("precision %d: point %d = %v, expected %v", precision, index, point, expected)
+			}
+		}
+	}
+
+	_, err := geoformat.DecodePolyline("_p~iF~ps|U_ulL", 5)
+	if err == nil {
+		t.Errorf("expected error for truncated polyline")
+	}
+}
+
+func TestEncodeGPX(t *testing.T) {
+	route := geoformat.Route{
+		Name:      "test",
+		Waypoints: []geojson.Point{polylinePoints[0], polylinePoints[2]},
+		Segments:  [][]geojson.Point{polylinePoints},
+	}
+
+	out, err := geoformat.EncodeGPX(route)
+	if err != nil {
+		t.Fatalf("error encoding gpx: %s", err.Error())
+	}
+
+	var document struct {
+		Route []struct {
+			Lat float64 `xml:"lat,attr"`
+			Lon float64 `xml:"lon,attr"`
+		} `xml:"rte>rtept"`
+		Track []struct {
+			Lat float64 `xml:"lat,attr"`
+		} `xml:"trk>trkseg>trkpt"`
+	}
+
+	err = xml.Unmarshal(out, &document)
+	if err != nil {
+		t.Fatalf("error parsing gpx: %s", err.Error())
+	}
+
+	if len(document.Route) != 2 || document.Route[1].Lat != 43.252 || document.Route[1].Lon != -126.453 {
+		t.Errorf("unexpected route points: %+v", document.Route)
+	}
+
+	if len(document.Track) != 3 || document.Track[0].Lat != 38.5 {
+		t.Errorf("unexpected track points: %+v", document.Track)
+	}
+}
+
+func TestEncodeKML(t *testing.T) {
+	out, err := geoformat.EncodeKML(geoformat.Route{Segments: [][]geojson.Point{polylinePoints}})
+	if err != nil {
+		t.Fatalf("error encoding kml: %s", err.Error())
+	}
+
+	if !strings.Contains(string(out), "<coordinates>-120.2,38.5 -120.95,40.7 -126.453,43.252</coordinates>") {
+		t.Errorf("coordinates missing in kml:\n%s", out)
+	}
+}
@@ -0,0 +1,79 @@
+package geoformat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+)
+
+const (
+	gpxNamespace = "http://www.topografix.com/GPX/1/1"
+	gpxCreator   = "gosmRoutify"
+)
+
+type gpx struct {
+	XMLName xml.Name `xml:"gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Route   gpxRoute `xml:"rte"`
+	Track   gpxTrack `xml:"trk"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name,omitempty"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Name     string            `xml:"name,omitempty"`
+	Segments []gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Name string  `xml:"name,omitempty"`
+}
+
+// EncodeGPX writes the waypoints as GPX route and the segments as track, as most devices expect one of both
+func EncodeGPX(route Route) ([]byte, error) {
+	document := gpx{
+		Version: "1.1",
+		Creator: gpxCreator,
+		Xmlns:   gpxNamespace,
+		Route:   gpxRoute{Name: route.Name},
+		Track:   gpxTrack{Name: route.Name},
+	}
+
+	for index, point := range route.Waypoints {
+		document.Route.Points = append(document.Route.Points, gpxPoint{
+			Lat:  point[1],
+			Lon:  point[0],
+			Name: fmt.Sprintf("%d", index+1),
+		})
+	}
+
+	for _, segment := range route.Segments {
+		document.Track.Segments = append(document.Track.Segments, gpxTrackSegment{Points: toGpxPoints(segment)})
+	}
+
+	out, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling gpx: %s", err.Error())
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+func toGpxPoints(points []geojson.Point) []gpxPoint {
+	out := make([]gpxPoint, len(points))
+	for index, point := range points {
+		out[index] = gpxPoint{Lat: point[1], Lon: point[0]}
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package geoformat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"strconv"
+	"strings"
+)
+
+const kmlNamespace = "http://www.opengis.net/kml/2.2"
+
+type kml struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name,omitempty"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name       string         `xml:"name,omitempty"`
+	Point      *kmlPoint      `xml:"Point,omitempty"`
+	LineString *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// EncodeKML writes every waypoint and segment as a placemark of a single document
+func EncodeKML(route Route) ([]byte, error) {
+	document := kml{
+		Xmlns:    kmlNamespace,
+		Document: kmlDocument{Name: route.Name},
+	}
+
+	for index, point := range route.Waypoints {
+		document.Document.Placemarks = append(document.Document.Placemarks, kmlPlacemark{
+			Name:  fmt.Sprintf("%d", index+1),
+			Point: &kmlPoint{Coordinates: kmlCoordinates([]geojson.Point{point})},
+		})
+	}
+
+	for _, segment := range route.Segments {
+		document.Document.Placemarks = append(document.Document.Placemarks, kmlPlacemark{
+			Name:       route.Name,
+			LineString: &kmlLineString{Tessellate: 1, Coordinates: kmlCoordinates(segment)},
+		})
+	}
+
+	out, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling kml: %s", err.Error())
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+// kmlCoordinates formats the points as space separated lon,lat tuples
+func kmlCoordinates(points []geojson.Point) string {
+	tuples := make([]string, len(points))
+	for index, point := range points {
+		tuples[index] = strconv.FormatFloat(point[0], 'f', -1, 64) + "," + strconv.FormatFloat(point[1], 'f', -1, 64)
+	}
+	return strings.Join(tuples, " ")
+}
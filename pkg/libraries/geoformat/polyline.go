@@ -0,0 +1,99 @@
+package geoformat
+
+import (
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+	"math"
+	"strings"
+)
+
+// EncodePolyline encodes the points in the Google encoded polyline format, precision is the number of decimals (5 or 6)
+func EncodePolyline(points []geojson.Point, precision int) string {
+	factor := math.Pow10(precision)
+
+	var out strings.Builder
+	var prevLat, prevLon int64
+	for _, point := range points {
+		// geojson points are stored as [lon, lat], polylines encode lat first
+		lat := int64(math.Round(point[1] * factor))
+		lon := int64(math.Round(point[0] * factor))
+
+		encodeValue(&out, lat-prevLat)
+		encodeValue(&out, lon-prevLon)
+
+		prevLat, prevLon = lat, lon
+	}
+
+	return out.String()
+}
+
+func encodeValue(out *strings.Builder, value int64) {
+	shifted := value << 1
+	if value < 0 {
+		shifted = ^shifted
+	}
+
+	for shifted >= 0x20 {
+		out.WriteByte(byte((0x20 | (shifted & 0x1f)) + 63))
+		shifted >>= 5
+	}
+	out.WriteByte(byte(shifted + 63))
+}
+
+// DecodePolyline is the inverse of EncodePolyline
+func DecodePolyline(encoded string, precision int) ([]geojson.Point, error) {
+	factor := math.Pow10(precision)
+
+	var out []geojson.Point
+	var lat, lon int64
+	for index := 0; index < len(encoded); {
+		var err error
+		var deltaLat, deltaLon int64
+
+		deltaLat, index, err = decodeValue(encoded, index)
+		if err != nil {
+			return nil, err
+		}
+
+		deltaLon, index, err = decodeValue(encoded, index)
+		if err != nil {
+			return nil, err
+		}
+
+		lat += deltaLat
+		lon += deltaLon
+		out = append(out, geojson.Point{float64(lon) / factor, float64(lat) / factor})
+	}
+
+	return out, nil
+}
+
+func decodeValue(encoded string, index int) (int64, int, error) {
+	var result int64
+	var shift uint
+
+	for {
+		if index >= len(encoded) {
+			return 0, index, fmt.Errorf("unexpected end of polyline")
+		}
+
+		b := int64(encoded[index]) - 63
+		index++
+
+		if b < 0 || b > 0x3f || shift > 60 {
+			return 0, index, fmt.Errorf("invalid character at position %d", index-1)
+		}
+
+		result |= (b & 0x1f) << shift
+		shift += 5
+
+		if b < 0x20 {
+			break
+		}
+	}
+
+	if result&1 != 0 {
+		return ^(result >> 1), index, nil
+	}
+	return result >> 1, index, nil
+}
@@ -0,0 +1,10 @@
+package geoformat
+
+import "github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/geojson"
+
+// Route is the format independent input of the GPX and KML encoders
+type Route struct {
+	Name      string
+	Waypoints []geojson.Point   // points requested by the user
+	Segments  [][]geojson.Point // geometry of the route, e.g. one line per leg
+}
@@ -0,0 +1,68 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type rawObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    json.RawMessage `json:"geometry"`
+	Geometries  []rawObject     `json:"geometries"`
+	Features    []rawObject     `json:"features"`
+}
+
+// ParsePolygons reads all polygons of a Polygon or MultiPolygon geometry, which can also be wrapped
+// in a Feature, FeatureCollection or GeometryCollection. Other geometry types are rejected.
+func ParsePolygons(data []byte) ([]Polygon, error) {
+	var object rawObject
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return nil, fmt.Errorf("error while unmarshalling geojson: %s", err.Error())
+	}
+
+	return object.polygons()
+}
+
+func (o rawObject) polygons() ([]Polygon, error) {
+	switch o.Type {
+	case "Polygon":
+		var polygon Polygon
+		err := json.Unmarshal(o.Coordinates, &polygon)
+		if err != nil {
+			return nil, fmt.Errorf("error while unmarshalling polygon: %s", err.Error())
+		}
+		return []Polygon{polygon}, nil
+
+	case "MultiPolygon":
+		var multiPolygon MultiPolygon
+		err := json.Unmarshal(o.Coordinates, &multiPolygon)
+		if err != nil {
+			return nil, fmt.Errorf("error while unmarshalling multipolygon: %s", err.Error())
+		}
+		return multiPolygon, nil
+
+	case "Feature":
+		var geometry rawObject
+		err := json.Unmarshal(o.Geometry, &geometry)
+		if err != nil {
+			return nil, fmt.Errorf("error while unmarshalling feature geometry: %s", err.Error())
+		}
+		return geometry.polygons()
+
+	case "FeatureCollection", "GeometryCollection":
+		var out []Polygon
+		for _, child := range append(o.Features, o.Geometries...) {
+			polygons, err := child.polygons()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, polygons...)
+		}
+		return out, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %q, expected polygons", o.Type)
+	}
+}
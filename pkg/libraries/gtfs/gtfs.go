@@ -0,0 +1,312 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Feed contains the parts of a GTFS feed that are needed for routing.
+// Times are seconds since midnight of the service day and may exceed 24 hours.
+type Feed struct {
+	Agencies      []Agency
+	Stops         []Stop
+	Routes        []Route
+	Trips         []Trip
+	StopTimes     []StopTime
+	Calendars     []Calendar
+	CalendarDates []CalendarDate
+}
+
+type Agency struct {
+	ID       string
+	Name     string
+	Timezone string
+}
+
+type Stop struct {
+	ID            string
+	Name          string
+	Lat           float64
+	Lon           float64
+	ParentStation string
+}
+
+type Route struct {
+	ID        string
+	ShortName string
+	LongName  string
+	Type      int
+}
+
+type Trip struct {
+	ID        string
+	RouteID   string
+	ServiceID string
+	Headsign  string
+}
+
+type StopTime struct {
+	TripID        string
+	StopID        string
+	StopSequence  int
+	ArrivalTime   int
+	DepartureTime int
+}
+
+type Calendar struct {
+	ServiceID string
+	Weekdays  [7]bool // indexed by time.Weekday, Sunday is 0
+	StartDate string  // YYYYMMDD
+	EndDate   string  // YYYYMMDD
+}
+
+const (
+	ExceptionAdded   = 1
+	ExceptionRemoved = 2
+)
+
+type CalendarDate struct {
+	ServiceID     string
+	Date          string // YYYYMMDD
+	ExceptionType int
+}
+
+// Open reads a GTFS feed from a zip file
+func Open(path string) (*Feed, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening gtfs archive: %s", err.Error())
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		// some feeds are zipped with a containing directory
+		name := file.Name[strings.LastIndex(file.Name, "/")+1:]
+		files[name] = file
+	}
+
+	feed := &Feed{}
+
+	readers := []struct {
+		name     string
+		required bool
+		read     func(row *row) error
+	}{
+		{"agency.txt", true, feed.readAgency},
+		{"stops.txt", true, feed.readStop},
+		{"routes.txt", true, feed.readRoute},
+		{"trips.txt", true, feed.readTrip},
+		{"stop_times.txt", true, feed.readStopTime},
+		{"calendar.txt", false, feed.readCalendar},
+		{"calendar_dates.txt", false, feed.readCalendarDate},
+	}
+
+	for _, reader := range readers {
+		file, ok := files[reader.name]
+		if !ok {
+			if reader.required {
+				return nil, fmt.Errorf("missing required file %s", reader.name)
+			}
+			continue
+		}
+
+		err = readTable(file, reader.read)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading %s: %s", reader.name, err.Error())
+		}
+	}
+
+	if len(feed.Calendars) == 0 && len(feed.CalendarDates) == 0 {
+		return nil, fmt.Errorf("neither calendar.txt nor calendar_dates.txt contain services")
+	}
+
+	sort.SliceStable(feed.StopTimes, func(a, b int) bool {
+		if feed.StopTimes[a].TripID != feed.StopTimes[b].TripID {
+			return feed.StopTimes[a].TripID < feed.StopTimes[b].TripID
+		}
+		return feed.StopTimes[a].StopSequence < feed.StopTimes[b].StopSequence
+	})
+
+	return feed, nil
+}
+
+func (f *Feed) readAgency(row *row) error {
+	f.Agencies = append(f.Agencies, Agency{
+		ID:       row.get("agency_id"),
+		Name:     row.get("agency_name"),
+		Timezone: row.get("agency_timezone"),
+	})
+	return nil
+}
+
+func (f *Feed) readStop(row *row) error {
+	// entrances, generic nodes and boarding areas (location_type 2-4) are not needed for routing
+	if locationType := row.get("location_type"); locationType != "" && locationType != "0" && locationType != "1" {
+		return nil
+	}
+
+	lat, err := row.float("stop_lat")
+	if err != nil {
+		return err
+	}
+
+	lon, err := row.float("stop_lon")
+	if err != nil {
+		return err
+	}
+
+	f.Stops = append(f.Stops, Stop{
+		ID:            row.get("stop_id"),
+		Name:          row.get("stop_name"),
+		Lat:           lat,
+		Lon:           lon,
+		ParentStation: row.get("parent_station"),
+	})
+	return nil
+}
+
+func (f *Feed) readRoute(row *row) error {
+	routeType, err := row.int("route_type")
+	if err != nil {
+		return err
+	}
+
+	f.Routes = append(f.Routes, Route{
+		ID:        row.get("route_id"),
+		ShortName: row.get("route_short_name"),
+		LongName:  row.get("route_long_name"),
+		Type:      routeType,
+	})
+	return nil
+}
+
+func (f *Feed) readTrip(row *row) error {
+	f.Trips = append(f.Trips, Trip{
+		ID:        row.get("trip_id"),
+		RouteID:   row.get("route_id"),
+		ServiceID: row.get("service_id"),
+		Headsign:  row.get("trip_headsign"),
+	})
+	return nil
+}
+
+func (f *Feed) readStopTime(row *row) error {
+	sequence, err := row.int("stop_sequence")
+	if err != nil {
+		return err
+	}
+
+	arrival, err := parseTime(row.get("arrival_time"))
+	if err != nil {
+		return fmt.Errorf("line %d: %s", row.line, err.Error())
+	}
+
+	departure, err := parseTime(row.get("departure_time"))
+	if err != nil {
+		return fmt.Errorf("line %d: %s", row.line, err.Error())
+	}
+
+	// times are only required at timepoints, stops without times can not be used for boarding
+	if arrival < 0 && departure < 0 {
+		return nil
+	}
+
+	if arrival < 0 {
+		arrival = departure
+	}
+
+	if departure < 0 {
+		departure = arrival
+	}
+
+	f.StopTimes = append(f.StopTimes, StopTime{
+		TripID:        row.get("trip_id"),
+		StopID:        row.get("stop_id"),
+		StopSequence:  sequence,
+		ArrivalTime:   arrival,
+		DepartureTime: departure,
+	})
+	return nil
+}
+
+func (f *Feed) readCalendar(row *row) error {
+	calendar := Calendar{
+		ServiceID: row.get("service_id"),
+		StartDate: row.get("start_date"),
+		EndDate:   row.get("end_date"),
+	}
+
+	days := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	for index, day := range days {
+		calendar.Weekdays[index] = row.get(day) == "1"
+	}
+
+	f.Calendars = append(f.Calendars, calendar)
+	return nil
+}
+
+func (f *Feed) readCalendarDate(row *row) error {
+	exceptionType, err := row.int("exception_type")
+	if err != nil {
+		return err
+	}
+
+	f.CalendarDates = append(f.CalendarDates, CalendarDate{
+		ServiceID:     row.get("service_id"),
+		Date:          row.get("date"),
+		ExceptionType: exceptionType,
+	})
+	return nil
+}
+
+// parseTime parses HH:MM:SS into seconds, empty values return -1
+func parseTime(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return -1, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time \"%s\"", value)
+	}
+
+	out := 0
+	for _, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time \"%s\"", value)
+		}
+		out = out*60 + number
+	}
+
+	return out, nil
+}
+
+// RouteTypeMode maps basic and extended GTFS route types to a mode name
+func RouteTypeMode(routeType int) string {
+	switch {
+	case routeType == 0 || (routeType >= 900 && routeType < 1000):
+		return "tram"
+	case routeType == 1 || (routeType >= 400 && routeType < 500):
+		return "subway"
+	case routeType == 2 || (routeType >= 100 && routeType < 200):
+		return "rail"
+	case routeType == 3 || (routeType >= 200 && routeType < 300) || (routeType >= 700 && routeType < 800):
+		return "bus"
+	case routeType == 4 || routeType == 1000 || routeType == 1200:
+		return "ferry"
+	case routeType == 5 || routeType == 6 || routeType == 7 || routeType == 1300 || routeType == 1400:
+		return "cableway"
+	case routeType == 11 || routeType == 800:
+		return "trolleybus"
+	case routeType == 12:
+		return "monorail"
+	default:
+		return "transit"
+	}
+}
@@ -0,0 +1,95 @@
+package gtfs_test
+
+import (
+	"archive/zip"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/gtfs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFeed(t *testing.T, files map[string]string) string {
+	path := filepath.Join(t.TempDir(), "feed.zip")
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("error creating test feed: %s", err.Error())
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("error creating %s: %s", name, err.Error())
+		}
+
+		_, err = entry.Write([]byte(content))
+		if err != nil {
+			t.Fatalf("error writing %s: %s", name, err.Error())
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf("error closing test feed: %s", err.Error())
+	}
+
+	return path
+}
+
+func TestOpen(t *testing.T) {
+	path := writeTestFeed(t, map[string]string{
+		"agency.txt": "\ufeffagency_id,agency_name,agency_url,agency_timezone\n" +
+			"A,Test Transit,https://example.com,Europe/Berlin\n",
+		"stops.txt": "stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station\n" +
+			"S1,First,51.5,-0.2,0,\n" +
+			"S2,Second,51.6,-0.3,,P\n" +
+			"E1,Entrance,51.6,-0.3,2,P\n",
+		"routes.txt": "route_id,route_short_name,route_long_name,route_type\n" +
+			"R1,42,,3\n",
+		"trips.txt": "route_id,service_id,trip_id,trip_headsign\n" +
+			"R1,WD,T1,Second\n",
+		"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"T1,25:10:00,25:10:30,S2,2\n" +
+			"T1,24:59:00,25:00:00,S1,1\n",
+		"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+			"WD,1,1,1,1,1,0,0,20240101,20241231\n",
+	})
+
+	feed, err := gtfs.Open(path)
+	if err != nil {
+		t.Fatalf("error opening feed: %s", err.Error())
+	}
+
+	if len(feed.Agencies) != 1 || feed.Agencies[0].Timezone != "Europe/Berlin" {
+		t.Errorf("unexpected agencies: %+v", feed.Agencies)
+	}
+
+	if len(feed.Stops) != 2 {
+		t.Errorf("expected entrances to be skipped, got %d stops", len(feed.Stops))
+	}
+
+	if len(feed.StopTimes) != 2 || feed.StopTimes[0].StopID != "S1" {
+		t.Fatalf("expected stop times ordered by sequence, got %+v", feed.StopTimes)
+	}
+
+	if feed.StopTimes[0].DepartureTime != 25*3600 || feed.StopTimes[1].ArrivalTime != 25*3600+600 {
+		t.Errorf("unexpected times after midnight: %+v", feed.StopTimes)
+	}
+
+	if calendar := feed.Calendars[0]; !calendar.Weekdays[1] || calendar.Weekdays[0] {
+		t.Errorf("unexpected weekdays: %v", calendar.Weekdays)
+	}
+}
+
+func TestOpenMissingFile(t *testing.T) {
+	path := writeTestFeed(t, map[string]string{
+		"agency.txt": "agency_name,agency_url,agency_timezone\nTest,https://example.com,UTC\n",
+	})
+
+	_, err := gtfs.Open(path)
+	if err == nil {
+		t.Errorf("expected error for feed without stops")
+	}
+}
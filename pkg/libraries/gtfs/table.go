@@ -0,0 +1,85 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type row struct {
+	header map[string]int
+	values []string
+	line   int
+}
+
+func (r *row) get(column string) string {
+	index, ok := r.header[column]
+	if !ok || index >= len(r.values) {
+		return ""
+	}
+	return strings.TrimSpace(r.values[index])
+}
+
+func (r *row) float(column string) (float64, error) {
+	value, err := strconv.ParseFloat(r.get(column), 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid %s \"%s\"", r.line, column, r.get(column))
+	}
+	return value, nil
+}
+
+func (r *row) int(column string) (int, error) {
+	value, err := strconv.Atoi(r.get(column))
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid %s \"%s\"", r.line, column, r.get(column))
+	}
+	return value, nil
+}
+
+func readTable(file *zip.File, read func(row *row) error) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("error while opening file: %s", err.Error())
+	}
+	defer reader.Close()
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while reading header: %s", err.Error())
+	}
+
+	columns := make(map[string]int, len(header))
+	for index, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = index
+	}
+
+	current := &row{header: columns, line: 1}
+	for {
+		values, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while reading line %d: %s", current.line+1, err.Error())
+		}
+
+		current.values = values
+		current.line++
+
+		err = read(current)
+		if err != nil {
+			return err
+		}
+	}
+}
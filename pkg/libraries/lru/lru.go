@@ -0,0 +1,112 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a thread safe cache, that evicts the least recently used entry when it is full
+type Cache[K comparable, V any] struct {
+	capacity int
+	ttl      time.Duration // entries never expire, if zero
+	now      func() time.Time
+	entries  map[K]*list.Element
+	order    *list.List // most recently used first
+	lock     sync.Mutex
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+func New[K comparable, V any](capacity int) *Cache[K, V] {
+	return NewWithTTL[K, V](capacity, 0)
+}
+
+// NewWithTTL creates a cache, whose entries expire ttl after they were added
+func NewWithTTL[K comparable, V any](capacity int, ttl time.Duration) *Cache[K, V] {
+	return NewWithClock[K, V](capacity, ttl, time.Now)
+}
+
+// NewWithClock creates a cache with expiring entries, that reads the time from now
+func NewWithClock[K comparable, V any](capacity int, ttl time.Duration, now func() time.Time) *Cache[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &Cache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      now,
+		entries:  make(map[K]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		var empty V
+		return empty, false
+	}
+
+	e := element.Value.(*entry[K, V])
+	if c.ttl > 0 && !c.now().Before(e.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+
+		var empty V
+		return empty, false
+	}
+
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+func (c *Cache[K, V]) Add(key K, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry[K, V])
+		e.value = value
+		e.expires = expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.order.Len()
+}
+
+// Purge removes all entries
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries = make(map[K]*list.Element, c.capacity)
+	c.order.Init()
+}
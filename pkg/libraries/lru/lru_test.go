@@ -0,0 +1,66 @@
+package lru_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/lru"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	cache := lru.New[string, int](2)
+
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	// a is used more recently than b
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Fatalf("expected a = 1, got %d, %t", value, ok)
+	}
+
+	cache.Add("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Errorf("expected a = 1, got %d, %t", value, ok)
+	}
+
+	if value, ok := cache.Get("c"); !ok || value != 3 {
+		t.Errorf("expected c = 3, got %d, %t", value, ok)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("expected empty cache after purge, got %d entries", cache.Len())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := lru.NewWithClock[string, int](2, time.Minute, func() time.Time { return now })
+
+	cache.Add("a", 1)
+
+	now = now.Add(30 * time.Second)
+	cache.Add("b", 2)
+
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Fatalf("expected a = 1, got %d, %t", value, ok)
+	}
+
+	now = now.Add(30 * time.Second)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to be expired")
+	}
+
+	if value, ok := cache.Get("b"); !ok || value != 2 {
+		t.Errorf("expected b = 2, got %d, %t", value, ok)
+	}
+
+	if cache.Len() != 1 {
+		t.Errorf("expected the expired entry to be removed, got %d entries", cache.Len())
+	}
+}
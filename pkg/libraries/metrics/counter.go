@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"sync/atomic"
+)
+
+// Counter is a value, that only increases
+type Counter struct {
+	bits atomic.Uint64
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increases the counter, negative values are ignored
+func (c *Counter) Add(value float64) {
+	if value < 0 {
+		return
+	}
+
+	for {
+		old := c.bits.Load()
+		if c.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+value)) {
+			return
+		}
+	}
+}
+
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	metricName string
+	help       string
+	series     *series[Counter]
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	vec := &CounterVec{
+		metricName: name,
+		help:       help,
+		series:     newSeries(labels, func() *Counter { return &Counter{} }),
+	}
+	r.register(vec)
+	return vec
+}
+
+// NewCounter registers a counter without labels
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return r.NewCounterVec(name, help).With()
+}
+
+// With returns the counter for the label values, in the order of the labels of the vector
+func (v *CounterVec) With(values ...string) *Counter {
+	return v.series.with(values)
+}
+
+func (v *CounterVec) name() string {
+	return v.metricName
+}
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, v.metricName, v.help, "counter")
+	v.series.each(func(values []string, counter *Counter) {
+		writeSample(w, v.metricName, formatLabels(v.series.labels, values), counter.Value())
+	})
+}
+
+// GaugeFunc is a value, that is read on every exposition
+type GaugeFunc struct {
+	metricName string
+	help       string
+	kind       string
+	value      func() float64
+}
+
+func (r *Registry) NewGaugeFunc(name, help string, value func() float64) {
+	r.register(&GaugeFunc{metricName: name, help: help, kind: "gauge", value: value})
+}
+
+// NewCounterFunc registers a counter, that is maintained outside of the registry
+func (r *Registry) NewCounterFunc(name, help string, value func() float64) {
+	r.register(&GaugeFunc{metricName: name, help: help, kind: "counter", value: value})
+}
+
+func (g *GaugeFunc) name() string {
+	return g.metricName
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	writeHeader(w, g.metricName, g.help, g.kind)
+	writeSample(w, g.metricName, "", g.value())
+}
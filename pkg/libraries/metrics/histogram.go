@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Histogram counts observations in buckets
+type Histogram struct {
+	upperBounds []float64
+	counts      []uint64 // not cumulative, the last count is the +Inf bucket
+	sum         float64
+	count       uint64
+	lock        sync.Mutex
+}
+
+func (h *Histogram) Observe(value float64) {
+	index := sort.SearchFloat64s(h.upperBounds, value)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.counts[index]++
+	h.sum += value
+	h.count++
+}
+
+// ObserveSince observes the seconds passed since start, it is meant to be deferred
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	metricName string
+	help       string
+	series     *series[Histogram]
+}
+
+// NewHistogramVec registers a histogram, buckets are the upper bounds of the buckets in increasing order
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	upperBounds := append([]float64(nil), buckets...)
+	sort.Float64s(upperBounds)
+
+	vec := &HistogramVec{
+		metricName: name,
+		help:       help,
+		series: newSeries(labels, func() *Histogram {
+			return &Histogram{
+				upperBounds: upperBounds,
+				counts:      make([]uint64, len(upperBounds)+1),
+			}
+		}),
+	}
+	r.register(vec)
+	return vec
+}
+
+// NewHistogram registers a histogram without labels
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	return r.NewHistogramVec(name, help, buckets).With()
+}
+
+// With returns the histogram for the label values, in the order of the labels of the vector
+func (v *HistogramVec) With(values ...string) *Histogram {
+	return v.series.with(values)
+}
+
+func (v *HistogramVec) name() string {
+	return v.metricName
+}
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, v.metricName, v.help, "histogram")
+	v.series.each(func(values []string, histogram *Histogram) {
+		histogram.lock.Lock()
+		counts := append([]uint64(nil), histogram.counts...)
+		sum, count := histogram.sum, histogram.count
+		histogram.lock.Unlock()
+
+		var cumulative uint64
+		for index, upperBound := range histogram.upperBounds {
+			cumulative += counts[index]
+			labels := formatLabels(v.series.labels, values, "le", formatFloat(upperBound))
+			writeSample(w, v.metricName+"_bucket", labels, float64(cumulative))
+		}
+		labels := formatLabels(v.series.labels, values, "le", formatFloat(math.Inf(1)))
+		writeSample(w, v.metricName+"_bucket", labels, float64(count))
+
+		writeSample(w, v.metricName+"_sum", formatLabels(v.series.labels, values), sum)
+		writeSample(w, v.metricName+"_count", formatLabels(v.series.labels, values), float64(count))
+	})
+}
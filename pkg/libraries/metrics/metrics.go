@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default is the registry, that is exposed by the server, it contains the Go runtime metrics
+var Default = newDefaultRegistry()
+
+// DefaultBuckets are the upper bounds of latency histograms in seconds
+var DefaultBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector writes one or more metric families in the Prometheus text exposition format
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text exposition format
+type Registry struct {
+	collectors map[string]collector
+	lock       sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]collector),
+	}
+}
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.RegisterRuntimeMetrics()
+	return registry
+}
+
+func (r *Registry) register(c collector) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.collectors[c.name()]; ok {
+		panic(fmt.Sprintf("metric %s is already registered", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteText writes all metrics sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.lock.Lock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	r.lock.Unlock()
+
+	sort.Strings(names)
+
+	out := bufio.NewWriter(w)
+	for _, name := range names {
+		r.lock.Lock()
+		c := r.collectors[name]
+		r.lock.Unlock()
+
+		c.write(out)
+	}
+
+	return out.Flush()
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeSample(w *bufio.Writer, name string, labels string, value float64) {
+	_, _ = fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(value))
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats label pairs as {a="1",b="2"}, extra pairs are appended after the named labels
+func formatLabels(names []string, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(names)+len(extra)/2)
+	for index, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelValueReplacer.Replace(values[index])))
+	}
+	for index := 0; index+1 < len(extra); index += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[index], labelValueReplacer.Replace(extra[index+1])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// series holds the children of a metric vector by their label values
+type series[T any] struct {
+	labels   []string
+	children map[string]*T
+	values   map[string][]string
+	create   func() *T
+	lock     sync.Mutex
+}
+
+func newSeries[T any](labels []string, create func() *T) *series[T] {
+	return &series[T]{
+		labels:   labels,
+		children: make(map[string]*T),
+		values:   make(map[string][]string),
+		create:   create,
+	}
+}
+
+func (s *series[T]) with(values []string) *T {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("expected %d label values, got %d", len(s.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	child, ok := s.children[key]
+	if !ok {
+		child = s.create()
+		s.children[key] = child
+		s.values[key] = append([]string(nil), values...)
+	}
+	return child
+}
+
+// each calls fn for every child sorted by its label values
+func (s *series[T]) each(fn func(values []string, child *T)) {
+	s.lock.Lock()
+	keys := make([]string, 0, len(s.children))
+	for key := range s.children {
+		keys = append(keys, key)
+	}
+	s.lock.Unlock()
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s.lock.Lock()
+		child, values := s.children[key], s.values[key]
+		s.lock.Unlock()
+
+		fn(values, child)
+	}
+}
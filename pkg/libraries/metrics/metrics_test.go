@@ -0,0 +1,64 @@
+package metrics_test
+
+import (
+	"bytes"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/metrics"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	requests := registry.NewCounterVec("requests_total", "Handled requests.", "endpoint", "status")
+	requests.With("route", "200").Inc()
+	requests.With("route", "200").Add(2)
+	requests.With("search", "4\"0\"4").Inc()
+
+	latency := registry.NewHistogram("latency_seconds", "Request latency.", []float64{1, 0.1})
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(3)
+
+	registry.NewGaugeFunc("answer", "The answer.", func() float64 { return 42 })
+
+	var out bytes.Buffer
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := strings.Join([]string{
+		"# HELP answer The answer.",
+		"# TYPE answer gauge",
+		"answer 42",
+		"# HELP latency_seconds Request latency.",
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{le="0.1"} 1`,
+		`latency_seconds_bucket{le="1"} 2`,
+		`latency_seconds_bucket{le="+Inf"} 3`,
+		"latency_seconds_sum 3.55",
+		"latency_seconds_count 3",
+		"# HELP requests_total Handled requests.",
+		"# TYPE requests_total counter",
+		`requests_total{endpoint="route",status="200"} 3`,
+		`requests_total{endpoint="search",status="4\"0\"4"} 1`,
+		"",
+	}, "\n")
+
+	if out.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.NewCounter("requests_total", "Handled requests.")
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic when registering a metric twice")
+		}
+	}()
+
+	registry.NewCounter("requests_total", "Handled requests.")
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bufio"
+	"runtime"
+)
+
+// runtimeCollector exposes the Go runtime stats, memory stats are read once per exposition
+type runtimeCollector struct{}
+
+// RegisterRuntimeMetrics adds the go_* metrics of the Go runtime to the registry
+func (r *Registry) RegisterRuntimeMetrics() {
+	r.register(runtimeCollector{})
+}
+
+func (runtimeCollector) name() string {
+	return "go_"
+}
+
+func (runtimeCollector) write(w *bufio.Writer) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	samples := []struct {
+		name  string
+		help  string
+		kind  string
+		value float64
+	}{
+		{"go_goroutines", "Number of goroutines that currently exist.", "gauge", float64(runtime.NumGoroutine())},
+		{"go_gc_cycles_total", "Number of completed GC cycles.", "counter", float64(stats.NumGC)},
+		{"go_gc_pause_seconds_total", "Total time spent in GC stop-the-world pauses.", "counter", float64(stats.PauseTotalNs) / 1e9},
+		{"go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", "gauge", float64(stats.Alloc)},
+		{"go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", "counter", float64(stats.TotalAlloc)},
+		{"go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", "gauge", float64(stats.HeapInuse)},
+		{"go_memstats_heap_objects", "Number of allocated objects.", "gauge", float64(stats.HeapObjects)},
+		{"go_memstats_sys_bytes", "Number of bytes obtained from the system.", "gauge", float64(stats.Sys)},
+	}
+
+	for _, sample := range samples {
+		writeHeader(w, sample.name, sample.help, sample.kind)
+		writeSample(w, sample.name, "", sample.value)
+	}
+}
@@ -0,0 +1,135 @@
+package mvt_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/mvt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"testing"
+)
+
+func TestTileID(t *testing.T) {
+	tile := mvt.TileID{Z: 14, X: 8180, Y: 5440}
+
+	minLat, minLon, maxLat, maxLon := tile.Bounds()
+	if minLat >= maxLat || minLon >= maxLon {
+		t.Fatalf("invalid bounds: %f %f %f %f", minLat, minLon, maxLat, maxLon)
+	}
+
+	if p := tile.Project(maxLat, minLon, mvt.DefaultExtent); p != (mvt.TilePoint{}) {
+		t.Errorf("north-west corner projected to %v", p)
+	}
+
+	if p := tile.Project(minLat, maxLon, mvt.DefaultExtent); p != (mvt.TilePoint{X: mvt.DefaultExtent, Y: mvt.DefaultExtent}) {
+		t.Errorf("south-east corner projected to %v", p)
+	}
+
+	if (mvt.TileID{Z: 2, X: 4, Y: 0}).IsValid() {
+		t.Errorf("tile outside of the grid is valid")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	layer := mvt.NewLayer("ways")
+	layer.AddFeature(mvt.Feature{
+		ID:         42,
+		Type:       mvt.LineString,
+		Geometry:   [][]mvt.TilePoint{{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 3, Y: 2}}},
+		Properties: map[string]any{"name": "Main Street", "maxSpeed": 50.0, "oneway": true},
+	})
+	layer.AddFeature(mvt.Feature{
+		Type:     mvt.LineString,
+		Geometry: [][]mvt.TilePoint{{{X: 1, Y: 1}}}, // too short, skipped
+	})
+
+	data, err := (&mvt.Tile{Layers: []*mvt.Layer{layer}}).Marshal()
+	if err != nil {
+		t.Fatalf("error while marshalling: %s", err.Error())
+	}
+
+	layerData := field(t, data, 3)
+	if len(layerData) != 1 {
+		t.Fatalf("expected 1 layer, got %d", len(layerData))
+	}
+
+	if name := string(field(t, layerData[0], 1)[0]); name != "ways" {
+		t.Errorf("expected layer name ways, got %s", name)
+	}
+
+	features := field(t, layerData[0], 2)
+	if len(features) != 1 {
+		t.Fatalf("expected 1 feature, got %d", len(features))
+	}
+
+	if keys := field(t, layerData[0], 3); len(keys) != 3 {
+		t.Errorf("expected 3 keys, got %d", len(keys))
+	}
+
+	geometry := unpack(t, field(t, features[0], 4)[0])
+	expected := []uint64{9, 2, 2, 10, 4, 2} // MoveTo(1) +1 +1, LineTo(1) +2 +1
+	if len(geometry) != len(expected) {
+		t.Fatalf("expected geometry %v, got %v", expected, geometry)
+	}
+	for index := range expected {
+		if geometry[index] != expected[index] {
+			t.Fatalf("expected geometry %v, got %v", expected, geometry)
+		}
+	}
+
+	values := field(t, layerData[0], 4)
+	for _, value := range values {
+		number, typ, n := protowire.ConsumeTag(value)
+		if n < 0 || number != 3 || typ != protowire.Fixed64Type {
+			continue
+		}
+
+		bits, _ := protowire.ConsumeFixed64(value[n:])
+		if math.Float64frombits(bits) != 50 {
+			t.Errorf("expected double value 50, got %f", math.Float64frombits(bits))
+		}
+	}
+}
+
+// field returns the raw content of all length delimited fields with the number
+func field(t *testing.T, data []byte, number protowire.Number) [][]byte {
+	t.Helper()
+
+	var out [][]byte
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			t.Fatalf("invalid tag: %s", protowire.ParseError(n).Error())
+		}
+		data = data[n:]
+
+		if num == number && typ == protowire.BytesType {
+			value, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				t.Fatalf("invalid bytes: %s", protowire.ParseError(m).Error())
+			}
+			out = append(out, value)
+		}
+
+		m := protowire.ConsumeFieldValue(num, typ, data)
+		if m < 0 {
+			t.Fatalf("invalid field: %s", protowire.ParseError(m).Error())
+		}
+		data = data[m:]
+	}
+
+	return out
+}
+
+func unpack(t *testing.T, data []byte) []uint64 {
+	t.Helper()
+
+	var out []uint64
+	for len(data) > 0 {
+		value, n := protowire.ConsumeVarint(data)
+		if n < 0 {
+			t.Fatalf("invalid varint: %s", protowire.ParseError(n).Error())
+		}
+		out = append(out, value)
+		data = data[n:]
+	}
+	return out
+}
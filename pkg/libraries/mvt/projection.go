@@ -0,0 +1,56 @@
+package mvt
+
+import "math"
+
+// MaxLatitude is the latitude, at which the web mercator projection is cut off
+const MaxLatitude = 85.05112877980659
+
+// TileID is the position of a tile in the web mercator tile grid
+type TileID struct {
+	Z int
+	X int
+	Y int
+}
+
+// IsValid checks if the tile exists at its zoom level
+func (t TileID) IsValid() bool {
+	if t.Z < 0 || t.Z > 30 {
+		return false
+	}
+
+	count := 1 << t.Z
+	return t.X >= 0 && t.X < count && t.Y >= 0 && t.Y < count
+}
+
+// Bounds returns the coordinates of the south-west and the north-east corner of the tile
+func (t TileID) Bounds() (minLat, minLon, maxLat, maxLon float64) {
+	count := float64(int(1) << t.Z)
+
+	minLon = float64(t.X)/count*360 - 180
+	maxLon = float64(t.X+1)/count*360 - 180
+	maxLat = tileYToLat(float64(t.Y), count)
+	minLat = tileYToLat(float64(t.Y+1), count)
+
+	return minLat, minLon, maxLat, maxLon
+}
+
+// Project converts a coordinate into the units of the tile with the given extent
+func (t TileID) Project(lat float64, lon float64, extent uint32) TilePoint {
+	count := float64(int(1) << t.Z)
+
+	lat = math.Max(-MaxLatitude, math.Min(MaxLatitude, lat))
+	sin := math.Sin(lat * math.Pi / 180)
+
+	x := (lon + 180) / 360 * count
+	y := (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * count
+
+	return TilePoint{
+		X: int64(math.Round((x - float64(t.X)) * float64(extent))),
+		Y: int64(math.Round((y - float64(t.Y)) * float64(extent))),
+	}
+}
+
+func tileYToLat(y float64, count float64) float64 {
+	n := math.Pi - 2*math.Pi*y/count
+	return math.Atan(math.Sinh(n)) * 180 / math.Pi
+}
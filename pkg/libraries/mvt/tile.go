@@ -0,0 +1,317 @@
+package mvt
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"sort"
+)
+
+// DefaultExtent is the number of units along each side of a tile
+const DefaultExtent = 4096
+
+const layerVersion = 2
+
+type GeometryType int
+
+const (
+	Unknown GeometryType = iota
+	Point
+	LineString
+	Polygon
+)
+
+// Tile is a Mapbox Vector Tile, see https://github.com/mapbox/vector-tile-spec/tree/master/2.1
+type Tile struct {
+	Layers []*Layer
+}
+
+// Layer is a named set of features, the coordinates of the features are given in tile units from 0 to Extent
+type Layer struct {
+	Name     string
+	Extent   uint32
+	Features []Feature
+}
+
+// Feature is a geometry of a layer. The geometry of points contains a single line with all points.
+type Feature struct {
+	ID         uint64
+	Type       GeometryType
+	Geometry   [][]TilePoint
+	Properties map[string]any
+}
+
+// TilePoint is a position in tile units, it may be outside the extent of the tile for buffered features
+type TilePoint struct {
+	X int64
+	Y int64
+}
+
+func NewLayer(name string) *Layer {
+	return &Layer{
+		Name:   name,
+		Extent: DefaultExtent,
+	}
+}
+
+func (l *Layer) AddFeature(feature Feature) {
+	l.Features = append(l.Features, feature)
+}
+
+// field numbers of the vector tile protobuf schema
+const (
+	tileLayers protowire.Number = 3
+
+	layerName     protowire.Number = 1
+	layerFeatures protowire.Number = 2
+	layerKeys     protowire.Number = 3
+	layerValues   protowire.Number = 4
+	layerExtent   protowire.Number = 5
+	layerVersionN protowire.Number = 15
+
+	featureID       protowire.Number = 1
+	featureTags     protowire.Number = 2
+	featureType     protowire.Number = 3
+	featureGeometry protowire.Number = 4
+
+	valueString protowire.Number = 1
+	valueDouble protowire.Number = 3
+	valueInt    protowire.Number = 4
+	valueUint   protowire.Number = 5
+	valueBool   protowire.Number = 7
+)
+
+const (
+	commandMoveTo    = 1
+	commandLineTo    = 2
+	commandClosePath = 7
+)
+
+// Marshal encodes the tile as protobuf
+func (t *Tile) Marshal() ([]byte, error) {
+	var out []byte
+	for _, layer := range t.Layers {
+		encoded, err := layer.marshal()
+		if err != nil {
+			return nil, fmt.Errorf("error while encoding layer %s: %s", layer.Name, err.Error())
+		}
+
+		out = protowire.AppendTag(out, tileLayers, protowire.BytesType)
+		out = protowire.AppendBytes(out, encoded)
+	}
+
+	return out, nil
+}
+
+func (l *Layer) marshal() ([]byte, error) {
+	var out []byte
+	out = protowire.AppendTag(out, layerVersionN, protowire.VarintType)
+	out = protowire.AppendVarint(out, layerVersion)
+
+	out = protowire.AppendTag(out, layerName, protowire.BytesType)
+	out = protowire.AppendString(out, l.Name)
+
+	keys := make(map[string]uint64)
+	var keyList []string
+	values := make(map[any]uint64)
+	var valueList [][]byte
+
+	for _, feature := range l.Features {
+		geometry := encodeGeometry(feature.Type, feature.Geometry)
+		if len(geometry) == 0 {
+			continue
+		}
+
+		// sorted keys keep the encoding deterministic
+		names := make([]string, 0, len(feature.Properties))
+		for name := range feature.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var tags []uint64
+		for _, name := range names {
+			value := feature.Properties[name]
+			if value == nil {
+				continue
+			}
+
+			encodedValue, key, err := encodeValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("error while encoding property %s: %s", name, err.Error())
+			}
+
+			keyIndex, ok := keys[name]
+			if !ok {
+				keyIndex = uint64(len(keyList))
+				keys[name] = keyIndex
+				keyList = append(keyList, name)
+			}
+
+			valueIndex, ok := values[key]
+			if !ok {
+				valueIndex = uint64(len(valueList))
+				values[key] = valueIndex
+				valueList = append(valueList, encodedValue)
+			}
+
+			tags = append(tags, keyIndex, valueIndex)
+		}
+
+		var encoded []byte
+		if feature.ID != 0 {
+			encoded = protowire.AppendTag(encoded, featureID, protowire.VarintType)
+			encoded = protowire.AppendVarint(encoded, feature.ID)
+		}
+
+		if len(tags) > 0 {
+			encoded = protowire.AppendTag(encoded, featureTags, protowire.BytesType)
+			encoded = protowire.AppendBytes(encoded, packVarints(tags))
+		}
+
+		encoded = protowire.AppendTag(encoded, featureType, protowire.VarintType)
+		encoded = protowire.AppendVarint(encoded, uint64(feature.Type))
+
+		encoded = protowire.AppendTag(encoded, featureGeometry, protowire.BytesType)
+		encoded = protowire.AppendBytes(encoded, packVarints(geometry))
+
+		out = protowire.AppendTag(out, layerFeatures, protowire.BytesType)
+		out = protowire.AppendBytes(out, encoded)
+	}
+
+	for _, key := range keyList {
+		out = protowire.AppendTag(out, layerKeys, protowire.BytesType)
+		out = protowire.AppendString(out, key)
+	}
+
+	for _, value := range valueList {
+		out = protowire.AppendTag(out, layerValues, protowire.BytesType)
+		out = protowire.AppendBytes(out, value)
+	}
+
+	extent := l.Extent
+	if extent == 0 {
+		extent = DefaultExtent
+	}
+	out = protowire.AppendTag(out, layerExtent, protowire.VarintType)
+	out = protowire.AppendVarint(out, uint64(extent))
+
+	return out, nil
+}
+
+// valueKey identifies equal values of different types, e.g. the string "1" and the number 1
+type valueKey struct {
+	kind  protowire.Number
+	value any
+}
+
+func encodeValue(value any) ([]byte, valueKey, error) {
+	var out []byte
+	var key valueKey
+
+	switch v := value.(type) {
+	case string:
+		out = protowire.AppendTag(out, valueString, protowire.BytesType)
+		out = protowire.AppendString(out, v)
+		key = valueKey{valueString, v}
+	case bool:
+		out = protowire.AppendTag(out, valueBool, protowire.VarintType)
+		out = protowire.AppendVarint(out, protowire.EncodeBool(v))
+		key = valueKey{valueBool, v}
+	case int:
+		return encodeValue(int64(v))
+	case int64:
+		out = protowire.AppendTag(out, valueInt, protowire.VarintType)
+		out = protowire.AppendVarint(out, uint64(v))
+		key = valueKey{valueInt, v}
+	case uint64:
+		out = protowire.AppendTag(out, valueUint, protowire.VarintType)
+		out = protowire.AppendVarint(out, v)
+		key = valueKey{valueUint, v}
+	case float64:
+		out = protowire.AppendTag(out, valueDouble, protowire.Fixed64Type)
+		out = protowire.AppendFixed64(out, math.Float64bits(v))
+		key = valueKey{valueDouble, v}
+	default:
+		return nil, key, fmt.Errorf("unsupported type %T", value)
+	}
+
+	return out, key, nil
+}
+
+// encodeGeometry encodes the lines as commands with zigzag encoded deltas, repeated points are dropped
+func encodeGeometry(geometryType GeometryType, lines [][]TilePoint) []uint64 {
+	var out []uint64
+	var cursor TilePoint
+
+	for _, line := range lines {
+		var points []TilePoint
+		for _, point := range line {
+			if geometryType != Point && len(points) > 0 && points[len(points)-1] == point {
+				continue
+			}
+			points = append(points, point)
+		}
+
+		switch geometryType {
+		case Point:
+			if len(points) == 0 {
+				continue
+			}
+			out = append(out, command(commandMoveTo, len(points)))
+			for _, point := range points {
+				out = append(out, delta(&cursor, point)...)
+			}
+
+		case LineString:
+			if len(points) < 2 {
+				continue
+			}
+			out = append(out, command(commandMoveTo, 1))
+			out = append(out, delta(&cursor, points[0])...)
+			out = append(out, command(commandLineTo, len(points)-1))
+			for _, point := range points[1:] {
+				out = append(out, delta(&cursor, point)...)
+			}
+
+		case Polygon:
+			// the closing point is implied by the close path command
+			if len(points) > 1 && points[0] == points[len(points)-1] {
+				points = points[:len(points)-1]
+			}
+			if len(points) < 3 {
+				continue
+			}
+			out = append(out, command(commandMoveTo, 1))
+			out = append(out, delta(&cursor, points[0])...)
+			out = append(out, command(commandLineTo, len(points)-1))
+			for _, point := range points[1:] {
+				out = append(out, delta(&cursor, point)...)
+			}
+			out = append(out, command(commandClosePath, 1))
+		}
+	}
+
+	return out
+}
+
+func command(id int, count int) uint64 {
+	return uint64(id&0x7) | uint64(count)<<3
+}
+
+func delta(cursor *TilePoint, point TilePoint) []uint64 {
+	out := []uint64{
+		protowire.EncodeZigZag(point.X - cursor.X),
+		protowire.EncodeZigZag(point.Y - cursor.Y),
+	}
+	*cursor = point
+	return out
+}
+
+func packVarints(values []uint64) []byte {
+	var out []byte
+	for _, value := range values {
+		out = protowire.AppendVarint(out, value)
+	}
+	return out
+}
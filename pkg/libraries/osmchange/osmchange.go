@@ -0,0 +1,269 @@
+package osmchange
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmpbfreaderdata"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Action is the section of an OsmChange file, an element is part of
+type Action int
+
+const (
+	Create Action = iota
+	Modify
+	Delete
+)
+
+func (a Action) String() string {
+	switch a {
+	case Create:
+		return "create"
+	case Modify:
+		return "modify"
+	case Delete:
+		return "delete"
+	default:
+		return fmt.Sprintf("action(%d)", int(a))
+	}
+}
+
+// Change is a single element of an OsmChange file, exactly one of Node, Way and Relation is set
+type Change struct {
+	Action   Action
+	Node     *osmpbfreaderdata.Node
+	Way      *osmpbfreaderdata.Way
+	Relation *osmpbfreaderdata.Relation
+}
+
+// Decoder reads the changes of an OsmChange file in the order of the file
+type Decoder interface {
+	// Decode returns the next change or io.EOF at the end of the file
+	Decode() (*Change, error)
+}
+
+type impl struct {
+	decoder *xml.Decoder
+	action  *Action
+}
+
+func New(reader io.Reader) Decoder {
+	return &impl{
+		decoder: xml.NewDecoder(reader),
+	}
+}
+
+// Open opens an OsmChange file, files ending with .gz are decompressed
+func Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening file: %s", err.Error())
+	}
+
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("error while opening gzip reader: %s", err.Error())
+	}
+
+	return &gzipFile{Reader: reader, file: file}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	err := g.Reader.Close()
+	if closeErr := g.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+var actions = map[string]Action{
+	"create": Create,
+	"modify": Modify,
+	"delete": Delete,
+}
+
+func (i *impl) Decode() (*Change, error) {
+	for {
+		token, err := i.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if action, ok := actions[element.Name.Local]; ok {
+				i.action = &action
+				continue
+			}
+
+			if element.Name.Local == "osmChange" {
+				continue
+			}
+
+			if i.action == nil {
+				// elements outside of create, modify and delete, e.g. bounds
+				err = i.decoder.Skip()
+				if err != nil {
+					return nil, fmt.Errorf("error while skipping %s: %s", element.Name.Local, err.Error())
+				}
+				continue
+			}
+
+			change, err := i.decodeElement(element)
+			if err != nil {
+				return nil, err
+			}
+
+			if change != nil {
+				return change, nil
+			}
+
+		case xml.EndElement:
+			if _, ok := actions[element.Name.Local]; ok {
+				i.action = nil
+			}
+		}
+	}
+}
+
+type xmlTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+type xmlElement struct {
+	ID        int64       `xml:"id,attr"`
+	Version   int32       `xml:"version,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Changeset int64       `xml:"changeset,attr"`
+	Uid       int32       `xml:"uid,attr"`
+	User      string      `xml:"user,attr"`
+	Visible   *bool       `xml:"visible,attr"`
+	Lat       float64     `xml:"lat,attr"`
+	Lon       float64     `xml:"lon,attr"`
+	Tags      []xmlTag    `xml:"tag"`
+	Nodes     []xmlNode   `xml:"nd"`
+	Members   []xmlMember `xml:"member"`
+}
+
+type xmlNode struct {
+	Ref int64 `xml:"ref,attr"`
+}
+
+type xmlMember struct {
+	Type string `xml:"type,attr"`
+	Ref  int64  `xml:"ref,attr"`
+	Role string `xml:"role,attr"`
+}
+
+var memberTypes = map[string]osmpbfreaderdata.MemberType{
+	"node":     osmpbfreaderdata.NodeType,
+	"way":      osmpbfreaderdata.WayType,
+	"relation": osmpbfreaderdata.RelationType,
+}
+
+func (i *impl) decodeElement(start xml.StartElement) (*Change, error) {
+	var element xmlElement
+	err := i.decoder.DecodeElement(&element, &start)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding %s: %s", start.Name.Local, err.Error())
+	}
+
+	info, err := element.info()
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding %s %d: %s", start.Name.Local, element.ID, err.Error())
+	}
+
+	change := &Change{Action: *i.action}
+
+	switch start.Name.Local {
+	case "node":
+		change.Node = &osmpbfreaderdata.Node{
+			ID:   element.ID,
+			Lat:  element.Lat,
+			Lon:  element.Lon,
+			Tags: element.tags(),
+			Info: info,
+		}
+
+	case "way":
+		nodeIDs := make([]int64, len(element.Nodes))
+		for index, node := range element.Nodes {
+			nodeIDs[index] = node.Ref
+		}
+
+		change.Way = &osmpbfreaderdata.Way{
+			ID:      element.ID,
+			Tags:    element.tags(),
+			NodeIDs: nodeIDs,
+			Info:    info,
+		}
+
+	case "relation":
+		members := make([]osmpbfreaderdata.Member, len(element.Members))
+		for index, member := range element.Members {
+			memberType, ok := memberTypes[member.Type]
+			if !ok {
+				return nil, fmt.Errorf("error while decoding relation %d: unknown member type %q", element.ID, member.Type)
+			}
+
+			members[index] = osmpbfreaderdata.Member{ID: member.Ref, Type: memberType, Role: member.Role}
+		}
+
+		change.Relation = &osmpbfreaderdata.Relation{
+			ID:      element.ID,
+			Tags:    element.tags(),
+			Members: members,
+			Info:    info,
+		}
+
+	default:
+		// unknown elements are ignored
+		return nil, nil
+	}
+
+	return change, nil
+}
+
+func (e *xmlElement) tags() map[string]string {
+	out := make(map[string]string, len(e.Tags))
+	for _, tag := range e.Tags {
+		out[tag.Key] = tag.Value
+	}
+	return out
+}
+
+func (e *xmlElement) info() (osmpbfreaderdata.Info, error) {
+	out := osmpbfreaderdata.Info{
+		Version:   e.Version,
+		Uid:       e.Uid,
+		Changeset: e.Changeset,
+		User:      e.User,
+		Visible:   e.Visible == nil || *e.Visible,
+	}
+
+	if e.Timestamp != "" {
+		timestamp, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			return out, fmt.Errorf("invalid timestamp %q", e.Timestamp)
+		}
+		out.Timestamp = timestamp
+	}
+
+	return out, nil
+}
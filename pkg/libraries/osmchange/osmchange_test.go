@@ -0,0 +1,114 @@
+package osmchange_test
+
+import (
+	"errors"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmchange"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testChange = `<?xml version="1.0" encoding="UTF-8"?>
+<osmChange version="0.6" generator="test">
+  <create>
+    <node id="1" version="1" timestamp="2024-01-02T03:04:05Z" lat="48.1" lon="11.5">
+      <tag k="addr:street" v="Hauptstraße"/>
+    </node>
+    <way id="10" version="1">
+      <nd ref="1"/>
+      <nd ref="2"/>
+      <tag k="highway" v="residential"/>
+    </way>
+  </create>
+  <modify>
+    <relation id="100" version="3">
+      <member type="way" ref="10" role="outer"/>
+    </relation>
+  </modify>
+  <delete>
+    <node id="2" version="4" lat="48.2" lon="11.6" visible="false"/>
+  </delete>
+</osmChange>`
+
+func TestDecode(t *testing.T) {
+	decoder := osmchange.New(strings.NewReader(testChange))
+
+	var changes []*osmchange.Change
+	for {
+		change, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		changes = append(changes, change)
+	}
+
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %d", len(changes))
+	}
+
+	node := changes[0]
+	if node.Action != osmchange.Create || node.Node == nil || node.Node.ID != 1 || node.Node.Lat != 48.1 || node.Node.Tags["addr:street"] != "Hauptstraße" {
+		t.Errorf("unexpected node change %+v", node)
+	}
+
+	if node.Node.Info.Timestamp.Year() != 2024 || !node.Node.Info.Visible {
+		t.Errorf("unexpected node info %+v", node.Node.Info)
+	}
+
+	way := changes[1]
+	if way.Action != osmchange.Create || way.Way == nil || len(way.Way.NodeIDs) != 2 || way.Way.NodeIDs[1] != 2 || way.Way.Tags["highway"] != "residential" {
+		t.Errorf("unexpected way change %+v", way)
+	}
+
+	relation := changes[2]
+	if relation.Action != osmchange.Modify || relation.Relation == nil || len(relation.Relation.Members) != 1 || relation.Relation.Members[0].Role != "outer" {
+		t.Errorf("unexpected relation change %+v", relation)
+	}
+
+	deleted := changes[3]
+	if deleted.Action != osmchange.Delete || deleted.Node == nil || deleted.Node.ID != 2 || deleted.Node.Info.Visible {
+		t.Errorf("unexpected delete change %+v", deleted)
+	}
+}
+
+func TestStateOf(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "000", "004")
+	err := os.MkdirAll(directory, 0o755)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	state, err := osmchange.StateOf(filepath.Join(directory, "123.osc.gz"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if state.SequenceNumber != 4123 {
+		t.Errorf("expected sequence number 4123 from the path, got %d", state.SequenceNumber)
+	}
+
+	stateFile := "#Tue Jan 02 03:04:05 UTC 2024\nsequenceNumber=4124\ntimestamp=2024-01-02T03\\:04\\:05Z\n"
+	err = os.WriteFile(filepath.Join(directory, "124.state.txt"), []byte(stateFile), 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	state, err = osmchange.StateOf(filepath.Join(directory, "124.osc.gz"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if state.SequenceNumber != 4124 || state.Timestamp.Hour() != 3 {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	_, err = osmchange.StateOf(filepath.Join(t.TempDir(), "changes.osc"))
+	if err == nil {
+		t.Errorf("expected an error without state file and replication path")
+	}
+}
@@ -0,0 +1,101 @@
+package osmchange
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// State is the replication state of a change file, as written by the OSM replication servers into *.state.txt
+type State struct {
+	SequenceNumber int64
+	Timestamp      time.Time
+}
+
+// ReadState parses a replication state file, java property escapes like \: are removed
+func ReadState(reader io.Reader) (*State, error) {
+	out := &State{}
+	found := false
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.ReplaceAll(value, `\`, "")
+
+		switch strings.TrimSpace(key) {
+		case "sequenceNumber":
+			sequenceNumber, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sequence number %q", value)
+			}
+			out.SequenceNumber = sequenceNumber
+			found = true
+
+		case "timestamp":
+			timestamp, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q", value)
+			}
+			out.Timestamp = timestamp
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading state: %s", err.Error())
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no sequence number found")
+	}
+
+	return out, nil
+}
+
+// StateOf finds the replication state of a change file.
+// It reads the state file next to the change file (123.state.txt for 123.osc.gz) and falls back to the
+// sequence number of the replication directory layout, e.g. 000/004/123.osc.gz is sequence number 4123.
+func StateOf(path string) (*State, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(path, ".gz"), ".osc")
+
+	file, err := os.Open(base + ".state.txt")
+	if err == nil {
+		defer file.Close()
+		return ReadState(file)
+	}
+
+	parts := []string{
+		filepath.Base(filepath.Dir(filepath.Dir(base))),
+		filepath.Base(filepath.Dir(base)),
+		filepath.Base(base),
+	}
+
+	for _, part := range parts {
+		if len(part) != 3 {
+			return nil, fmt.Errorf("no state file and no replication path: %s", path)
+		}
+
+		if _, err := strconv.Atoi(part); err != nil {
+			return nil, fmt.Errorf("no state file and no replication path: %s", path)
+		}
+	}
+
+	sequenceNumber, err := strconv.ParseInt(strings.Join(parts, ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replication path %s: %s", path, err.Error())
+	}
+
+	return &State{SequenceNumber: sequenceNumber}, nil
+}
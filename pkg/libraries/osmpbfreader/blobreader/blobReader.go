@@ -20,12 +20,21 @@ const maxBlobHeaderSize = 32 * 1024
 const maxBlobSize = 64 * 1024 * 1024
 
 type BlobReader interface {
-	Read(blobs chan valueerrpair.Pair[*osmproto.Blob])
+	Read(blobs chan valueerrpair.Pair[Blob])
 	Header() (*osmpbfreaderdata.Header, error)
 }
 
+// Blob is a data blob and the offset of its end in the file, a seekable reader can be resumed there
+type Blob struct {
+	Data *osmproto.Blob
+	End  int64
+}
+
 type impl struct {
 	reader        io.Reader
+	seeker        io.Seeker
+	start         int64
+	offset        int64
 	buffer        *bytes.Buffer
 	osmHeaderOnce sync.Once
 	osmHeader     *osmpbfreaderdata.Header
@@ -38,30 +47,50 @@ func NewBlobReader(reader io.Reader) BlobReader {
 	}
 }
 
+// NewSeekableBlobReader reads the header and continues with the blob at the offset, which has to be
+// the End of a blob read before
+func NewSeekableBlobReader(reader io.ReadSeeker, offset int64) BlobReader {
+	return &impl{
+		reader: reader,
+		seeker: reader,
+		start:  offset,
+		buffer: bytes.NewBuffer(make([]byte, 0, maxBlobSize)),
+	}
+}
+
 func (i *impl) Header() (*osmpbfreaderdata.Header, error) {
 	return i.osmHeader, i.readHeaderBlock()
 }
 
-func (i *impl) Read(blobs chan valueerrpair.Pair[*osmproto.Blob]) {
+func (i *impl) Read(blobs chan valueerrpair.Pair[Blob]) {
 	err := i.readHeaderBlock()
 	if err != nil {
-		blobs <- valueerrpair.Pair[*osmproto.Blob]{Err: fmt.Errorf("error reading header: %s", err.Error())}
+		blobs <- valueerrpair.Pair[Blob]{Err: fmt.Errorf("error reading header: %s", err.Error())}
 		close(blobs)
 		return
 	}
 
+	if i.seeker != nil && i.start > i.offset {
+		i.offset, err = i.seeker.Seek(i.start, io.SeekStart)
+		if err != nil {
+			blobs <- valueerrpair.Pair[Blob]{Err: fmt.Errorf("error seeking to offset %d: %s", i.start, err.Error())}
+			close(blobs)
+			return
+		}
+	}
+
 	for {
 		blobHeader, blob, err := i.readBlock()
 		if err != nil || blobHeader.GetType() != expectedBlobDataType {
 			if blobHeader.GetType() != expectedBlobDataType && err == nil {
 				err = fmt.Errorf("invalid type: \"%s\"", blobHeader.GetType())
 			}
-			blobs <- valueerrpair.Pair[*osmproto.Blob]{Err: EOFor(err, fmt.Errorf("error reading blob: %s", err.Error()))}
+			blobs <- valueerrpair.Pair[Blob]{Err: EOFor(err, fmt.Errorf("error reading blob: %s", err.Error()))}
 			close(blobs)
 			return
 		}
 
-		blobs <- valueerrpair.Pair[*osmproto.Blob]{Value: blob}
+		blobs <- valueerrpair.Pair[Blob]{Value: Blob{Data: blob, End: i.offset}}
 	}
 }
 
@@ -110,6 +139,7 @@ func (i *impl) readBlobHeaderSize() (uint32, error) {
 	if _, err := io.CopyN(i.buffer, i.reader, 4); err != nil {
 		return 0, EOFor(err, fmt.Errorf("error reading blob header size: %s", err.Error()))
 	}
+	i.offset += 4
 
 	size := binary.BigEndian.Uint32(i.buffer.Bytes())
 
@@ -124,6 +154,7 @@ func (i *impl) readBlobHeader(size uint32) (*osmproto.BlobHeader, error) {
 	if _, err := io.CopyN(i.buffer, i.reader, int64(size)); err != nil {
 		return nil, EOFor(err, fmt.Errorf("error reading blob header: %s", err.Error()))
 	}
+	i.offset += int64(size)
 
 	blobHeader := new(osmproto.BlobHeader)
 	if err := proto.Unmarshal(i.buffer.Bytes(), blobHeader); err != nil {
@@ -142,6 +173,7 @@ func (i *impl) readBlob(blobHeader *osmproto.BlobHeader) (*osmproto.Blob, error)
 	if _, err := io.CopyN(i.buffer, i.reader, int64(blobHeader.GetDatasize())); err != nil {
 		return nil, EOFor(err, fmt.Errorf("error reading blob: %s", err.Error()))
 	}
+	i.offset += int64(blobHeader.GetDatasize())
 
 	blob := new(osmproto.Blob)
 	if err := proto.Unmarshal(i.buffer.Bytes(), blob); err != nil {
@@ -5,7 +5,7 @@ import (
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/blobreader"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/datadecoder"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/filter"
-	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmproto"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/osmpbfreaderdata"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/osmpbfreader/valueerrpair"
 	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/workerpool"
 	"io"
@@ -24,6 +24,7 @@ type impl struct {
 	blobReader blobreader.BlobReader
 	serializer chan valueerrpair.Pair[any]
 	done       chan struct{}
+	blobEnds   bool
 }
 
 func New(reader io.Reader) Decoder {
@@ -33,14 +34,24 @@ func New(reader io.Reader) Decoder {
 	}
 }
 
+// NewResumable starts decoding at the offset of a osmpbfreaderdata.BlobEnd of an earlier decoder and
+// returns a osmpbfreaderdata.BlobEnd after the elements of each blob
+func NewResumable(reader io.ReadSeeker, offset int64) Decoder {
+	return &impl{
+		blobReader: blobreader.NewSeekableBlobReader(reader, offset),
+		serializer: make(chan valueerrpair.Pair[any], standartPrimitiveFeatureCount),
+		blobEnds:   true,
+	}
+}
+
 func (i *impl) Start(count ProcsCount, filter filter.Filter) error {
 	i.done = make(chan struct{})
-	blobs := make(chan valueerrpair.Pair[*osmproto.Blob], count)
+	blobs := make(chan valueerrpair.Pair[blobreader.Blob], count)
 	pool := workerpool.New[valueerrpair.Pair[[]interface{}]](workerpool.ProcsCount(count))
 	pool.Start()
 
 	go i.blobReader.Read(blobs)
-	go decoderHandler(pool, blobs, filter)
+	go decoderHandler(pool, blobs, filter, i.blobEnds)
 	go i.decodedBlobHandler(pool)
 
 	go func() {
@@ -70,7 +81,7 @@ func (i *impl) decodedBlobHandler(pool workerpool.Pool[valueerrpair.Pair[[]inter
 	}
 }
 
-func decoderHandler(pool workerpool.Pool[valueerrpair.Pair[[]interface{}]], blobs chan valueerrpair.Pair[*osmproto.Blob], filter filter.Filter) {
+func decoderHandler(pool workerpool.Pool[valueerrpair.Pair[[]interface{}]], blobs chan valueerrpair.Pair[blobreader.Blob], filter filter.Filter, blobEnds bool) {
 	for {
 		blob, ok := <-blobs
 		if !ok {
@@ -85,10 +96,13 @@ func decoderHandler(pool workerpool.Pool[valueerrpair.Pair[[]interface{}]], blob
 		}
 
 		pool.Submit(func(int) valueerrpair.Pair[[]interface{}] {
-			data, err := datadecoder.NewDataDecoder().Decode(blob.Value, filter)
+			data, err := datadecoder.NewDataDecoder().Decode(blob.Value.Data, filter)
 			if err != nil {
 				return valueerrpair.Pair[[]interface{}]{Err: err}
 			}
+			if blobEnds {
+				data = append(data, osmpbfreaderdata.BlobEnd{Offset: blob.Value.End})
+			}
 			return valueerrpair.Pair[[]interface{}]{Value: data}
 		})
 	}
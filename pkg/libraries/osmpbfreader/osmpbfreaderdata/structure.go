@@ -51,6 +51,11 @@ type Relation struct {
 	Info    Info
 }
 
+// BlobEnd follows the elements of a blob, if the decoder is resumable. The decoder can be resumed at Offset.
+type BlobEnd struct {
+	Offset int64
+}
+
 type MemberType int
 
 const (
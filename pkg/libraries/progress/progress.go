@@ -0,0 +1,172 @@
+package progress
+
+import (
+	"sync"
+	"time"
+)
+
+// Unit is the unit of the progress of a phase
+type Unit string
+
+const (
+	Bytes Unit = "bytes"
+	Rows  Unit = "rows"
+)
+
+// Report is the progress of a phase. Percent and ETA are only known, if the phase has a total.
+type Report struct {
+	Time           time.Time `json:"time"`
+	Phase          string    `json:"phase"`
+	Unit           Unit      `json:"unit"`
+	Done           int64     `json:"done"`
+	Total          int64     `json:"total"`
+	Percent        float64   `json:"percent"`
+	Rate           float64   `json:"rate"`  // units per second
+	Steps          int64     `json:"steps"` // the blobs of a pass or the chunks of a query
+	StepsPerSecond float64   `json:"stepsPerSecond"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	EtaSeconds     float64   `json:"etaSeconds"` // -1, if unknown
+	Finished       bool      `json:"finished"`
+}
+
+// Tracker reports the progress of the current phase when it starts and finishes and every interval in between,
+// so that phases without updates still show that they are running
+type Tracker interface {
+	// Start begins a phase, done is the progress of a phase continued after a restart, total is 0 if unknown
+	Start(phase string, unit Unit, done int64, total int64)
+	// Update sets the progress of the phase and counts a step
+	Update(done int64)
+	Finish()
+
+	// Stop ends the periodic reports
+	Stop()
+}
+
+type impl struct {
+	sinks    []func(Report)
+	interval time.Duration
+
+	lock    sync.Mutex
+	running bool
+	phase   string
+	unit    Unit
+	start   time.Time
+	initial int64
+	done    int64
+	total   int64
+	steps   int64
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func New(interval time.Duration, sinks ...func(Report)) Tracker {
+	i := &impl{
+		sinks:    sinks,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+
+	go i.tick()
+
+	return i
+}
+
+func (i *impl) tick() {
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.stop:
+			return
+		case <-ticker.C:
+			i.lock.Lock()
+			if i.running {
+				i.emit(false)
+			}
+			i.lock.Unlock()
+		}
+	}
+}
+
+func (i *impl) Start(phase string, unit Unit, done int64, total int64) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.running = true
+	i.phase, i.unit = phase, unit
+	i.start = time.Now()
+	i.initial, i.done, i.total = done, done, total
+	i.steps = 0
+
+	i.emit(false)
+}
+
+func (i *impl) Update(done int64) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.done = done
+	i.steps++
+}
+
+func (i *impl) Finish() {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if !i.running {
+		return
+	}
+
+	if i.total > 0 {
+		i.done = i.total
+	}
+
+	i.emit(true)
+	i.running = false
+}
+
+func (i *impl) Stop() {
+	i.once.Do(func() {
+		close(i.stop)
+	})
+}
+
+// emit has to be called with the lock held
+func (i *impl) emit(finished bool) {
+	now := time.Now()
+	elapsed := now.Sub(i.start).Seconds()
+
+	report := Report{
+		Time:           now,
+		Phase:          i.phase,
+		Unit:           i.unit,
+		Done:           i.done,
+		Total:          i.total,
+		Steps:          i.steps,
+		ElapsedSeconds: elapsed,
+		EtaSeconds:     -1,
+		Finished:       finished,
+	}
+
+	if elapsed > 0 {
+		// the progress before a restart is not part of the rate
+		report.Rate = float64(i.done-i.initial) / elapsed
+		report.StepsPerSecond = float64(i.steps) / elapsed
+	}
+
+	if i.total > 0 {
+		report.Percent = 100 * float64(i.done) / float64(i.total)
+
+		if finished {
+			report.EtaSeconds = 0
+		} else if report.Rate > 0 {
+			report.EtaSeconds = float64(i.total-i.done) / report.Rate
+		}
+	}
+
+	for _, sink := range i.sinks {
+		sink(report)
+	}
+}
@@ -0,0 +1,89 @@
+package progress_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/progress"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	var reports []progress.Report
+	tracker := progress.New(time.Hour, func(report progress.Report) {
+		reports = append(reports, report)
+	})
+	defer tracker.Stop()
+
+	tracker.Start("ways", progress.Bytes, 100, 1000)
+	time.Sleep(10 * time.Millisecond)
+	tracker.Update(400)
+	tracker.Update(550)
+	tracker.Finish()
+
+	if len(reports) != 2 {
+		t.Fatalf("expected a report for the start and the end, got %d", len(reports))
+	}
+
+	start, end := reports[0], reports[1]
+	if start.Done != 100 || start.Percent != 10 || start.EtaSeconds != -1 || start.Finished {
+		t.Errorf("unexpected start report: %+v", start)
+	}
+
+	if end.Done != 1000 || end.Percent != 100 || end.Steps != 2 || end.EtaSeconds != 0 || !end.Finished {
+		t.Errorf("unexpected end report: %+v", end)
+	}
+
+	// the progress before the start is not counted into the rate
+	if expected := 900 / end.ElapsedSeconds; end.Rate > expected*1.01 || end.Rate < expected*0.99 {
+		t.Errorf("rate = %f, expected %f", end.Rate, expected)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	report := progress.Report{
+		Phase:          "nodes",
+		Unit:           progress.Bytes,
+		Done:           3 << 30,
+		Total:          4 << 30,
+		Percent:        75,
+		Rate:           10 << 20,
+		StepsPerSecond: 120,
+		ElapsedSeconds: 300,
+		EtaSeconds:     100,
+	}
+
+	expected := "nodes: 75.0% (3.0 GiB of 4.0 GiB), 10.0 MiB/s, 120 steps/s, elapsed 5m0s, ETA 1m40s"
+	if actual := progress.Format(report); actual != expected {
+		t.Errorf("Format() = %q, expected %q", actual, expected)
+	}
+
+	report = progress.Report{Phase: "nodeIndices", Unit: progress.Rows, ElapsedSeconds: 65, EtaSeconds: -1}
+	if actual := progress.Format(report); actual != "nodeIndices: running for 1m5s" {
+		t.Errorf("Format() = %q", actual)
+	}
+}
+
+func TestJsonSink(t *testing.T) {
+	var buffer bytes.Buffer
+	sink := progress.JsonSink(&buffer)
+
+	sink(progress.Report{Phase: "ways", Done: 1})
+	sink(progress.Report{Phase: "nodes", Done: 2})
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var report progress.Report
+	err := json.Unmarshal([]byte(lines[1]), &report)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if report.Phase != "nodes" || report.Done != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,84 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/logging"
+	"io"
+	"sync"
+	"time"
+)
+
+// LogSink writes the reports as readable log messages
+func LogSink(logger logging.Logger) func(Report) {
+	return func(report Report) {
+		logger.Info().Msg(Format(report))
+	}
+}
+
+// JsonSink writes each report as a line of json, e.g. for dashboards
+func JsonSink(writer io.Writer) func(Report) {
+	var lock sync.Mutex
+	encoder := json.NewEncoder(writer)
+
+	return func(report Report) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		_ = encoder.Encode(report)
+	}
+}
+
+// Format describes a report in one line, like "ways: 42.0% (1.2 GB of 3.9 GB), 18.3 MB/s, 350 steps/s, elapsed 1m0s, ETA 3m20s"
+func Format(report Report) string {
+	elapsed := formatDuration(report.ElapsedSeconds)
+
+	if report.Finished {
+		if report.Done <= 0 {
+			return fmt.Sprintf("%s: finished in %s", report.Phase, elapsed)
+		}
+		return fmt.Sprintf("%s: finished %s in %s", report.Phase, formatAmount(report.Done, report.Unit), elapsed)
+	}
+
+	if report.Total <= 0 {
+		if report.Done > 0 {
+			return fmt.Sprintf("%s: %s, running for %s", report.Phase, formatAmount(report.Done, report.Unit), elapsed)
+		}
+		return fmt.Sprintf("%s: running for %s", report.Phase, elapsed)
+	}
+
+	eta := "unknown"
+	if report.EtaSeconds >= 0 {
+		eta = formatDuration(report.EtaSeconds)
+	}
+
+	return fmt.Sprintf("%s: %.1f%% (%s of %s), %s/s, %.0f steps/s, elapsed %s, ETA %s",
+		report.Phase, report.Percent,
+		formatAmount(report.Done, report.Unit), formatAmount(report.Total, report.Unit),
+		formatAmount(int64(report.Rate), report.Unit), report.StepsPerSecond,
+		elapsed, eta,
+	)
+}
+
+func formatDuration(seconds float64) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func formatAmount(amount int64, unit Unit) string {
+	if unit != Bytes {
+		return fmt.Sprintf("%d %s", amount, unit)
+	}
+
+	const base = 1024
+	if amount < base {
+		return fmt.Sprintf("%d B", amount)
+	}
+
+	value, exponent := float64(amount), 0
+	for value >= base && exponent < 4 {
+		value /= base
+		exponent++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exponent-1])
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of calls to Allow, after which full buckets are removed
+const sweepInterval = 1024
+
+// Limiter is a thread safe token bucket limiter with one bucket per key.
+// Every bucket holds up to burst tokens and is refilled with rate tokens per second.
+type Limiter struct {
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	calls   int
+	now     func() time.Time
+	lock    sync.Mutex
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func New(rate float64, burst float64) *Limiter {
+	return NewWithClock(rate, burst, time.Now)
+}
+
+// NewWithClock creates a limiter, that reads the time from now
+func NewWithClock(rate float64, burst float64, now func() time.Time) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*bucket),
+		now:     now,
+	}
+}
+
+// Allow takes a token from the bucket of key. If the bucket is empty, it returns false and the time until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+
+	l.calls++
+	if l.calls%sweepInterval == 0 {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = l.tokens(b, now)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, l.wait(b.tokens)
+}
+
+// Peek reports like Allow whether the bucket of key has a token, without taking it
+func (l *Limiter) Peek(key string) (bool, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		return true, 0
+	}
+
+	tokens := l.tokens(b, l.now())
+	if tokens >= 1 {
+		return true, 0
+	}
+
+	return false, l.wait(tokens)
+}
+
+// tokens returns the tokens of the bucket refilled until now
+func (l *Limiter) tokens(b *bucket, now time.Time) float64 {
+	return math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+}
+
+// wait returns the time until a bucket with tokens has a full token
+func (l *Limiter) wait(tokens float64) time.Duration {
+	if l.rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	wait := (1 - tokens) / l.rate
+	return time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// sweep removes buckets, that would be full again, they behave like new buckets
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
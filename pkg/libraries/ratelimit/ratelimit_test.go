@@ -0,0 +1,67 @@
+package ratelimit_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := ratelimit.NewWithClock(2, 3, func() time.Time { return now })
+
+	for request := 0; request < 3; request++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("expected request %d to be allowed", request)
+		}
+	}
+
+	ok, retryAfter := limiter.Allow("a")
+	if ok {
+		t.Fatalf("expected the fourth request to be rejected")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Errorf("expected to retry after 500ms, got %s", retryAfter)
+	}
+
+	// buckets are independent
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Errorf("expected the first request of b to be allowed")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Errorf("expected a request to be allowed after the refill")
+	}
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Errorf("expected the bucket to be empty again")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := ratelimit.NewWithClock(2, 1, func() time.Time { return now })
+
+	for request := 0; request < 2; request++ {
+		if ok, _ := limiter.Peek("a"); !ok {
+			t.Fatalf("expected peek %d to find a token", request)
+		}
+	}
+
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Fatalf("expected the peeks to leave the token in the bucket")
+	}
+
+	ok, retryAfter := limiter.Peek("a")
+	if ok {
+		t.Fatalf("expected the bucket to be empty")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Errorf("expected to retry after 500ms, got %s", retryAfter)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := limiter.Peek("a"); !ok {
+		t.Errorf("expected a token after the refill")
+	}
+}
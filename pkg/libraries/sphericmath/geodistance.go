@@ -61,3 +61,17 @@ func CalculateBearing(a, b Point) float64 {
 
 	return math.Atan2(y, x)
 }
+
+// CalculateDestination returns the point reached from start after distance meters in direction bearing (radians, clockwise from north)
+func CalculateDestination(start Point, bearing float64, distance float64) Point {
+	phi, lambda := start.toLatLngRad()
+	delta := distance / EarthRadius
+
+	phi2 := math.Asin(math.Sin(phi)*math.Cos(delta) + math.Cos(phi)*math.Sin(delta)*math.Cos(bearing))
+	lambda2 := lambda + math.Atan2(
+		math.Sin(bearing)*math.Sin(delta)*math.Cos(phi),
+		math.Cos(delta)-math.Sin(phi)*math.Sin(phi2),
+	)
+
+	return NewPoint(phi2*180/math.Pi, lambda2*180/math.Pi)
+}
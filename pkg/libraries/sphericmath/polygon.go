@@ -0,0 +1,131 @@
+package sphericmath
+
+// Polygon is a ring of points, the last point may repeat the first one
+type Polygon []Point
+
+// Contains uses the even-odd rule on the lat/lon coordinates, which is exact enough for small polygons
+func (p Polygon) Contains(point Point) bool {
+	inside := false
+
+	for index := range p {
+		a := p[index]
+		b := p[(index+1)%len(p)]
+
+		if (a.Lat() > point.Lat()) == (b.Lat() > point.Lat()) {
+			continue
+		}
+
+		lon := a.Lon() + (point.Lat()-a.Lat())/(b.Lat()-a.Lat())*(b.Lon()-a.Lon())
+		if point.Lon() < lon {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// BoundingBox returns the south-west and the north-east corner of the polygon
+func (p Polygon) BoundingBox() (Point, Point) {
+	if len(p) == 0 {
+		return Point{}, Point{}
+	}
+
+	min, max := p[0], p[0]
+	for _, point := range p[1:] {
+		min = NewPoint(minFloat(min.Lat(), point.Lat()), minFloat(min.Lon(), point.Lon()))
+		max = NewPoint(maxFloat(max.Lat(), point.Lat()), maxFloat(max.Lon(), point.Lon()))
+	}
+
+	return min, max
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// IntersectsSegment checks if the segment from a to b crosses the ring of the polygon
+func (p Polygon) IntersectsSegment(a, b Point) bool {
+	for index := range p {
+		if segmentsIntersect(a, b, p[index], p[(index+1)%len(p)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Area is a polygon with optional holes
+type Area struct {
+	Outer Polygon
+	Holes []Polygon
+}
+
+func (a Area) Contains(point Point) bool {
+	if !a.Outer.Contains(point) {
+		return false
+	}
+
+	for _, hole := range a.Holes {
+		if hole.Contains(point) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IntersectsSegment checks if any part of the segment from p to q lies within the area
+func (a Area) IntersectsSegment(p, q Point) bool {
+	if a.Contains(p) || a.Contains(q) || a.Outer.IntersectsSegment(p, q) {
+		return true
+	}
+
+	// a segment entering a hole has to cross the area around it
+	for _, hole := range a.Holes {
+		if hole.IntersectsSegment(p, q) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a Area) BoundingBox() (Point, Point) {
+	return a.Outer.BoundingBox()
+}
+
+func segmentsIntersect(p1, p2, q1, q2 Point) bool {
+	d1 := orientation(q1, q2, p1)
+	d2 := orientation(q1, q2, p2)
+	d3 := orientation(p1, p2, q1)
+	d4 := orientation(p1, p2, q2)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(q1, q2, p1)) ||
+		(d2 == 0 && onSegment(q1, q2, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, q1)) ||
+		(d4 == 0 && onSegment(p1, p2, q2))
+}
+
+// orientation is positive, if c lies to the left of the line from a to b
+func orientation(a, b, c Point) float64 {
+	return (b.Lon()-a.Lon())*(c.Lat()-a.Lat()) - (b.Lat()-a.Lat())*(c.Lon()-a.Lon())
+}
+
+// onSegment checks if c, which lies on the line through a and b, is between them
+func onSegment(a, b, c Point) bool {
+	return minFloat(a.Lat(), b.Lat()) <= c.Lat() && c.Lat() <= maxFloat(a.Lat(), b.Lat()) &&
+		minFloat(a.Lon(), b.Lon()) <= c.Lon() && c.Lon() <= maxFloat(a.Lon(), b.Lon())
+}
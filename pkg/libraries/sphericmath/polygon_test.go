@@ -0,0 +1,77 @@
+package sphericmath_test
+
+import (
+	"github.com/paulkoehlerdev/gosmRoutify/pkg/libraries/sphericmath"
+	"testing"
+)
+
+func TestPolygonContains(t *testing.T) {
+	// L-shaped polygon, the north-east quarter of the square is cut out
+	polygon := sphericmath.Polygon{
+		sphericmath.NewPoint(48, 11),
+		sphericmath.NewPoint(48, 13),
+		sphericmath.NewPoint(49, 13),
+		sphericmath.NewPoint(49, 12),
+		sphericmath.NewPoint(50, 12),
+		sphericmath.NewPoint(50, 11),
+		sphericmath.NewPoint(48, 11),
+	}
+
+	tests := []struct {
+		point    sphericmath.Point
+		expected bool
+	}{
+		{sphericmath.NewPoint(48.5, 11.5), true},
+		{sphericmath.NewPoint(49.5, 11.5), true},
+		{sphericmath.NewPoint(48.5, 12.5), true},
+		{sphericmath.NewPoint(49.5, 12.5), false},
+		{sphericmath.NewPoint(47.5, 11.5), false},
+		{sphericmath.NewPoint(48.5, 13.5), false},
+	}
+
+	for _, test := range tests {
+		if actual := polygon.Contains(test.point); actual != test.expected {
+			t.Errorf("Contains(%v) = %t, expected %t", test.point, actual, test.expected)
+		}
+	}
+
+	min, max := polygon.BoundingBox()
+	if min != sphericmath.NewPoint(48, 11) || max != sphericmath.NewPoint(50, 13) {
+		t.Errorf("BoundingBox() = %v, %v", min, max)
+	}
+}
+
+func TestAreaIntersectsSegment(t *testing.T) {
+	square := func(min, max float64) sphericmath.Polygon {
+		return sphericmath.Polygon{
+			sphericmath.NewPoint(min, min),
+			sphericmath.NewPoint(min, max),
+			sphericmath.NewPoint(max, max),
+			sphericmath.NewPoint(max, min),
+		}
+	}
+
+	area := sphericmath.Area{
+		Outer: square(0, 10),
+		Holes: []sphericmath.Polygon{square(4, 6)},
+	}
+
+	tests := []struct {
+		name     string
+		from, to sphericmath.Point
+		expected bool
+	}{
+		{"inside", sphericmath.NewPoint(1, 1), sphericmath.NewPoint(2, 2), true},
+		{"crossing", sphericmath.NewPoint(-1, 5), sphericmath.NewPoint(11, 5), true},
+		{"entering", sphericmath.NewPoint(-1, 1), sphericmath.NewPoint(1, 1), true},
+		{"outside", sphericmath.NewPoint(-1, -1), sphericmath.NewPoint(-1, 11), false},
+		{"within hole", sphericmath.NewPoint(4.5, 4.5), sphericmath.NewPoint(5.5, 5.5), false},
+		{"leaving hole", sphericmath.NewPoint(5, 5), sphericmath.NewPoint(7, 5), true},
+	}
+
+	for _, test := range tests {
+		if actual := area.IntersectsSegment(test.from, test.to); actual != test.expected {
+			t.Errorf("%s: IntersectsSegment(%v, %v) = %t, expected %t", test.name, test.from, test.to, actual, test.expected)
+		}
+	}
+}